@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/search"
+	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/utils"
+)
+
+// Imports or exports the product catalogue as CSV, in the same layout as the
+// /api/products/import and /api/products/export routes.
+//
+//	go run ./cli/products -import products.csv -user 1
+//	go run ./cli/products -export products.csv
+func main() {
+	importPath := flag.String("import", "", "CSV file of products to create or update")
+	exportPath := flag.String("export", "", "CSV file to write every product to")
+	userId := flag.Uint64("user", 0, "id of the user the import is recorded against")
+	flag.Parse()
+
+	if (*importPath == "") == (*exportPath == "") {
+		fmt.Fprintln(os.Stderr, "exactly one of -import or -export is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *importPath != "" && *userId == 0 {
+		fmt.Fprintln(os.Stderr, "-user is required for an import")
+		os.Exit(2)
+	}
+
+	// todo: remove hardcode databse config and use aws secret
+	genericUserConfig := mysql.DatabaseConfig{
+		Host:              "localhost",
+		Port:              3306,
+		RequestTimeout:    30,
+		ConnectionTimeout: 10,
+		Dialect:           "mysql",
+		Database:          "go_admin",
+		Username:          "root",
+		Password:          "root",
+	}
+
+	logger := logger.NewSimpleLogger(utils.Getenv("LOG_LEVEL", "INFO"), false)
+	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig, logger)
+	if err != nil {
+		logger.Errorf("Unabled to connect to database: %s", err.Error())
+		os.Exit(1)
+	}
+
+	productRepo := repository.NewMySqlProductRepository(logger, *dbConn)
+	categoryRepo := repository.NewMySqlCategoryRepository(logger, *dbConn)
+	importRepo := repository.NewMySqlProductImportRepository(logger, *dbConn)
+	validatorService := service.NewValidator(logger, *validator.New())
+	productsService := service.NewProductsService(validatorService, productRepo, categoryRepo, search.NewMySqlIndex(logger, *dbConn), logger)
+	importsService := service.NewImportsService(validatorService, importRepo, productRepo, productsService, logger)
+
+	if *exportPath != "" {
+		err = exportProducts(importsService, *exportPath)
+	} else {
+		err = importProducts(importsService, *importPath, *userId)
+	}
+	importsService.Shutdown()
+	if err != nil {
+		logger.Errorf("%s", err.Error())
+		os.Exit(1)
+	}
+}
+
+func exportProducts(importsService service.Imports, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return importsService.ExportProducts(context.Background(), file)
+}
+
+func importProducts(importsService service.Imports, path string, userId uint64) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	report, err := importsService.ImportProducts(context.Background(), data, userId)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
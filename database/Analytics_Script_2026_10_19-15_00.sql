@@ -0,0 +1,34 @@
+-- Create Sales Summaries Table
+-- Hourly UTC buckets of paid orders, refreshed by the sales summary job so charts
+-- can be grouped into days, weeks or months in any time zone without scanning orders.
+CREATE TABLE sales_summaries (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  bucket_start datetime NOT NULL,
+  order_count bigint unsigned NOT NULL DEFAULT 0,
+  revenue DECIMAL(14,2) NOT NULL DEFAULT 0,
+  refreshed_at datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_sales_summaries_bucket_start (bucket_start)
+);
+
+-- Create Product Sales Summaries Table
+CREATE TABLE product_sales_summaries (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  bucket_start datetime NOT NULL,
+  product_title longtext,
+  product_title_hash char(64) NOT NULL,
+  quantity bigint unsigned NOT NULL DEFAULT 0,
+  revenue DECIMAL(14,2) NOT NULL DEFAULT 0,
+  refreshed_at datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  UNIQUE KEY uq_product_sales_summaries_bucket (bucket_start, product_title_hash)
+);
+
+-- Insert analytics permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('view_analytics', 'Allow user to view sales analytics charts', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'view_analytics' AND deleted_at IS NULL;
@@ -0,0 +1,27 @@
+-- Create Audit Logs Table
+-- One row per audited write, stored in the same transaction as the write. changes
+-- maps each changed column to its before and after value, with secrets redacted.
+CREATE TABLE audit_logs (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  actor_user bigint unsigned NOT NULL,
+  entity varchar(50) NOT NULL,
+  entity_id bigint unsigned NOT NULL,
+  action varchar(20) NOT NULL,
+  changes json NOT NULL,
+  trace_id varchar(64),
+  source_ip varchar(45),
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  INDEX idx_audit_logs_entity (entity, entity_id),
+  INDEX idx_audit_logs_actor (actor_user),
+  INDEX idx_audit_logs_created_at (created_at)
+);
+
+-- Insert audit permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('view_audit_log', 'Allow user to query the audit log of changes', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'view_audit_log' AND deleted_at IS NULL;
@@ -0,0 +1,51 @@
+-- Create Categories Table
+-- Categories nest through parent_id; a NULL parent is a top level category.
+CREATE TABLE categories (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  parent_id bigint unsigned DEFAULT NULL,
+  name varchar(50) NOT NULL,
+  slug varchar(100) NOT NULL,
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  INDEX idx_categories_slug (slug),
+  CONSTRAINT fk_categories_parent
+    FOREIGN KEY (parent_id)
+    REFERENCES categories (id)
+);
+
+-- Create Many-to-Many Table for Products and Categories
+CREATE TABLE product_categories (
+  product_id bigint unsigned NOT NULL,
+  category_id bigint unsigned NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (product_id, category_id),
+  INDEX idx_product_categories_category (category_id),
+  CONSTRAINT fk_product_categories_product
+    FOREIGN KEY (product_id)
+    REFERENCES products (id),
+  CONSTRAINT fk_product_categories_category
+    FOREIGN KEY (category_id)
+    REFERENCES categories (id)
+);
+
+-- Insert category permissions for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('create_category', 'Allow user to create product categories', 1, NULL),
+('edit_category', 'Allow user to edit product categories', 1, NULL),
+('delete_category', 'Allow user to delete product categories', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name IN ('create_category', 'edit_category', 'delete_category') AND deleted_at IS NULL;
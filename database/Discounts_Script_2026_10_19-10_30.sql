@@ -0,0 +1,101 @@
+-- Add reproducible totals to Orders Table
+ALTER TABLE orders
+  ADD COLUMN subtotal double NOT NULL DEFAULT 0 AFTER delivery_details_id,
+  ADD COLUMN discount_total double NOT NULL DEFAULT 0 AFTER subtotal,
+  ADD COLUMN total double NOT NULL DEFAULT 0 AFTER discount_total;
+
+-- Insert discount permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('manage_discount', 'Allow user to view, create, edit and delete discount codes and promotions', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'manage_discount' AND deleted_at IS NULL;
+
+-- Create Discount Types Table
+CREATE TABLE discount_types (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Discount Types Table
+INSERT INTO discount_types (name, description, created_user, updated_at) VALUES
+('Percentage', 'Takes a percentage off the eligible subtotal', 1, NULL),
+('Fixed Amount', 'Takes a fixed amount off the eligible subtotal', 1, NULL);
+
+-- Create Discounts Table (coupons have a code, promotions apply automatically)
+CREATE TABLE discounts (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  code varchar(50) DEFAULT NULL,
+  description varchar(225),
+  discount_type_id bigint unsigned NOT NULL,
+  value double NOT NULL,
+  minimum_spend double NOT NULL DEFAULT 0,
+  global_usage_limit bigint unsigned DEFAULT NULL,
+  per_user_usage_limit bigint unsigned DEFAULT NULL,
+  starts_at datetime DEFAULT NULL,
+  ends_at datetime DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY code (code),
+  CONSTRAINT fk_discounts_discount_type
+    FOREIGN KEY (discount_type_id)
+    REFERENCES discount_types (id)
+);
+
+-- Create Many-to-Many Table for Discounts and Products
+CREATE TABLE discount_products (
+  discount_id bigint unsigned NOT NULL,
+  product_id bigint unsigned NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (discount_id, product_id),
+  CONSTRAINT fk_discount_products_discount
+    FOREIGN KEY (discount_id)
+    REFERENCES discounts (id),
+  CONSTRAINT fk_discount_products_product
+    FOREIGN KEY (product_id)
+    REFERENCES products (id)
+);
+
+-- Create Order Discounts Table (snapshot of each discount as applied to an order)
+CREATE TABLE order_discounts (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  order_id bigint unsigned NOT NULL,
+  discount_id bigint unsigned NOT NULL,
+  code varchar(50) DEFAULT NULL,
+  discount_type_id bigint unsigned NOT NULL,
+  value double NOT NULL,
+  discount_amount double NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_orders_order_discounts
+    FOREIGN KEY (order_id)
+    REFERENCES orders (id),
+  CONSTRAINT fk_discounts_order_discounts
+    FOREIGN KEY (discount_id)
+    REFERENCES discounts (id)
+);
@@ -0,0 +1,13 @@
+-- Insert order export permission for Permissions Table
+-- view_order is held by every customer, so exporting all orders and reading another
+-- customer's invoice need a permission of their own.
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('export_order', 'Allow user to export all orders and download any order''s invoice', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'export_order' AND deleted_at IS NULL;
+
+-- Record this script
+INSERT INTO schema_migrations (version) VALUES ('2026_10_19-23_45');
@@ -0,0 +1,29 @@
+-- Add SKU to Products Table so spreadsheet rows can be matched to products
+ALTER TABLE products
+  ADD COLUMN sku varchar(64) DEFAULT NULL AFTER id,
+  ADD INDEX idx_products_sku (sku);
+
+-- Widen Pictures so full image URLs fit
+ALTER TABLE pictures
+  MODIFY COLUMN picture_url varchar(225);
+
+-- Create Product Imports Table
+-- Tracks the progress of a bulk CSV import; errors holds the failed rows as JSON.
+CREATE TABLE product_imports (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  status varchar(20) NOT NULL,
+  total_rows bigint unsigned NOT NULL DEFAULT 0,
+  processed_rows bigint unsigned NOT NULL DEFAULT 0,
+  created_count bigint unsigned NOT NULL DEFAULT 0,
+  updated_count bigint unsigned NOT NULL DEFAULT 0,
+  failed_count bigint unsigned NOT NULL DEFAULT 0,
+  errors json DEFAULT NULL,
+  finished_at datetime DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
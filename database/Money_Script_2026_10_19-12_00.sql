@@ -0,0 +1,39 @@
+-- Money is stored as exact DECIMAL amounts instead of binary floating point.
+-- Existing values are rounded to whole cents before the columns are converted.
+
+-- Update Products Table
+UPDATE products SET price = ROUND(COALESCE(price, 0), 2);
+ALTER TABLE products
+  MODIFY COLUMN price DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Order Items Table
+UPDATE order_items SET price = ROUND(COALESCE(price, 0), 2);
+ALTER TABLE order_items
+  MODIFY COLUMN price DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Orders Table
+UPDATE orders SET subtotal = ROUND(subtotal, 2), discount_total = ROUND(discount_total, 2), total = ROUND(total, 2);
+ALTER TABLE orders
+  MODIFY COLUMN subtotal DECIMAL(12,2) NOT NULL DEFAULT 0,
+  MODIFY COLUMN discount_total DECIMAL(12,2) NOT NULL DEFAULT 0,
+  MODIFY COLUMN total DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Returns Table
+UPDATE returns SET refund_amount = ROUND(COALESCE(refund_amount, 0), 2);
+ALTER TABLE returns
+  MODIFY COLUMN refund_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Return Items Table
+UPDATE return_items SET refund_amount = ROUND(COALESCE(refund_amount, 0), 2);
+ALTER TABLE return_items
+  MODIFY COLUMN refund_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Discounts Table (percentages keep four decimal places)
+ALTER TABLE discounts
+  MODIFY COLUMN value DECIMAL(12,4) NOT NULL,
+  MODIFY COLUMN minimum_spend DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+-- Update Order Discounts Table
+ALTER TABLE order_discounts
+  MODIFY COLUMN value DECIMAL(12,4) NOT NULL,
+  MODIFY COLUMN discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
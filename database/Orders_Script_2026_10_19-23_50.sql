@@ -0,0 +1,13 @@
+-- Insert permission to read any order for Permissions Table
+-- view_order is held by every customer to read their own orders, so reading another
+-- customer's order needs a permission of its own.
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('view_any_order', 'Allow user to view orders placed by other users', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'view_any_order' AND deleted_at IS NULL;
+
+-- Record this script
+INSERT INTO schema_migrations (version) VALUES ('2026_10_19-23_50');
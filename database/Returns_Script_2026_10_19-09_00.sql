@@ -0,0 +1,90 @@
+-- Add stock tracking to Products Table
+ALTER TABLE products
+  ADD COLUMN stock bigint unsigned NOT NULL DEFAULT 0 AFTER price;
+
+-- Link Order Items back to the product they were snapshotted from
+ALTER TABLE order_items
+  ADD COLUMN product_id bigint unsigned DEFAULT NULL AFTER order_id,
+  ADD CONSTRAINT fk_products_order_items
+    FOREIGN KEY (product_id)
+    REFERENCES products (id);
+
+-- Insert cancelled status for Order Status Types Table
+INSERT INTO order_status_types (name, description, created_user, updated_at) VALUES
+('Cancelled', 'Order has been cancelled and any payment refunded', 1, NULL);
+
+-- Insert refund permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('refund_order', 'Allow user to approve or reject returns and refund orders', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+-- The permission id is looked up by name since it depends on the rows inserted before.
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'refund_order' AND deleted_at IS NULL;
+
+-- Create Return Status Types Table
+CREATE TABLE return_status_types (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Return Status Types Table
+INSERT INTO return_status_types (name, description, created_user, updated_at) VALUES
+('Requested', 'Return has been requested and is awaiting review', 1, NULL),
+('Approved', 'Return has been approved and stock restored', 1, NULL),
+('Rejected', 'Return has been rejected', 1, NULL),
+('Refunded', 'Return has been refunded through the payment provider', 1, NULL);
+
+-- Create Returns Table (RMA)
+CREATE TABLE returns (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  order_id bigint unsigned NOT NULL,
+  status_id bigint unsigned NOT NULL,
+  reason varchar(225),
+  refund_amount double DEFAULT NULL,
+  payment_reference varchar(225) DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_orders_returns
+    FOREIGN KEY (order_id)
+    REFERENCES orders (id),
+  CONSTRAINT fk_returns_status_type
+    FOREIGN KEY (status_id)
+    REFERENCES return_status_types (id)
+);
+
+-- Create Return Items Table
+CREATE TABLE return_items (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  return_id bigint unsigned NOT NULL,
+  order_item_id bigint unsigned NOT NULL,
+  quantity bigint unsigned NOT NULL,
+  refund_amount double DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_returns_return_items
+    FOREIGN KEY (return_id)
+    REFERENCES returns (id),
+  CONSTRAINT fk_order_items_return_items
+    FOREIGN KEY (order_item_id)
+    REFERENCES order_items (id)
+);
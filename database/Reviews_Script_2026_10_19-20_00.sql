@@ -0,0 +1,57 @@
+-- Create Review Status Types Table
+CREATE TABLE review_status_types (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Review Status Types Table
+INSERT INTO review_status_types (name, description, created_user, updated_at) VALUES
+('Pending', 'Review is waiting for moderation', 1, NULL),
+('Approved', 'Review has been approved and is shown on the product', 1, NULL),
+('Rejected', 'Review has been rejected by a moderator', 1, NULL);
+
+-- Create Reviews Table
+-- created_user is the reviewer; a user keeps one live review per product.
+CREATE TABLE reviews (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  product_id bigint unsigned NOT NULL,
+  status_id bigint unsigned NOT NULL,
+  rating tinyint unsigned NOT NULL,
+  title varchar(50),
+  body varchar(1000),
+  moderation_note varchar(225),
+  moderated_user bigint unsigned DEFAULT NULL,
+  moderated_at datetime DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  INDEX idx_reviews_product_status (product_id, status_id),
+  INDEX idx_reviews_user_product (created_user, product_id),
+  CONSTRAINT fk_reviews_product
+    FOREIGN KEY (product_id)
+    REFERENCES products (id),
+  CONSTRAINT fk_reviews_status_type
+    FOREIGN KEY (status_id)
+    REFERENCES review_status_types (id)
+);
+
+-- Insert review moderation permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('moderate_review', 'Allow user to approve, reject and delete product reviews', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'moderate_review' AND deleted_at IS NULL;
@@ -0,0 +1,11 @@
+-- Enforce one live review per user and product
+-- live is 1 while the review is not deleted and NULL once it is, so deleted reviews
+-- drop out of the unique index and the user can review the product again. Two live
+-- reviews by the same user for a product must be resolved before this runs.
+ALTER TABLE reviews
+  ADD COLUMN live tinyint GENERATED ALWAYS AS (IF(deleted_at IS NULL, 1, NULL)) STORED,
+  DROP INDEX idx_reviews_user_product,
+  ADD UNIQUE INDEX idx_reviews_user_product (created_user, product_id, live);
+
+-- Record this script
+INSERT INTO schema_migrations (version) VALUES ('2026_10_19-23_30');
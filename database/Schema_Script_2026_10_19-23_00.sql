@@ -0,0 +1,27 @@
+-- Create Schema Migrations Table
+-- One row per applied script, keyed by the timestamp in its file name. Readiness
+-- compares the latest version with the one the deployed code expects, so every
+-- script from this one on must insert its own version as its last statement.
+CREATE TABLE schema_migrations (
+  version varchar(32) NOT NULL,
+  applied_at datetime DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (version)
+);
+
+-- Record the scripts applied before this table existed
+INSERT INTO schema_migrations (version) VALUES
+('2023_12_26-23_15'),
+('2026_10_19-09_00'),
+('2026_10_19-10_30'),
+('2026_10_19-12_00'),
+('2026_10_19-13_00'),
+('2026_10_19-14_00'),
+('2026_10_19-15_00'),
+('2026_10_19-16_00'),
+('2026_10_19-17_00'),
+('2026_10_19-18_00'),
+('2026_10_19-19_00'),
+('2026_10_19-20_00'),
+('2026_10_19-21_00'),
+('2026_10_19-22_00'),
+('2026_10_19-23_00');
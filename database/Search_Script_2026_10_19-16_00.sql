@@ -0,0 +1,9 @@
+-- Add full-text indexes for Product search
+-- The combined index drives matching, the title index lets title hits rank higher.
+ALTER TABLE products
+  ADD FULLTEXT INDEX ft_products_title_description (title, description),
+  ADD FULLTEXT INDEX ft_products_title (title);
+
+-- Index Product prices for price range filters and sorting
+ALTER TABLE products
+  ADD INDEX idx_products_price (price);
@@ -0,0 +1,120 @@
+-- Add shipping weight (kg) to Products Table
+ALTER TABLE products
+  ADD COLUMN weight DECIMAL(12,4) NOT NULL DEFAULT 0 AFTER tax_class_id;
+
+-- Create Shipping Zones Table
+-- A zone matches on country and optionally narrows to a city and area name.
+CREATE TABLE shipping_zones (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  country varchar(50) NOT NULL,
+  city varchar(50) DEFAULT NULL,
+  area_name varchar(50) DEFAULT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Shipping Zones Table
+INSERT INTO shipping_zones (name, country, city, area_name, created_user, updated_at) VALUES
+('South Africa', 'South Africa', NULL, NULL, 1, NULL),
+('Cape Town', 'South Africa', 'Cape Town', NULL, 1, NULL);
+
+-- Create Shipping Rate Bases Table
+CREATE TABLE shipping_rate_bases (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Shipping Rate Bases Table
+INSERT INTO shipping_rate_bases (name, description, created_user, updated_at) VALUES
+('Weight', 'Rate band on the total order weight in kg', 1, NULL),
+('Price', 'Rate band on the order value after discounts', 1, NULL);
+
+-- Create Shipping Rates Table
+-- A band covers min_value up to but excluding max_value, a NULL max_value is open ended.
+CREATE TABLE shipping_rates (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  zone_id bigint unsigned NOT NULL,
+  rate_basis_id bigint unsigned NOT NULL,
+  min_value DECIMAL(12,4) NOT NULL DEFAULT 0,
+  max_value DECIMAL(12,4) DEFAULT NULL,
+  price DECIMAL(12,2) NOT NULL DEFAULT 0,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_shipping_zones_shipping_rates
+    FOREIGN KEY (zone_id)
+    REFERENCES shipping_zones (id),
+  CONSTRAINT fk_shipping_rate_bases_shipping_rates
+    FOREIGN KEY (rate_basis_id)
+    REFERENCES shipping_rate_bases (id)
+);
+
+-- Insert example data for Shipping Rates Table
+INSERT INTO shipping_rates (zone_id, rate_basis_id, min_value, max_value, price, created_user, updated_at) VALUES
+(1, 1, 0, 5, 99.00, 1, NULL),
+(1, 1, 5, NULL, 199.00, 1, NULL),
+(2, 2, 0, 500, 60.00, 1, NULL),
+(2, 2, 500, NULL, 0, 1, NULL);
+
+-- Create Delivery Slots Table
+CREATE TABLE delivery_slots (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  zone_id bigint unsigned NOT NULL,
+  starts_at datetime NOT NULL,
+  ends_at datetime NOT NULL,
+  capacity bigint unsigned NOT NULL,
+  reserved bigint unsigned NOT NULL DEFAULT 0,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY idx_delivery_slots_zone_starts_at (zone_id, starts_at),
+  CONSTRAINT fk_shipping_zones_delivery_slots
+    FOREIGN KEY (zone_id)
+    REFERENCES shipping_zones (id)
+);
+
+-- Link Delivery Details to the reserved slot
+ALTER TABLE delivery_details
+  ADD COLUMN delivery_slot_id bigint unsigned DEFAULT NULL AFTER country,
+  ADD CONSTRAINT fk_delivery_slots_delivery_details
+    FOREIGN KEY (delivery_slot_id)
+    REFERENCES delivery_slots (id);
+
+-- Add shipping to Orders Table
+ALTER TABLE orders
+  ADD COLUMN shipping_rate_id bigint unsigned DEFAULT NULL AFTER tax_total,
+  ADD COLUMN shipping_total DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER shipping_rate_id,
+  ADD CONSTRAINT fk_shipping_rates_orders
+    FOREIGN KEY (shipping_rate_id)
+    REFERENCES shipping_rates (id);
+
+-- Insert shipping permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('manage_shipping', 'Allow user to manage shipping zones, rates and delivery slots', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'manage_shipping' AND deleted_at IS NULL;
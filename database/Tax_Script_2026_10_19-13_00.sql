@@ -0,0 +1,75 @@
+-- Create Tax Classes Table
+CREATE TABLE tax_classes (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50),
+  description varchar(225),
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Insert example data for Tax Classes Table
+INSERT INTO tax_classes (name, description, created_user, updated_at) VALUES
+('Standard', 'Goods taxed at the standard rate', 1, NULL),
+('Reduced', 'Goods taxed at a reduced rate', 1, NULL),
+('Zero Rated', 'Goods that carry no tax', 1, NULL);
+
+-- Add tax class to Products Table
+ALTER TABLE products
+  ADD COLUMN tax_class_id bigint unsigned NOT NULL DEFAULT 1 AFTER stock,
+  ADD CONSTRAINT fk_tax_classes_products
+    FOREIGN KEY (tax_class_id)
+    REFERENCES tax_classes (id);
+
+-- Create Tax Rates Table
+-- A NULL region is the country wide rate, a region matches the delivery city or area name.
+CREATE TABLE tax_rates (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  country varchar(50) NOT NULL,
+  region varchar(50) DEFAULT NULL,
+  tax_class_id bigint unsigned NOT NULL,
+  name varchar(50),
+  rate DECIMAL(12,4) NOT NULL,
+  prices_include_tax tinyint(1) NOT NULL DEFAULT 0,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_tax_classes_tax_rates
+    FOREIGN KEY (tax_class_id)
+    REFERENCES tax_classes (id)
+);
+
+-- Insert example data for Tax Rates Table
+INSERT INTO tax_rates (country, region, tax_class_id, name, rate, prices_include_tax, created_user, updated_at) VALUES
+('South Africa', NULL, 1, 'VAT', 15.0000, 1, 1, NULL),
+('South Africa', NULL, 2, 'VAT', 0.0000, 1, 1, NULL),
+('South Africa', NULL, 3, 'VAT', 0.0000, 1, 1, NULL);
+
+-- Store the discount share and tax applied to each Order Item
+ALTER TABLE order_items
+  ADD COLUMN discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER quantity,
+  ADD COLUMN tax_class_id bigint unsigned DEFAULT NULL AFTER discount_amount,
+  ADD COLUMN tax_rate DECIMAL(12,4) NOT NULL DEFAULT 0 AFTER tax_class_id,
+  ADD COLUMN tax_amount DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER tax_rate,
+  ADD COLUMN prices_include_tax tinyint(1) NOT NULL DEFAULT 0 AFTER tax_amount;
+
+-- Add tax total to Orders Table
+ALTER TABLE orders
+  ADD COLUMN tax_total DECIMAL(12,2) NOT NULL DEFAULT 0 AFTER discount_total;
+
+-- Insert tax permission for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('manage_tax', 'Allow user to view, create, edit and delete tax rates', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name = 'manage_tax' AND deleted_at IS NULL;
@@ -0,0 +1,97 @@
+-- Create Attributes Table (e.g. Size, Colour)
+CREATE TABLE attributes (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  name varchar(50) NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id)
+);
+
+-- Create Attribute Values Table (e.g. Small, Medium for Size)
+CREATE TABLE attribute_values (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  attribute_id bigint unsigned NOT NULL,
+  value varchar(50) NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  CONSTRAINT fk_attribute_values_attribute
+    FOREIGN KEY (attribute_id)
+    REFERENCES attributes (id)
+);
+
+-- Insert example data for Attributes and Attribute Values Tables
+INSERT INTO attributes (name, created_user, updated_at) VALUES
+('Size', 1, NULL),
+('Colour', 1, NULL);
+
+INSERT INTO attribute_values (attribute_id, value, created_user, updated_at) VALUES
+(1, 'Small', 1, NULL),
+(1, 'Medium', 1, NULL),
+(1, 'Large', 1, NULL),
+(2, 'Black', 1, NULL),
+(2, 'White', 1, NULL);
+
+-- Create Product Variants Table
+-- A NULL price means the variant sells at the product price.
+CREATE TABLE product_variants (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  product_id bigint unsigned NOT NULL,
+  sku varchar(64) NOT NULL,
+  price DECIMAL(12,2) DEFAULT NULL,
+  stock bigint unsigned NOT NULL DEFAULT 0,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  INDEX idx_product_variants_sku (sku),
+  CONSTRAINT fk_product_variants_product
+    FOREIGN KEY (product_id)
+    REFERENCES products (id)
+);
+
+-- Create Many-to-Many Table for Variants and their Attribute Values
+CREATE TABLE variant_attribute_values (
+  variant_id bigint unsigned NOT NULL,
+  attribute_value_id bigint unsigned NOT NULL,
+  created_user bigint unsigned DEFAULT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (variant_id, attribute_value_id),
+  CONSTRAINT fk_variant_attribute_values_variant
+    FOREIGN KEY (variant_id)
+    REFERENCES product_variants (id),
+  CONSTRAINT fk_variant_attribute_values_value
+    FOREIGN KEY (attribute_value_id)
+    REFERENCES attribute_values (id)
+);
+
+-- Let Pictures belong to a single variant of their product
+ALTER TABLE pictures
+  ADD COLUMN variant_id bigint unsigned DEFAULT NULL AFTER product_id,
+  ADD CONSTRAINT fk_product_variants_pictures
+    FOREIGN KEY (variant_id)
+    REFERENCES product_variants (id);
+
+-- Snapshot the chosen variant onto Order Items
+ALTER TABLE order_items
+  ADD COLUMN variant_id bigint unsigned DEFAULT NULL AFTER product_id,
+  ADD COLUMN sku varchar(64) DEFAULT NULL AFTER variant_id,
+  ADD COLUMN variant_attributes json DEFAULT NULL AFTER product_title,
+  ADD CONSTRAINT fk_product_variants_order_items
+    FOREIGN KEY (variant_id)
+    REFERENCES product_variants (id);
@@ -0,0 +1,39 @@
+-- Create Wishlist Items Table
+-- created_user owns the item. saved_price and saved_stock snapshot the product (or
+-- variant) when it was added, so price drops and restocks can be flagged later.
+-- variant_key folds a NULL variant into 0 so the unique key covers plain products.
+CREATE TABLE wishlist_items (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  product_id bigint unsigned NOT NULL,
+  variant_id bigint unsigned DEFAULT NULL,
+  variant_key bigint unsigned AS (COALESCE(variant_id, 0)) STORED,
+  saved_price DECIMAL(12,2) NOT NULL DEFAULT 0,
+  saved_stock bigint unsigned NOT NULL DEFAULT 0,
+  created_user bigint unsigned NOT NULL,
+  created_at datetime DEFAULT CURRENT_TIMESTAMP,
+  updated_user bigint unsigned DEFAULT NULL,
+  updated_at datetime DEFAULT CURRENT_TIMESTAMP,
+  deleted_user bigint unsigned DEFAULT NULL,
+  deleted_at datetime DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE INDEX idx_wishlist_items_user_product (created_user, product_id, variant_key),
+  CONSTRAINT fk_wishlist_items_product
+    FOREIGN KEY (product_id)
+    REFERENCES products (id),
+  CONSTRAINT fk_wishlist_items_variant
+    FOREIGN KEY (variant_id)
+    REFERENCES product_variants (id)
+);
+
+-- Insert wishlist permissions for Permissions Table
+INSERT INTO permission_types (name, description, created_user, updated_at) VALUES
+('view_wishlist', 'Allow user to view their wishlist', 1, NULL),
+('edit_wishlist', 'Allow user to add, remove and move items on their wishlist', 1, NULL);
+
+-- Insert example data for Role-Permissions Table
+INSERT INTO role_permissions (role_id, permission_id, created_user, updated_at)
+-- Admin Role
+SELECT 1, id, 1, NULL FROM permission_types WHERE name IN ('view_wishlist', 'edit_wishlist') AND deleted_at IS NULL
+UNION ALL
+-- Customer Role
+SELECT 2, id, 1, NULL FROM permission_types WHERE name IN ('view_wishlist', 'edit_wishlist') AND deleted_at IS NULL;
@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/model"
+)
+
+// Chart implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) Chart() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		chartResponse, err := controller.analyticsService.Chart(context.UserContext(), model.ChartRequest{
+			From:     context.Query("from"),
+			To:       context.Query("to"),
+			Interval: context.Query("interval"),
+			Timezone: context.Query("timezone"),
+			Limit:    context.QueryInt("limit"),
+		})
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(chartResponse)
+	}
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/types"
+)
+
+func (controller *InternalPluginControllerImpl) getIdQuery(context *fiber.Ctx, key string) (*uint64, error) {
+	value := context.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		controller.requestLogger(context).Infof("Invalid '%s' query parameter: '%s'", key, value)
+		return nil, types.NewInvalidInputError()
+	}
+	return &id, nil
+}
+
+// AuditLog implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AuditLog() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		entityId, err := controller.getIdQuery(context, "entity_id")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		actorUser, err := controller.getIdQuery(context, "actor_user")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		auditResponse, err := controller.auditService.QueryAuditLog(context.UserContext(), model.AuditLogRequest{
+			Entity:    context.Query("entity"),
+			EntityID:  entityId,
+			ActorUser: actorUser,
+			Action:    context.Query("action"),
+			Page:      context.QueryInt("page"),
+			PerPage:   context.QueryInt("per_page"),
+		})
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(auditResponse)
+	}
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/model"
+)
+
+// AllCategories implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllCategories() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		categoriesResponse, err := controller.categoriesService.AllCategories(context.UserContext())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(categoriesResponse)
+	}
+}
+
+// GetCategory implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetCategory() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		categoryResponse, err := controller.categoriesService.GetCategory(context.UserContext(), context.Params("slug"))
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(categoryResponse)
+	}
+}
+
+// GetCategoryProducts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetCategoryProducts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		minPrice, err := controller.getMoneyQuery(context, "min_price")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		maxPrice, err := controller.getMoneyQuery(context, "max_price")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productsResponse, err := controller.productsService.CategoryProducts(context.UserContext(), context.Params("slug"), model.ProductSearchRequest{
+			Query:    context.Query("q"),
+			MinPrice: minPrice,
+			MaxPrice: maxPrice,
+			Sort:     context.Query("sort"),
+			Page:     context.QueryInt("page"),
+			PerPage:  context.QueryInt("per_page"),
+		})
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(productsResponse)
+	}
+}
+
+// CreateCategory implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateCategory() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		categoryResponse, err := controller.categoriesService.CreateCategory(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(categoryResponse)
+	}
+}
+
+// UpdateCategory implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateCategory() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		categoryId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		categoryResponse, err := controller.categoriesService.UpdateCategory(context.UserContext(), categoryId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(categoryResponse)
+	}
+}
+
+// DeleteCategory implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteCategory() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		categoryId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.categoriesService.DeleteCategory(context.UserContext(), categoryId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// AllDiscounts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllDiscounts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		discountsResponse, err := controller.discountsService.AllDiscounts(context.UserContext())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(discountsResponse)
+	}
+}
+
+// GetDiscount implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetDiscount() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		discountId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		discountResponse, err := controller.discountsService.GetDiscount(context.UserContext(), discountId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(discountResponse)
+	}
+}
+
+// CreateDiscount implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateDiscount() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		discountResponse, err := controller.discountsService.CreateDiscount(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(discountResponse)
+	}
+}
+
+// UpdateDiscount implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateDiscount() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		discountId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		discountResponse, err := controller.discountsService.UpdateDiscount(context.UserContext(), discountId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(discountResponse)
+	}
+}
+
+// DeleteDiscount implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteDiscount() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		discountId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.discountsService.DeleteDiscount(context.UserContext(), discountId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// QuoteDiscounts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) QuoteDiscounts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		quoteResponse, err := controller.discountsService.Quote(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(quoteResponse)
+	}
+}
+
+// ApplyOrderDiscount implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ApplyOrderDiscount() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderResponse, err := controller.discountsService.ApplyToOrder(context.UserContext(), orderId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(orderResponse)
+	}
+}
@@ -0,0 +1,56 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/export"
+)
+
+// Export implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) Export() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		exportRequest, err := controller.exportsService.ValidateOrderExport(context.UserContext(), string(context.Body()))
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+
+		filename := fmt.Sprintf("orders_%s_%s.%s", exportRequest.From.Format("20060102"), exportRequest.To.Format("20060102"), exportRequest.Format)
+		context.Set(fiber.HeaderContentType, export.ContentType(exportRequest.Format))
+		context.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		startStream, requestLogger := controller.streamContext(context, "Stream order export")
+		context.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			ctx, finish := startStream()
+			err := controller.exportsService.StreamOrderExport(ctx, *exportRequest, w)
+			finish(err)
+			if err != nil {
+				requestLogger.Errorf("Unabled to finish export '%s': %s", filename, err.Error())
+			}
+			w.Flush()
+		})
+		return nil
+	}
+}
+
+// CreateFile implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateFile() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		invoice, err := controller.exportsService.Invoice(context.UserContext(), orderId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+
+		context.Set(fiber.HeaderContentType, "application/pdf")
+		context.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"invoice_%06d.pdf\"", orderId))
+		return context.Send(invoice)
+	}
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/constant"
+)
+
+// Healthz implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) Healthz() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		return context.JSON(controller.healthService.Liveness())
+	}
+}
+
+// Readyz implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) Readyz() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		readiness := controller.healthService.Readiness(context.UserContext())
+		if readiness.Status == constant.HEALTH_STATUS_FAIL {
+			return context.Status(fiber.StatusServiceUnavailable).JSON(readiness)
+		}
+		return context.JSON(readiness)
+	}
+}
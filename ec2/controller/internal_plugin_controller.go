@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"errors"
 	"strconv"
 	"strings"
@@ -8,17 +9,28 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/audit"
 	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/jobs"
 	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/payment"
 	"tannar.moss/backend/internal/repository"
 	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/search"
 	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/types"
 	"tannar.moss/backend/internal/utils"
 )
 
 type InternalPluginController interface {
 	GetPublicService() service.Public
+	GetLogger() logger.Logger
+	Shutdown()
+	Healthz() fiber.Handler
+	Readyz() fiber.Handler
+	Metrics() fiber.Handler
 	Register() fiber.Handler
 	Login() fiber.Handler
 	User() fiber.Handler
@@ -32,6 +44,34 @@ type InternalPluginController interface {
 	UpdateOrder() fiber.Handler
 	GetOrder() fiber.Handler
 	DeleteOrder() fiber.Handler
+	CancelOrder() fiber.Handler
+	CreateReturn() fiber.Handler
+	GetOrderReturns() fiber.Handler
+	GetReturn() fiber.Handler
+	ApproveReturn() fiber.Handler
+	RejectReturn() fiber.Handler
+	AllDiscounts() fiber.Handler
+	GetDiscount() fiber.Handler
+	CreateDiscount() fiber.Handler
+	UpdateDiscount() fiber.Handler
+	DeleteDiscount() fiber.Handler
+	QuoteDiscounts() fiber.Handler
+	ApplyOrderDiscount() fiber.Handler
+	AllTaxRates() fiber.Handler
+	GetTaxRate() fiber.Handler
+	CreateTaxRate() fiber.Handler
+	UpdateTaxRate() fiber.Handler
+	DeleteTaxRate() fiber.Handler
+	AllShippingZones() fiber.Handler
+	GetShippingZone() fiber.Handler
+	CreateShippingZone() fiber.Handler
+	UpdateShippingZone() fiber.Handler
+	DeleteShippingZone() fiber.Handler
+	GetDeliverySlots() fiber.Handler
+	CreateDeliverySlot() fiber.Handler
+	DeleteDeliverySlot() fiber.Handler
+	QuoteOrderShipping() fiber.Handler
+	ReserveOrderSlot() fiber.Handler
 	Export() fiber.Handler
 	CreateFile() fiber.Handler
 	AllPermissions() fiber.Handler
@@ -40,6 +80,34 @@ type InternalPluginController interface {
 	GetProduct() fiber.Handler
 	UpdateProduct() fiber.Handler
 	DeleteProduct() fiber.Handler
+	ImportProducts() fiber.Handler
+	GetProductImport() fiber.Handler
+	ExportProducts() fiber.Handler
+	AllCategories() fiber.Handler
+	GetCategory() fiber.Handler
+	GetCategoryProducts() fiber.Handler
+	CreateCategory() fiber.Handler
+	UpdateCategory() fiber.Handler
+	DeleteCategory() fiber.Handler
+	AllAttributes() fiber.Handler
+	CreateAttribute() fiber.Handler
+	UpdateAttribute() fiber.Handler
+	DeleteAttribute() fiber.Handler
+	GetProductVariants() fiber.Handler
+	CreateVariant() fiber.Handler
+	UpdateVariant() fiber.Handler
+	DeleteVariant() fiber.Handler
+	GetProductReviews() fiber.Handler
+	CreateReview() fiber.Handler
+	UpdateReview() fiber.Handler
+	DeleteReview() fiber.Handler
+	GetReviewQueue() fiber.Handler
+	ModerateReview() fiber.Handler
+	GetWishlist() fiber.Handler
+	AddWishlistItem() fiber.Handler
+	RemoveWishlistItem() fiber.Handler
+	MoveWishlistItemToCart() fiber.Handler
+	AuditLog() fiber.Handler
 	AllRoles() fiber.Handler
 	CreateRole() fiber.Handler
 	UpdateRole() fiber.Handler
@@ -54,15 +122,100 @@ type InternalPluginController interface {
 }
 
 type InternalPluginControllerImpl struct {
-	publicService  service.Public
-	privateService service.Private
-	logger         logger.Logger
+	publicService     service.Public
+	privateService    service.Private
+	returnsService    service.Returns
+	discountsService  service.Discounts
+	taxesService      service.Taxes
+	shippingService   service.Shipping
+	exportsService    service.Exports
+	analyticsService  service.Analytics
+	productsService   service.Products
+	categoriesService service.Categories
+	variantsService   service.Variants
+	ordersService     service.Orders
+	importsService    service.Imports
+	reviewsService    service.Reviews
+	wishlistsService  service.Wishlists
+	auditService      service.Audit
+	healthService     service.Health
+	stopAnalyticsJob  func()
+	exportTimeout     time.Duration
+	logger            logger.Logger
 }
 
 func (controller *InternalPluginControllerImpl) GetPublicService() service.Public {
 	return controller.publicService
 }
 
+func (controller *InternalPluginControllerImpl) GetLogger() logger.Logger {
+	return controller.logger
+}
+
+// streamContext reads what a body stream writer needs out of the request while the
+// handler still runs: by the time fiber calls the writer it has released the request,
+// the timeout middleware has cancelled its context and the request span has ended.
+// The returned start gives the writer a fresh context bounded by the export timeout,
+// carrying the request's logger and a span continuing its trace; finish ends both.
+func (controller *InternalPluginControllerImpl) streamContext(c *fiber.Ctx, name string) (start func() (context.Context, func(error)), requestLogger logger.Logger) {
+	requestLogger = controller.requestLogger(c)
+	parent := tracing.SpanFromContext(c.UserContext()).SpanContext()
+	timeout := controller.exportTimeout
+
+	start = func() (context.Context, func(error)) {
+		ctx, cancel := context.WithTimeout(logger.NewContext(context.Background(), requestLogger), timeout)
+		ctx, span := tracing.Start(ctx, name, tracing.WithRemoteParent(parent))
+		return ctx, func(err error) {
+			span.RecordError(err)
+			span.End()
+			cancel()
+		}
+	}
+	return start, requestLogger
+}
+
+// requestLogger returns the logger the middleware derived for the request, carrying
+// its trace id, route, IP and user.
+func (controller *InternalPluginControllerImpl) requestLogger(context *fiber.Ctx) logger.Logger {
+	return logger.FromContext(context.UserContext(), controller.logger)
+}
+
+// Shutdown stops the background jobs, closes the services once running imports have
+// finished and flushes buffered logs. Call it after the server has drained.
+func (controller *InternalPluginControllerImpl) Shutdown() {
+	controller.logger.Info("Shutting down... ")
+	controller.stopAnalyticsJob()
+	// imports write through the shared pools, so they finish before anything closes them
+	controller.importsService.Shutdown()
+
+	controller.publicService.Shutdown()
+	controller.privateService.Shutdown()
+	controller.returnsService.Shutdown()
+	controller.discountsService.Shutdown()
+	controller.taxesService.Shutdown()
+	controller.shippingService.Shutdown()
+	controller.exportsService.Shutdown()
+	controller.analyticsService.Shutdown()
+	controller.productsService.Shutdown()
+	controller.categoriesService.Shutdown()
+	controller.variantsService.Shutdown()
+	controller.ordersService.Shutdown()
+	controller.reviewsService.Shutdown()
+	controller.wishlistsService.Shutdown()
+	controller.auditService.Shutdown()
+	controller.healthService.Shutdown()
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := tracing.Shutdown(flushCtx)
+	if err != nil {
+		controller.logger.Errorf("Unabled to flush spans: %s", err.Error())
+	}
+
+	controller.logger.Info("System stopped... ")
+	controller.logger.PublishSumoLogs()
+}
+
 func (controller *InternalPluginControllerImpl) getUserIdSession(context *fiber.Ctx) (uint64, error) {
 	jwt, err := controller.getJwtTokenFromSession(context)
 	if err != nil {
@@ -70,12 +223,12 @@ func (controller *InternalPluginControllerImpl) getUserIdSession(context *fiber.
 	}
 	issuer, err := utils.GetIssuerFromJwt(jwt, constant.PASSWORD_SECRET_HASHING_KEY)
 	if err != nil {
-		controller.logger.Errorf("Cant get issuer from jwt '%s'", issuer)
+		controller.requestLogger(context).Errorf("Cant get issuer from jwt '%s'", issuer)
 		return 0, types.NewInternalServerError()
 	}
 	userId, err := strconv.ParseUint(issuer, 10, 64)
 	if err != nil {
-		controller.logger.Errorf("Cant parse as Uint: '%s'", issuer)
+		controller.requestLogger(context).Errorf("Cant parse as Uint: '%s'", issuer)
 		return 0, types.NewInternalServerError()
 	}
 	return userId, nil
@@ -99,13 +252,24 @@ func (controller *InternalPluginControllerImpl) getJwtTokenFromSession(context *
 	return "", errors.New("jwt token not found in headers or cookies")
 }
 
-// AddOrder implements InternalPluginController.
-func (InternalPluginControllerImpl) AddOrder() fiber.Handler {
-	panic("unimplemented")
+func (controller *InternalPluginControllerImpl) getIdParam(context *fiber.Ctx) (uint64, error) {
+	id, err := context.ParamsInt("id")
+	if err != nil || id <= 0 {
+		controller.requestLogger(context).Infof("Invalid id parameter: '%s'", context.Params("id"))
+		return 0, types.NewInvalidInputError()
+	}
+	return uint64(id), nil
+}
+
+func (controller *InternalPluginControllerImpl) getAuditSource(context *fiber.Ctx, userId uint64) audit.Source {
+	return audit.Source{
+		ActorID:  userId,
+		SourceIP: context.IP(),
+	}
 }
 
-// GetOrder implements InternalPluginController.
-func (InternalPluginControllerImpl) GetOrder() fiber.Handler {
+// AddOrder implements InternalPluginController.
+func (InternalPluginControllerImpl) AddOrder() fiber.Handler {
 	panic("unimplemented")
 }
 
@@ -119,11 +283,6 @@ func (InternalPluginControllerImpl) UpdateOrder() fiber.Handler {
 	panic("unimplemented")
 }
 
-// CreateOrder implements InternalPluginController.
-func (InternalPluginControllerImpl) CreateOrder() fiber.Handler {
-	panic("unimplemented")
-}
-
 // AllOrders implements InternalPluginController.
 func (InternalPluginControllerImpl) AllOrders() fiber.Handler {
 	panic("unimplemented")
@@ -134,11 +293,6 @@ func (InternalPluginControllerImpl) AllPermissions() fiber.Handler {
 	panic("unimplemented")
 }
 
-// AllProducts implements InternalPluginController.
-func (InternalPluginControllerImpl) AllProducts() fiber.Handler {
-	panic("unimplemented")
-}
-
 // AllRoles implements InternalPluginController.
 func (InternalPluginControllerImpl) AllRoles() fiber.Handler {
 	panic("unimplemented")
@@ -149,21 +303,6 @@ func (InternalPluginControllerImpl) AllUsers() fiber.Handler {
 	panic("unimplemented")
 }
 
-// Chart implements InternalPluginController.
-func (InternalPluginControllerImpl) Chart() fiber.Handler {
-	panic("unimplemented")
-}
-
-// CreateFile implements InternalPluginController.
-func (InternalPluginControllerImpl) CreateFile() fiber.Handler {
-	panic("unimplemented")
-}
-
-// CreateProduct implements InternalPluginController.
-func (InternalPluginControllerImpl) CreateProduct() fiber.Handler {
-	panic("unimplemented")
-}
-
 // CreateRole implements InternalPluginController.
 func (InternalPluginControllerImpl) CreateRole() fiber.Handler {
 	panic("unimplemented")
@@ -174,11 +313,6 @@ func (InternalPluginControllerImpl) CreateUser() fiber.Handler {
 	panic("unimplemented")
 }
 
-// DeleteProduct implements InternalPluginController.
-func (InternalPluginControllerImpl) DeleteProduct() fiber.Handler {
-	panic("unimplemented")
-}
-
 // DeleteRole implements InternalPluginController.
 func (InternalPluginControllerImpl) DeleteRole() fiber.Handler {
 	panic("unimplemented")
@@ -189,16 +323,6 @@ func (InternalPluginControllerImpl) DeleteUser() fiber.Handler {
 	panic("unimplemented")
 }
 
-// Export implements InternalPluginController.
-func (InternalPluginControllerImpl) Export() fiber.Handler {
-	panic("unimplemented")
-}
-
-// GetProduct implements InternalPluginController.
-func (InternalPluginControllerImpl) GetProduct() fiber.Handler {
-	panic("unimplemented")
-}
-
 // GetRole implements InternalPluginController.
 func (InternalPluginControllerImpl) GetRole() fiber.Handler {
 	panic("unimplemented")
@@ -226,7 +350,7 @@ func (controller *InternalPluginControllerImpl) marshalErrorResponse(context *fi
 // Login implements InternalPluginController.
 func (controller *InternalPluginControllerImpl) Login() fiber.Handler {
 	return func(context *fiber.Ctx) error {
-		loginResponse, err := controller.publicService.Login(string(context.Body()))
+		loginResponse, err := controller.publicService.Login(context.UserContext(), string(context.Body()))
 
 		if err != nil {
 			return controller.marshalErrorResponse(context, err)
@@ -249,7 +373,7 @@ func (InternalPluginControllerImpl) Logout() fiber.Handler {
 
 func (controller InternalPluginControllerImpl) Register() fiber.Handler {
 	return func(context *fiber.Ctx) error {
-		loginResponse, err := controller.publicService.Register(string(context.Body()))
+		loginResponse, err := controller.publicService.Register(context.UserContext(), string(context.Body()), context.IP())
 
 		if err != nil {
 			return controller.marshalErrorResponse(context, err)
@@ -271,7 +395,7 @@ func (controller *InternalPluginControllerImpl) UpdateInfo() fiber.Handler {
 		if err != nil {
 			return controller.marshalErrorResponse(context, err)
 		}
-		userResponse, err := controller.privateService.UpdateUserInfo(userId, string(context.Body()), userId)
+		userResponse, err := controller.privateService.UpdateUserInfo(context.UserContext(), userId, string(context.Body()), controller.getAuditSource(context, userId))
 		if err != nil {
 			return context.JSON(utils.FormatErrorAPIGatewayResponse(err))
 		}
@@ -285,7 +409,7 @@ func (controller *InternalPluginControllerImpl) UpdatePassword() fiber.Handler {
 		if err != nil {
 			return controller.marshalErrorResponse(context, err)
 		}
-		updatedResponse, err := controller.privateService.UpdateUserPassword(userId, string(context.Body()), userId)
+		updatedResponse, err := controller.privateService.UpdateUserPassword(context.UserContext(), userId, string(context.Body()), controller.getAuditSource(context, userId))
 		if err != nil {
 			return controller.marshalErrorResponse(context, err)
 		}
@@ -293,11 +417,6 @@ func (controller *InternalPluginControllerImpl) UpdatePassword() fiber.Handler {
 	}
 }
 
-// UpdateProduct implements InternalPluginController.
-func (InternalPluginControllerImpl) UpdateProduct() fiber.Handler {
-	panic("unimplemented")
-}
-
 // UpdateRole implements InternalPluginController.
 func (InternalPluginControllerImpl) UpdateRole() fiber.Handler {
 	panic("unimplemented")
@@ -318,7 +437,9 @@ func (InternalPluginControllerImpl) User() fiber.Handler {
 	panic("unimplemented")
 }
 
-func NewInternalPluginController() InternalPluginController {
+// NewInternalPluginController wires the services, returning an error when the
+// database writer is still unreachable after its startup retries.
+func NewInternalPluginController() (InternalPluginController, error) {
 	// todo: remove hardcode databse config and use aws secret
 	genericUserConfig := mysql.DatabaseConfig{
 		Host:              "localhost",
@@ -329,25 +450,97 @@ func NewInternalPluginController() InternalPluginController {
 		Database:          "go_admin",
 		Username:          "root",
 		Password:          "root",
+		// the server is long running and concurrent, unlike the lambdas
+		MaxOpenConns:        20,
+		MaxIdleConns:        5,
+		ConnMaxLifetime:     300,
+		ConnMaxIdleTime:     60,
+		ConnectRetries:      5,
+		RetryBackoffMillis:  500,
+		HealthCheckInterval: 10,
 	}
 
 	logger := logger.NewSimpleLogger("DEBUG", false)
-	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig)
+	tracing.Configure("backend-ec2", logger)
+	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig, logger)
 	if err != nil {
 		logger.Errorf("Unabled to connect to database: %s", err.Error())
-		panic("DB down!!!")
+		return nil, err
 	}
 
+	dbConn.RegisterMetrics(metrics.Default)
+
 	userRepo := repository.NewMySqlUserRepository(logger, *dbConn)
+	orderRepo := repository.NewMySqlOrderRepository(logger, *dbConn)
+	returnRepo := repository.NewMySqlReturnRepository(logger, *dbConn)
+	productRepo := repository.NewMySqlProductRepository(logger, *dbConn)
+	discountRepo := repository.NewMySqlDiscountRepository(logger, *dbConn)
+	taxRepo := repository.NewMySqlTaxRepository(logger, *dbConn)
+	shippingRepo := repository.NewMySqlShippingRepository(logger, *dbConn)
+	analyticsRepo := repository.NewMySqlAnalyticsRepository(logger, *dbConn)
+	categoryRepo := repository.NewMySqlCategoryRepository(logger, *dbConn)
+	attributeRepo := repository.NewMySqlAttributeRepository(logger, *dbConn)
+	importRepo := repository.NewMySqlProductImportRepository(logger, *dbConn)
+	reviewRepo := repository.NewMySqlReviewRepository(logger, *dbConn)
+	wishlistRepo := repository.NewMySqlWishlistRepository(logger, *dbConn)
+	auditRepo := repository.NewMySqlAuditRepository(logger, *dbConn)
+	healthRepo := repository.NewMySqlHealthRepository(logger, *dbConn)
+	searchIndex := search.NewMySqlIndex(logger, *dbConn)
+	unitOfWork := repository.NewMySqlUnitOfWork(logger, *dbConn)
 	validatorService := service.NewValidator(logger, *validator.New())
 	publicService := service.NewPublicService(validatorService, userRepo, logger)
 	privateService := service.NewPrivateService(validatorService, userRepo, logger)
+	returnsService := service.NewReturnsService(validatorService, unitOfWork, orderRepo, returnRepo, productRepo, shippingRepo, userRepo, payment.NewStandInGateway(logger), logger)
+	taxesService := service.NewTaxesService(validatorService, taxRepo, orderRepo, productRepo, logger)
+	shippingService := service.NewShippingService(validatorService, unitOfWork, shippingRepo, orderRepo, productRepo, userRepo, logger)
+	discountsService := service.NewDiscountsService(validatorService, unitOfWork, discountRepo, orderRepo, productRepo, userRepo, taxesService, shippingService, logger)
+	exportsService := service.NewExportsService(validatorService, orderRepo, userRepo, logger)
+	analyticsService := service.NewAnalyticsService(analyticsRepo, logger)
+	productsService := service.NewProductsService(validatorService, productRepo, categoryRepo, searchIndex, logger)
+	categoriesService := service.NewCategoriesService(validatorService, categoryRepo, logger)
+	variantsService := service.NewVariantsService(validatorService, attributeRepo, productRepo, logger)
+	ordersService := service.NewOrdersService(validatorService, unitOfWork, orderRepo, productRepo, userRepo, discountsService, shippingService, logger)
+	importsService := service.NewImportsService(validatorService, importRepo, productRepo, productsService, logger)
+	reviewsService := service.NewReviewsService(validatorService, reviewRepo, productRepo, userRepo, logger)
+	wishlistsService := service.NewWishlistsService(validatorService, wishlistRepo, productRepo, logger)
+	auditService := service.NewAuditService(validatorService, auditRepo, logger)
+	healthService := service.NewHealthService(healthRepo, logger)
+
+	refreshMinutes, err := strconv.Atoi(utils.Getenv("ANALYTICS_REFRESH_MINUTES", "15"))
+	if err != nil || refreshMinutes <= 0 {
+		logger.Errorf("Invalid ANALYTICS_REFRESH_MINUTES, defaulting to 15")
+		refreshMinutes = 15
+	}
+	exportSeconds, err := strconv.Atoi(utils.Getenv("EXPORT_TIMEOUT_SECONDS", "300"))
+	if err != nil || exportSeconds <= 0 {
+		logger.Errorf("Invalid EXPORT_TIMEOUT_SECONDS, defaulting to 300")
+		exportSeconds = 300
+	}
+
+	stopAnalyticsJob := jobs.Schedule("RefreshSalesSummaries", time.Duration(refreshMinutes)*time.Minute, logger, analyticsService.RefreshSummaries)
 
 	logger.Info("System started... ")
 
 	return &InternalPluginControllerImpl{
-		publicService:  publicService,
-		privateService: privateService,
-		logger:         logger,
-	}
+		publicService:     publicService,
+		privateService:    privateService,
+		returnsService:    returnsService,
+		discountsService:  discountsService,
+		taxesService:      taxesService,
+		shippingService:   shippingService,
+		exportsService:    exportsService,
+		analyticsService:  analyticsService,
+		productsService:   productsService,
+		categoriesService: categoriesService,
+		variantsService:   variantsService,
+		ordersService:     ordersService,
+		importsService:    importsService,
+		reviewsService:    reviewsService,
+		wishlistsService:  wishlistsService,
+		auditService:      auditService,
+		healthService:     healthService,
+		stopAnalyticsJob:  stopAnalyticsJob,
+		exportTimeout:     time.Duration(exportSeconds) * time.Second,
+		logger:            logger,
+	}, nil
 }
@@ -0,0 +1,14 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/metrics"
+)
+
+// Metrics implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) Metrics() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		context.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		return metrics.Default.WritePrometheus(context)
+	}
+}
@@ -0,0 +1,37 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+// CreateOrder implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateOrder() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderResponse, err := controller.ordersService.CreateOrder(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(orderResponse)
+	}
+}
+
+// GetOrder implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetOrder() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderResponse, err := controller.ordersService.GetOrder(context.UserContext(), orderId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(orderResponse)
+	}
+}
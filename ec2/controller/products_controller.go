@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/export"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/types"
+)
+
+func (controller *InternalPluginControllerImpl) getMoneyQuery(context *fiber.Ctx, key string) (*money.Money, error) {
+	value := context.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	amount, err := money.Parse(value, money.DefaultCurrency)
+	if err != nil {
+		controller.requestLogger(context).Infof("Invalid '%s' query parameter: '%s'", key, value)
+		return nil, types.NewInvalidInputError()
+	}
+	return &amount, nil
+}
+
+// AllProducts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllProducts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		minPrice, err := controller.getMoneyQuery(context, "min_price")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		maxPrice, err := controller.getMoneyQuery(context, "max_price")
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		searchResponse, err := controller.productsService.Search(context.UserContext(), model.ProductSearchRequest{
+			Query:    context.Query("q"),
+			MinPrice: minPrice,
+			MaxPrice: maxPrice,
+			Sort:     context.Query("sort"),
+			Page:     context.QueryInt("page"),
+			PerPage:  context.QueryInt("per_page"),
+		})
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(searchResponse)
+	}
+}
+
+// GetProduct implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetProduct() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productResponse, err := controller.productsService.GetProduct(context.UserContext(), productId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(productResponse)
+	}
+}
+
+// CreateProduct implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateProduct() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productResponse, err := controller.productsService.CreateProduct(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(productResponse)
+	}
+}
+
+// UpdateProduct implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateProduct() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productResponse, err := controller.productsService.UpdateProduct(context.UserContext(), productId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(productResponse)
+	}
+}
+
+// DeleteProduct implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteProduct() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.productsService.DeleteProduct(context.UserContext(), productId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// ImportProducts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ImportProducts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		importResponse, err := controller.importsService.StartProductImport(context.UserContext(), context.Body(), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.Status(fiber.StatusAccepted).JSON(importResponse)
+	}
+}
+
+// GetProductImport implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetProductImport() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		importId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		importResponse, err := controller.importsService.GetProductImport(context.UserContext(), importId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(importResponse)
+	}
+}
+
+// ExportProducts implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ExportProducts() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		filename := fmt.Sprintf("products_%s.csv", time.Now().Format("20060102"))
+		context.Set(fiber.HeaderContentType, export.ContentType(export.FORMAT_CSV))
+		context.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+		startStream, requestLogger := controller.streamContext(context, "Stream product export")
+		context.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+			ctx, finish := startStream()
+			err := controller.importsService.ExportProducts(ctx, w)
+			finish(err)
+			if err != nil {
+				requestLogger.Errorf("Unabled to finish export '%s': %s", filename, err.Error())
+			}
+			w.Flush()
+		})
+		return nil
+	}
+}
@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// CancelOrder implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CancelOrder() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnResponse, err := controller.returnsService.CancelOrder(context.UserContext(), orderId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnResponse)
+	}
+}
+
+// CreateReturn implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateReturn() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnResponse, err := controller.returnsService.CreateReturn(context.UserContext(), orderId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnResponse)
+	}
+}
+
+// GetOrderReturns implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetOrderReturns() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnsResponse, err := controller.returnsService.GetOrderReturns(context.UserContext(), orderId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnsResponse)
+	}
+}
+
+// GetReturn implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetReturn() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnResponse, err := controller.returnsService.GetReturn(context.UserContext(), returnId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnResponse)
+	}
+}
+
+// ApproveReturn implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ApproveReturn() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnResponse, err := controller.returnsService.ApproveReturn(context.UserContext(), returnId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnResponse)
+	}
+}
+
+// RejectReturn implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) RejectReturn() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		returnResponse, err := controller.returnsService.RejectReturn(context.UserContext(), returnId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(returnResponse)
+	}
+}
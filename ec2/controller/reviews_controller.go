@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/types"
+)
+
+// GetProductReviews implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetProductReviews() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewsResponse, err := controller.reviewsService.GetProductReviews(context.UserContext(), productId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(reviewsResponse)
+	}
+}
+
+// CreateReview implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateReview() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewResponse, err := controller.reviewsService.CreateReview(context.UserContext(), productId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(reviewResponse)
+	}
+}
+
+// UpdateReview implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateReview() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewResponse, err := controller.reviewsService.UpdateReview(context.UserContext(), reviewId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(reviewResponse)
+	}
+}
+
+// DeleteReview implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteReview() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.reviewsService.DeleteReview(context.UserContext(), reviewId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetReviewQueue implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetReviewQueue() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		statusId := context.QueryInt("status", constant.REVIEW_STATUS_PENDING)
+		if statusId <= 0 {
+			controller.requestLogger(context).Infof("Invalid status query parameter: '%s'", context.Query("status"))
+			return controller.marshalErrorResponse(context, types.NewInvalidInputError())
+		}
+		reviewsResponse, err := controller.reviewsService.GetReviewQueue(context.UserContext(), uint64(statusId))
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(reviewsResponse)
+	}
+}
+
+// ModerateReview implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ModerateReview() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		reviewResponse, err := controller.reviewsService.ModerateReview(context.UserContext(), reviewId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(reviewResponse)
+	}
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/types"
+)
+
+// AllShippingZones implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllShippingZones() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		zonesResponse, err := controller.shippingService.AllZones(context.UserContext())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(zonesResponse)
+	}
+}
+
+// GetShippingZone implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetShippingZone() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		zoneId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		zoneResponse, err := controller.shippingService.GetZone(context.UserContext(), zoneId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(zoneResponse)
+	}
+}
+
+// CreateShippingZone implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateShippingZone() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		zoneResponse, err := controller.shippingService.CreateZone(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(zoneResponse)
+	}
+}
+
+// UpdateShippingZone implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateShippingZone() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		zoneId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		zoneResponse, err := controller.shippingService.UpdateZone(context.UserContext(), zoneId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(zoneResponse)
+	}
+}
+
+// DeleteShippingZone implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteShippingZone() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		zoneId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.shippingService.DeleteZone(context.UserContext(), zoneId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+func (controller *InternalPluginControllerImpl) getTimeQuery(context *fiber.Ctx, key string, fallback time.Time) (time.Time, error) {
+	value := context.Query(key)
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		controller.requestLogger(context).Infof("Invalid '%s' query parameter: '%s'", key, value)
+		return time.Time{}, types.NewInvalidInputError()
+	}
+	return parsed, nil
+}
+
+// GetDeliverySlots implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetDeliverySlots() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		zoneId := context.QueryInt("zone_id")
+		if zoneId <= 0 {
+			controller.requestLogger(context).Infof("Invalid zone_id query parameter: '%s'", context.Query("zone_id"))
+			return controller.marshalErrorResponse(context, types.NewInvalidInputError())
+		}
+		from, err := controller.getTimeQuery(context, "from", time.Now())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		to, err := controller.getTimeQuery(context, "to", from.AddDate(0, 0, 7))
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		slotsResponse, err := controller.shippingService.GetSlots(context.UserContext(), uint64(zoneId), from, to)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(slotsResponse)
+	}
+}
+
+// CreateDeliverySlot implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateDeliverySlot() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		slotResponse, err := controller.shippingService.CreateSlot(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(slotResponse)
+	}
+}
+
+// DeleteDeliverySlot implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteDeliverySlot() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		slotId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.shippingService.DeleteSlot(context.UserContext(), slotId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// QuoteOrderShipping implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) QuoteOrderShipping() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		quoteResponse, err := controller.shippingService.Quote(context.UserContext(), orderId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(quoteResponse)
+	}
+}
+
+// ReserveOrderSlot implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) ReserveOrderSlot() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		orderResponse, err := controller.shippingService.ReserveSlot(context.UserContext(), orderId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(orderResponse)
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// AllTaxRates implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllTaxRates() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		taxRatesResponse, err := controller.taxesService.AllTaxRates(context.UserContext())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(taxRatesResponse)
+	}
+}
+
+// GetTaxRate implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetTaxRate() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		taxRateId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		taxRateResponse, err := controller.taxesService.GetTaxRate(context.UserContext(), taxRateId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(taxRateResponse)
+	}
+}
+
+// CreateTaxRate implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateTaxRate() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		taxRateResponse, err := controller.taxesService.CreateTaxRate(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(taxRateResponse)
+	}
+}
+
+// UpdateTaxRate implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateTaxRate() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		taxRateId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		taxRateResponse, err := controller.taxesService.UpdateTaxRate(context.UserContext(), taxRateId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(taxRateResponse)
+	}
+}
+
+// DeleteTaxRate implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteTaxRate() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		taxRateId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.taxesService.DeleteTaxRate(context.UserContext(), taxRateId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
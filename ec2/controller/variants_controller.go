@@ -0,0 +1,139 @@
+package controller
+
+import "github.com/gofiber/fiber/v2"
+
+// AllAttributes implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AllAttributes() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		attributesResponse, err := controller.variantsService.AllAttributes(context.UserContext())
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(attributesResponse)
+	}
+}
+
+// CreateAttribute implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateAttribute() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		attributeResponse, err := controller.variantsService.CreateAttribute(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(attributeResponse)
+	}
+}
+
+// UpdateAttribute implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateAttribute() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		attributeId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		attributeResponse, err := controller.variantsService.UpdateAttribute(context.UserContext(), attributeId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(attributeResponse)
+	}
+}
+
+// DeleteAttribute implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteAttribute() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		attributeId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.variantsService.DeleteAttribute(context.UserContext(), attributeId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// GetProductVariants implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetProductVariants() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		variantsResponse, err := controller.variantsService.GetVariants(context.UserContext(), productId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(variantsResponse)
+	}
+}
+
+// CreateVariant implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) CreateVariant() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		productId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		variantResponse, err := controller.variantsService.CreateVariant(context.UserContext(), productId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(variantResponse)
+	}
+}
+
+// UpdateVariant implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) UpdateVariant() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		variantId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		variantResponse, err := controller.variantsService.UpdateVariant(context.UserContext(), variantId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(variantResponse)
+	}
+}
+
+// DeleteVariant implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) DeleteVariant() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		variantId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.variantsService.DeleteVariant(context.UserContext(), variantId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
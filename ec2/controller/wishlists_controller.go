@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetWishlist implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) GetWishlist() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		wishlistResponse, err := controller.wishlistsService.GetWishlist(context.UserContext(), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(wishlistResponse)
+	}
+}
+
+// AddWishlistItem implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) AddWishlistItem() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		itemResponse, err := controller.wishlistsService.AddWishlistItem(context.UserContext(), string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(itemResponse)
+	}
+}
+
+// RemoveWishlistItem implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) RemoveWishlistItem() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		itemId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		err = controller.wishlistsService.RemoveWishlistItem(context.UserContext(), itemId, userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.SendStatus(fiber.StatusNoContent)
+	}
+}
+
+// MoveWishlistItemToCart implements InternalPluginController.
+func (controller *InternalPluginControllerImpl) MoveWishlistItemToCart() fiber.Handler {
+	return func(context *fiber.Ctx) error {
+		userId, err := controller.getUserIdSession(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		itemId, err := controller.getIdParam(context)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		cartItem, err := controller.wishlistsService.MoveWishlistItemToCart(context.UserContext(), itemId, string(context.Body()), userId)
+		if err != nil {
+			return controller.marshalErrorResponse(context, err)
+		}
+		return context.JSON(cartItem)
+	}
+}
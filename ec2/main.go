@@ -1,9 +1,17 @@
 package main
 
 import (
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"tannar.moss/backend/ec2/routes"
+	"tannar.moss/backend/internal/utils"
 )
 
 func main() {
@@ -17,7 +25,40 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	routes.Setup(app)
+	controller, err := routes.Setup(app)
+	if err != nil {
+		log.Printf("Unable to start: %s", err)
+		os.Exit(1)
+	}
+
+	drainSeconds, err := strconv.Atoi(utils.Getenv("SHUTDOWN_TIMEOUT_SECONDS", "30"))
+	if err != nil || drainSeconds <= 0 {
+		drainSeconds = 30
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":8000")
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-listenErr:
+		log.Printf("Server stopped: %s", err)
+		exitCode = 1
+	case sig := <-signals:
+		log.Printf("Received %s, draining requests for up to %ds", sig, drainSeconds)
+		// stops accepting connections and waits for in-flight requests to finish
+		err := app.ShutdownWithTimeout(time.Duration(drainSeconds) * time.Second)
+		if err != nil {
+			log.Printf("Requests still in flight after %ds: %s", drainSeconds, err)
+			exitCode = 1
+		}
+	}
 
-	app.Listen(":8000")
+	controller.Shutdown()
+	os.Exit(exitCode)
 }
@@ -2,10 +2,13 @@ package middleware
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository/mysql"
 	internalService "tannar.moss/backend/internal/service"
 	"tannar.moss/backend/internal/utils"
 )
@@ -31,7 +34,7 @@ func getJwtTokenFromSession(context *fiber.Ctx) (string, error) {
 func IsAuthenticated(c *fiber.Ctx, service internalService.Public) error {
 	jwt := c.Cookies("jwt")
 
-	err := service.IsAuthenticated(jwt)
+	err := service.IsAuthenticated(c.UserContext(), jwt)
 	if err != nil {
 		return err
 	}
@@ -47,5 +50,16 @@ func IsAuthenticated(c *fiber.Ctx, service internalService.Public) error {
 
 	c.Set("userId", issuer)
 
+	// reads default to session consistency, so a user sees their own writes
+	userId, err := strconv.ParseUint(issuer, 10, 64)
+	if err != nil {
+		return errors.New("could not parse issuer from jwt")
+	}
+	ctx := mysql.WithSession(c.UserContext(), userId)
+	if requestLogger := logger.FromContext(ctx, nil); requestLogger != nil {
+		ctx = logger.NewContext(ctx, requestLogger.Derive("", logger.Uint64("user_id", userId)))
+	}
+	c.SetUserContext(ctx)
+
 	return c.Next()
 }
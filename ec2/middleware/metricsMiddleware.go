@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/types"
+)
+
+// RecordMetrics counts and times every request by method and matched route pattern,
+// so /api/order/1 and /api/order/2 share /api/order/:id.
+func RecordMetrics(c *fiber.Ctx) error {
+	started := time.Now()
+	err := c.Next()
+
+	// the route is only known once the chain has matched it
+	route := c.Route().Path
+	metrics.HTTPRequests.Inc(c.Method(), route, strconv.Itoa(responseStatus(c, err)))
+	metrics.HTTPRequestDuration.ObserveSince(started, c.Method(), route)
+	return err
+}
+
+// responseStatus is the status the error handler will answer with when err is not nil,
+// since the response only carries it once the chain has returned.
+func responseStatus(c *fiber.Ctx, err error) int {
+	if err == nil {
+		return c.Response().StatusCode()
+	}
+	if socketErr, ok := err.(*types.SocketError); ok {
+		return socketErr.StatusCode()
+	}
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return fiberErr.Code
+	}
+	return fiber.StatusInternalServerError
+}
@@ -3,15 +3,26 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	internalService "tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/types"
 )
 
 func IsAuthorized(c *fiber.Ctx, page string, service internalService.Public) error {
-	jwt := c.Cookies("jwt")
+	jwt, err := getJwtTokenFromSession(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": err.Error(),
+		})
+	}
 
-	err := service.IsAuthorized(jwt, page)
+	err = service.IsAuthorized(c.UserContext(), jwt, page)
 	if err != nil {
+		if typedErr, ok := err.(*types.SocketError); ok {
+			return c.Status(typedErr.StatusCode()).JSON(fiber.Map{
+				"message": typedErr.Error(),
+			})
+		}
 		return err
 	}
 
-	return nil
+	return c.Next()
 }
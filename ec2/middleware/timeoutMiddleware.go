@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// WithRequestTimeout gives the handlers a context that is cancelled once timeout has
+// passed or the handler chain returns, whichever is first. Services pass it down so
+// the driver abandons queries nobody is waiting on. It is not derived from the
+// fasthttp request context, which is cancelled as soon as shutdown starts, so
+// in-flight requests can drain.
+func WithRequestTimeout(c *fiber.Ctx, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), timeout)
+	defer cancel()
+
+	c.SetUserContext(ctx)
+	return c.Next()
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/tracing"
+)
+
+// StartTrace runs the request in a server span, continuing the caller's trace when it
+// sends a valid W3C traceparent header, and gives it a logger derived from base with
+// the span's trace id and the request's route and IP. The span's traceparent
+// is echoed back so clients can find the trace.
+func StartTrace(c *fiber.Ctx, base logger.Logger) error {
+	var options []tracing.StartOption
+	options = append(options, tracing.WithKind(tracing.SpanKindServer))
+	parent, err := tracing.ParseTraceParent(c.Get(tracing.TraceParentHeader))
+	if err == nil {
+		options = append(options, tracing.WithRemoteParent(parent))
+	}
+
+	ctx, span := tracing.Start(c.UserContext(), c.Method()+" "+c.Path(), options...)
+	defer span.End()
+	requestLogger := base.Derive(span.SpanContext().TraceID.String(),
+		logger.String("route", c.Method()+" "+c.Path()),
+		// fiber reuses the IP's memory once the request ends, the logger may outlive it
+		logger.String("ip", strings.Clone(c.IP())),
+	)
+	c.SetUserContext(logger.NewContext(ctx, requestLogger))
+	c.Set(tracing.TraceParentHeader, span.SpanContext().TraceParent())
+
+	err = c.Next()
+
+	// the route is only known once the chain has matched it
+	span.SetAttribute("http.method", c.Method())
+	span.SetAttribute("http.route", c.Route().Path)
+	span.SetAttribute("http.status_code", responseStatus(c, err))
+	span.RecordError(err)
+	return err
+}
@@ -1,13 +1,47 @@
 package routes
 
 import (
+	"strconv"
+	"time"
+
 	"github.com/gofiber/fiber/v2"
 	"tannar.moss/backend/ec2/controller"
 	"tannar.moss/backend/ec2/middleware"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/utils"
 )
 
-func Setup(app *fiber.App) {
-	controller := controller.NewInternalPluginController()
+func requirePermission(page string, controller controller.InternalPluginController) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return middleware.IsAuthorized(c, page, controller.GetPublicService())
+	}
+}
+
+// Setup registers the routes on app and returns the controller serving them, to be
+// shut down once app has drained. It fails when the controller cannot be created.
+func Setup(app *fiber.App) (controller.InternalPluginController, error) {
+	controller, err := controller.NewInternalPluginController()
+	if err != nil {
+		return nil, err
+	}
+
+	timeoutSeconds, err := strconv.Atoi(utils.Getenv("REQUEST_TIMEOUT_SECONDS", "30"))
+	if err != nil || timeoutSeconds <= 0 {
+		timeoutSeconds = 30
+	}
+	app.Use(func(c *fiber.Ctx) error {
+		return middleware.StartTrace(c, controller.GetLogger())
+	})
+	app.Use(middleware.RecordMetrics)
+	app.Use(func(c *fiber.Ctx) error {
+		return middleware.WithRequestTimeout(c, time.Duration(timeoutSeconds)*time.Second)
+	})
+
+	// health routes, probed by the load balancer without credentials
+	app.Get("/healthz", controller.Healthz())
+	app.Get("/readyz", controller.Readyz())
+	app.Get("/metrics", controller.Metrics())
+
 	// auth routes
 	app.Post("/api/register", controller.Register())
 	app.Put("/api/login", controller.Login())
@@ -20,6 +54,98 @@ func Setup(app *fiber.App) {
 	app.Put("/api/users/info", controller.UpdateInfo())
 	app.Put("/api/users/password", controller.UpdatePassword())
 
+	// orders routes
+	app.Post("/api/order", controller.CreateOrder())
+	app.Get("/api/order/:id", controller.GetOrder())
+
+	// returns routes
+	app.Put("/api/order/:id/cancel", controller.CancelOrder())
+	app.Get("/api/order/:id/returns", controller.GetOrderReturns())
+	app.Post("/api/order/:id/returns", controller.CreateReturn())
+	app.Get("/api/returns/:id", controller.GetReturn())
+	app.Put("/api/returns/:id/approve", requirePermission(constant.REFUND_ORDER_PERMISSION, controller), controller.ApproveReturn())
+	app.Put("/api/returns/:id/reject", requirePermission(constant.REFUND_ORDER_PERMISSION, controller), controller.RejectReturn())
+
+	// discounts routes
+	app.Post("/api/discounts/quote", controller.QuoteDiscounts())
+	app.Put("/api/order/:id/discount", controller.ApplyOrderDiscount())
+	app.Get("/api/discounts", requirePermission(constant.MANAGE_DISCOUNT_PERMISSION, controller), controller.AllDiscounts())
+	app.Get("/api/discounts/:id", requirePermission(constant.MANAGE_DISCOUNT_PERMISSION, controller), controller.GetDiscount())
+	app.Post("/api/discounts", requirePermission(constant.MANAGE_DISCOUNT_PERMISSION, controller), controller.CreateDiscount())
+	app.Put("/api/discounts/:id", requirePermission(constant.MANAGE_DISCOUNT_PERMISSION, controller), controller.UpdateDiscount())
+	app.Delete("/api/discounts/:id", requirePermission(constant.MANAGE_DISCOUNT_PERMISSION, controller), controller.DeleteDiscount())
+
+	// tax routes
+	app.Get("/api/taxes", requirePermission(constant.MANAGE_TAX_PERMISSION, controller), controller.AllTaxRates())
+	app.Get("/api/taxes/:id", requirePermission(constant.MANAGE_TAX_PERMISSION, controller), controller.GetTaxRate())
+	app.Post("/api/taxes", requirePermission(constant.MANAGE_TAX_PERMISSION, controller), controller.CreateTaxRate())
+	app.Put("/api/taxes/:id", requirePermission(constant.MANAGE_TAX_PERMISSION, controller), controller.UpdateTaxRate())
+	app.Delete("/api/taxes/:id", requirePermission(constant.MANAGE_TAX_PERMISSION, controller), controller.DeleteTaxRate())
+
+	// shipping routes
+	app.Get("/api/order/:id/shipping", controller.QuoteOrderShipping())
+	app.Put("/api/order/:id/shipping", controller.ReserveOrderSlot())
+	app.Get("/api/shipping/slots", controller.GetDeliverySlots())
+	app.Post("/api/shipping/slots", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.CreateDeliverySlot())
+	app.Delete("/api/shipping/slots/:id", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.DeleteDeliverySlot())
+	app.Get("/api/shipping/zones", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.AllShippingZones())
+	app.Get("/api/shipping/zones/:id", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.GetShippingZone())
+	app.Post("/api/shipping/zones", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.CreateShippingZone())
+	app.Put("/api/shipping/zones/:id", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.UpdateShippingZone())
+	app.Delete("/api/shipping/zones/:id", requirePermission(constant.MANAGE_SHIPPING_PERMISSION, controller), controller.DeleteShippingZone())
+
+	// export routes
+	app.Post("/api/export", requirePermission(constant.EXPORT_ORDER_PERMISSION, controller), controller.Export())
+	app.Get("/api/order/:id/invoice", controller.CreateFile())
+
+	// products routes
+	app.Post("/api/products/import", requirePermission(constant.CREATE_PRODUCT_PERMISSION, controller), requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.ImportProducts())
+	app.Get("/api/products/imports/:id", requirePermission(constant.CREATE_PRODUCT_PERMISSION, controller), controller.GetProductImport())
+	app.Get("/api/products/export", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.ExportProducts())
+	app.Get("/api/products", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.AllProducts())
+	app.Get("/api/products/:id", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.GetProduct())
+	app.Post("/api/products", requirePermission(constant.CREATE_PRODUCT_PERMISSION, controller), controller.CreateProduct())
+	app.Put("/api/products/:id", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.UpdateProduct())
+	app.Delete("/api/products/:id", requirePermission(constant.DELETE_PRODUCT_PERMISSION, controller), controller.DeleteProduct())
+
+	// categories routes
+	app.Get("/api/categories", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.AllCategories())
+	app.Get("/api/categories/:slug", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.GetCategory())
+	app.Get("/api/categories/:slug/products", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.GetCategoryProducts())
+	app.Post("/api/categories", requirePermission(constant.CREATE_CATEGORY_PERMISSION, controller), controller.CreateCategory())
+	app.Put("/api/categories/:id", requirePermission(constant.EDIT_CATEGORY_PERMISSION, controller), controller.UpdateCategory())
+	app.Delete("/api/categories/:id", requirePermission(constant.DELETE_CATEGORY_PERMISSION, controller), controller.DeleteCategory())
+
+	// variants routes
+	app.Get("/api/attributes", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.AllAttributes())
+	app.Post("/api/attributes", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.CreateAttribute())
+	app.Put("/api/attributes/:id", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.UpdateAttribute())
+	app.Delete("/api/attributes/:id", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.DeleteAttribute())
+	app.Get("/api/products/:id/variants", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.GetProductVariants())
+	app.Post("/api/products/:id/variants", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.CreateVariant())
+	app.Put("/api/variants/:id", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.UpdateVariant())
+	app.Delete("/api/variants/:id", requirePermission(constant.EDIT_PRODUCT_PERMISSION, controller), controller.DeleteVariant())
+
+	// reviews routes
+	app.Get("/api/products/:id/reviews", requirePermission(constant.VIEW_PRODUCT_PERMISSION, controller), controller.GetProductReviews())
+	app.Post("/api/products/:id/reviews", controller.CreateReview())
+	app.Get("/api/reviews", requirePermission(constant.MODERATE_REVIEW_PERMISSION, controller), controller.GetReviewQueue())
+	app.Put("/api/reviews/:id", controller.UpdateReview())
+	app.Delete("/api/reviews/:id", controller.DeleteReview())
+	app.Put("/api/reviews/:id/moderate", requirePermission(constant.MODERATE_REVIEW_PERMISSION, controller), controller.ModerateReview())
+
+	// wishlist routes
+	app.Get("/api/wishlist", requirePermission(constant.VIEW_WISHLIST_PERMISSION, controller), controller.GetWishlist())
+	app.Post("/api/wishlist", requirePermission(constant.EDIT_WISHLIST_PERMISSION, controller), controller.AddWishlistItem())
+	app.Delete("/api/wishlist/:id", requirePermission(constant.EDIT_WISHLIST_PERMISSION, controller), controller.RemoveWishlistItem())
+	app.Post("/api/wishlist/:id/cart", requirePermission(constant.EDIT_WISHLIST_PERMISSION, controller), controller.MoveWishlistItemToCart())
+
+	// audit routes
+	app.Get("/api/audit", requirePermission(constant.VIEW_AUDIT_LOG_PERMISSION, controller), controller.AuditLog())
+
+	// analytics routes
+	app.Get("/api/chart", requirePermission(constant.VIEW_ANALYTICS_PERMISSION, controller), controller.Chart())
+
 	/*
 		app.Get("/api/user", controller.User())
 		app.Post("/api/logout", controller.Logout())
@@ -38,21 +164,12 @@ func Setup(app *fiber.App) {
 
 		app.Get("/api/permissions", controller.AllPermissions())
 
-		app.Get("/api/products", controller.AllProducts())
-		app.Get("/api/products/:id", controller.GetProduct())
-		app.Post("/api/products", controller.CreateProduct())
-		app.Put("/api/products/:id", controller.UpdateProduct())
-		app.Delete("/api/products/:id", controller.DeleteProduct())
-
 		app.Post("/api/upload", controller.Upload())
 		app.Static("/api/uploads", "/uploads")
 
-		app.Get("/api/chart", controller.Chart())
-
 		// orders route
 		app.Get("/api/orders", controller.AllOrders())
-		app.Get("/api/order/:id", controller.GetOrder())
-		app.Post("/api/export", controller.Export())
-		app.Put("/api/order/:id", controller.UpdateOrder())
-		app.Post("/api/order", controller.CreateOrder()) */
+		app.Put("/api/order/:id", controller.UpdateOrder()) */
+
+	return controller, nil
 }
@@ -2,14 +2,16 @@ module tannar.moss/backend
 
 go 1.21.1
 
-require github.com/aws/aws-lambda-go v1.43.0
+require (
+	github.com/aws/aws-lambda-go v1.43.0
+	github.com/google/uuid v1.5.0
+)
 
 require (
 	github.com/gabriel-vasile/mimetype v1.4.2 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/gofiber/utils v0.0.10 // indirect
-	github.com/google/uuid v1.5.0 // indirect
 	github.com/gorilla/schema v1.1.0 // indirect
 	github.com/leodido/go-urn v1.2.4 // indirect
 	github.com/mattn/go-runewidth v0.0.15 // indirect
@@ -0,0 +1,114 @@
+package audit
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"tannar.moss/backend/internal/logger"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Redacted replaces the value of secret columns in a diff, so the log shows that a
+// secret changed without keeping either version.
+const Redacted = "[REDACTED]"
+
+var secretColumns = []string{"password", "secret", "token"}
+
+// Source identifies who made a change and where the request came from.
+type Source struct {
+	ActorID  uint64
+	SourceIP string
+}
+
+// Entry describes the row an audited edit touches. EntityID is zero for inserts,
+// in which case the inserted id is used.
+type Entry struct {
+	Entity   string
+	EntityID uint64
+	Action   string
+	Source   Source
+}
+
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+func IsSecret(column string) bool {
+	column = strings.ToLower(column)
+	for _, secret := range secretColumns {
+		if strings.Contains(column, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff lists the columns whose values differ between the before and after images
+// of a row. A nil image stands for a row that does not exist, so every column of
+// the other image is reported.
+func Diff(before map[string]any, after map[string]any) map[string]Change {
+	columns := make(map[string]bool, len(before)+len(after))
+	for column := range before {
+		columns[column] = true
+	}
+	for column := range after {
+		columns[column] = true
+	}
+
+	changes := make(map[string]Change)
+	for column := range columns {
+		old, new := normalise(before[column]), normalise(after[column])
+		if reflect.DeepEqual(old, new) {
+			continue
+		}
+		if IsSecret(column) {
+			old, new = redact(old), redact(new)
+		}
+		changes[column] = Change{Before: old, After: new}
+	}
+
+	return changes
+}
+
+// Columns returns the changed column names in order, for log messages.
+func Columns(changes map[string]Change) []string {
+	columns := make([]string, 0, len(changes))
+	for column := range changes {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// TraceID reads the trace id of the request being served from the logger.
+func TraceID(log logger.Logger) string {
+	if traced, ok := log.(interface{ RequestId() string }); ok {
+		return traced.RequestId()
+	}
+	return ""
+}
+
+func normalise(value any) any {
+	switch typed := value.(type) {
+	case []byte:
+		return string(typed)
+	case fmt.Stringer:
+		return typed.String()
+	}
+	return value
+}
+
+func redact(value any) any {
+	if value == nil {
+		return nil
+	}
+	return Redacted
+}
@@ -0,0 +1,52 @@
+package audit_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/audit"
+)
+
+func TestDiff_withUpdatedRow_shouldListChangedColumnsOnly(t *testing.T) {
+	before := map[string]any{"id": int64(7), "first_name": []byte("Ann"), "last_name": []byte("Smith"), "updated_user": nil}
+	after := map[string]any{"id": int64(7), "first_name": []byte("Anna"), "last_name": []byte("Smith"), "updated_user": int64(7)}
+
+	changes := audit.Diff(before, after)
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changed columns but got '%v'", audit.Columns(changes))
+	}
+	if changes["first_name"].Before != "Ann" || changes["first_name"].After != "Anna" {
+		t.Errorf("Expected first_name to change from Ann to Anna but got '%+v'", changes["first_name"])
+	}
+	if changes["updated_user"].Before != nil || changes["updated_user"].After != int64(7) {
+		t.Errorf("Expected updated_user to change from nil to 7 but got '%+v'", changes["updated_user"])
+	}
+}
+
+func TestDiff_withSecretColumn_shouldRedactBothValues(t *testing.T) {
+	before := map[string]any{"hashed_password": []byte("$2a$old")}
+	after := map[string]any{"hashed_password": []byte("$2a$new")}
+
+	changes := audit.Diff(before, after)
+	change, ok := changes["hashed_password"]
+	if !ok {
+		t.Fatal("Expected hashed_password change to be recorded")
+	}
+	if change.Before != audit.Redacted || change.After != audit.Redacted {
+		t.Errorf("Expected hashed_password to be redacted but got '%+v'", change)
+	}
+}
+
+func TestDiff_withInsertedRow_shouldReportEveryColumn(t *testing.T) {
+	after := map[string]any{"id": int64(3), "email": []byte("a@b.co"), "hashed_password": []byte("$2a$x"), "deleted_at": nil}
+
+	changes := audit.Diff(nil, after)
+	if len(changes) != 3 {
+		t.Fatalf("Expected 3 changed columns but got '%v'", audit.Columns(changes))
+	}
+	if changes["email"].Before != nil || changes["email"].After != "a@b.co" {
+		t.Errorf("Expected email to be set to a@b.co but got '%+v'", changes["email"])
+	}
+	if changes["hashed_password"].Before != nil || changes["hashed_password"].After != audit.Redacted {
+		t.Errorf("Expected new hashed_password to be redacted but got '%+v'", changes["hashed_password"])
+	}
+}
@@ -4,3 +4,127 @@ const (
 	CUSTOMER_ROLE_ID            = 2
 	PASSWORD_SECRET_HASHING_KEY = "SECRET"
 )
+
+const (
+	// SCHEMA_VERSION is the latest database script the code depends on, see
+	// database/Schema_Script_2026_10_19-23_00.sql.
+	SCHEMA_VERSION = "2026_10_19-23_50"
+
+	HEALTH_STATUS_OK       = "ok"
+	HEALTH_STATUS_DEGRADED = "degraded"
+	HEALTH_STATUS_FAIL     = "fail"
+	HEALTH_CHECK_TIMEOUT   = 2 // seconds
+)
+
+const (
+	ORDER_STATUS_AWAITING_PAYMENT = 1
+	ORDER_STATUS_PENDING          = 2
+	ORDER_STATUS_SHIPPED          = 3
+	ORDER_STATUS_OUT_FOR_DELIVERY = 4
+	ORDER_STATUS_COMPLETE         = 5
+	ORDER_STATUS_CANCELLED        = 6
+)
+
+const (
+	RETURN_STATUS_REQUESTED = 1
+	RETURN_STATUS_APPROVED  = 2
+	RETURN_STATUS_REJECTED  = 3
+	RETURN_STATUS_REFUNDED  = 4
+)
+
+const (
+	REVIEW_STATUS_PENDING  = 1
+	REVIEW_STATUS_APPROVED = 2
+	REVIEW_STATUS_REJECTED = 3
+)
+
+const (
+	DISCOUNT_TYPE_PERCENTAGE   = 1
+	DISCOUNT_TYPE_FIXED_AMOUNT = 2
+)
+
+const (
+	TAX_CLASS_STANDARD   = 1
+	TAX_CLASS_REDUCED    = 2
+	TAX_CLASS_ZERO_RATED = 3
+)
+
+const (
+	SHIPPING_RATE_BASIS_WEIGHT = 1
+	SHIPPING_RATE_BASIS_PRICE  = 2
+)
+
+const (
+	DELIVERY_SLOT_BOOKING_DAYS = 14
+)
+
+const (
+	CHART_INTERVAL_DAY   = "day"
+	CHART_INTERVAL_WEEK  = "week"
+	CHART_INTERVAL_MONTH = "month"
+)
+
+const (
+	ANALYTICS_DEFAULT_RANGE_DAYS = 30
+	ANALYTICS_MAX_RANGE_DAYS     = 731
+	ANALYTICS_REFRESH_LOOKBACK   = 7
+	ANALYTICS_TOP_PRODUCTS_LIMIT = 10
+	ANALYTICS_MAX_PRODUCTS_LIMIT = 100
+)
+
+const (
+	SEARCH_SORT_RELEVANCE  = "relevance"
+	SEARCH_SORT_PRICE_ASC  = "price_asc"
+	SEARCH_SORT_PRICE_DESC = "price_desc"
+	SEARCH_SORT_NEWEST     = "newest"
+	SEARCH_SORT_TITLE      = "title"
+)
+
+const (
+	SEARCH_DEFAULT_PER_PAGE = 20
+	AUDIT_DEFAULT_PER_PAGE  = 50
+)
+
+const (
+	IMPORT_STATUS_QUEUED   = "queued"
+	IMPORT_STATUS_RUNNING  = "running"
+	IMPORT_STATUS_COMPLETE = "complete"
+	IMPORT_STATUS_FAILED   = "failed"
+)
+
+const (
+	// IMPORT_PROGRESS_INTERVAL is how many rows are imported between progress saves.
+	IMPORT_PROGRESS_INTERVAL = 50
+	IMPORT_LIST_SEPARATOR    = "|"
+)
+
+const (
+	VIEW_PRODUCT_PERMISSION   = "view_product"
+	CREATE_PRODUCT_PERMISSION = "create_product"
+	EDIT_PRODUCT_PERMISSION   = "edit_product"
+	DELETE_PRODUCT_PERMISSION = "delete_product"
+)
+
+const (
+	CREATE_CATEGORY_PERMISSION = "create_category"
+	EDIT_CATEGORY_PERMISSION   = "edit_category"
+	DELETE_CATEGORY_PERMISSION = "delete_category"
+)
+
+const (
+	VIEW_ORDER_PERMISSION      = "view_order"
+	VIEW_ANY_ORDER_PERMISSION  = "view_any_order"
+	EXPORT_ORDER_PERMISSION    = "export_order"
+	REFUND_ORDER_PERMISSION    = "refund_order"
+	MANAGE_DISCOUNT_PERMISSION = "manage_discount"
+	MANAGE_TAX_PERMISSION      = "manage_tax"
+	MANAGE_SHIPPING_PERMISSION = "manage_shipping"
+	VIEW_ANALYTICS_PERMISSION  = "view_analytics"
+	MODERATE_REVIEW_PERMISSION = "moderate_review"
+	VIEW_AUDIT_LOG_PERMISSION  = "view_audit_log"
+)
+
+const (
+	VIEW_WISHLIST_PERMISSION = "view_wishlist"
+	EDIT_WISHLIST_PERMISSION = "edit_wishlist"
+)
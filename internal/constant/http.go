@@ -19,6 +19,8 @@ const (
 	InternalServerErrorName = "Internal Server Error"
 	NotImplementedCode      = http.StatusNotImplemented
 	NotImplementedErrorName = "Not Implemented"
+	GatewayTimeoutCode      = http.StatusGatewayTimeout
+	GatewayTimeoutErrorName = "Request Timed Out"
 )
 
 const (
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type CSVWriter struct {
+	writer *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) TableWriter {
+	return &CSVWriter{writer: csv.NewWriter(w)}
+}
+
+func (c *CSVWriter) WriteHeader(columns []Column) error {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return c.WriteRow(names)
+}
+
+func (c *CSVWriter) WriteRow(values []string) error {
+	err := c.writer.Write(values)
+	if err != nil {
+		return err
+	}
+	// flush every row so the rows reach the client as they are read
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *CSVWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
@@ -0,0 +1,116 @@
+package export_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"tannar.moss/backend/internal/export"
+)
+
+var testColumns = []export.Column{{Name: "title"}, {Name: "total", Numeric: true}}
+
+func TestCSVWriter_withRows_shouldQuoteValues(t *testing.T) {
+	var output bytes.Buffer
+	writer := export.NewCSVWriter(&output)
+	if err := writer.WriteHeader(testColumns); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if err := writer.WriteRow([]string{"Mug, large", "29.99"}); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	expected := "title,total\n\"Mug, large\",29.99\n"
+	if output.String() != expected {
+		t.Errorf("Expected %q but got %q", expected, output.String())
+	}
+}
+
+func TestXLSXWriter_withRows_shouldWriteReadableWorkbook(t *testing.T) {
+	var output bytes.Buffer
+	writer := export.NewXLSXWriter(&output)
+	if err := writer.WriteHeader(testColumns); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if err := writer.WriteRow([]string{"Fish & Chips <large>", "29.99"}); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(output.Bytes()), int64(output.Len()))
+	if err != nil {
+		t.Fatalf("Expected a valid zip but got %v", err)
+	}
+
+	parts := map[string]string{}
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Expected to open %s but got %v", file.Name, err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		parts[file.Name] = string(content)
+	}
+
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("Expected workbook part %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, `<c r="A2" t="inlineStr"><is><t xml:space="preserve">Fish &amp; Chips &lt;large&gt;</t></is></c>`) {
+		t.Errorf("Expected escaped inline string cell in %s", sheet)
+	}
+	if !strings.Contains(sheet, `<c r="B2"><v>29.99</v></c>`) {
+		t.Errorf("Expected numeric cell in %s", sheet)
+	}
+	if !strings.HasSuffix(sheet, "</sheetData></worksheet>") {
+		t.Errorf("Expected the sheet to be closed but got %s", sheet)
+	}
+}
+
+func TestWritePDF_withManyLines_shouldSplitPages(t *testing.T) {
+	lines := make([]string, 120)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("Line (%d)", i)
+	}
+
+	var output bytes.Buffer
+	if err := export.WritePDF(&output, "Invoice", lines); err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	pdf := output.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Error("Expected a PDF header and trailer")
+	}
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Error("Expected 120 lines to span 3 pages")
+	}
+	if !strings.Contains(pdf, `(Line \(7\)) '`) {
+		t.Error("Expected parentheses to be escaped")
+	}
+
+	// every xref entry should point at the start of its object
+	xref := pdf[strings.Index(pdf, "xref\n"):]
+	entries := strings.Split(xref, "\n")[3:]
+	for i, entry := range entries {
+		if !strings.HasSuffix(entry, " n ") {
+			break
+		}
+		var offset int
+		fmt.Sscanf(entry, "%d", &offset)
+		if !strings.HasPrefix(pdf[offset:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("Expected xref entry %d to point at its object", i+1)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	pdfPageWidth  = 595
+	pdfPageHeight = 842
+	pdfMargin     = 50
+	pdfFontSize   = 10
+	pdfLeading    = 14
+)
+
+var pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+
+// WritePDF lays the lines out top to bottom in a fixed width font, starting a new A4
+// page whenever one fills up. It is deliberately minimal: one font, no images.
+func WritePDF(w io.Writer, title string, lines []string) error {
+	pages := make([][]string, 0, len(lines)/pdfLinesPerPage+1)
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var buffer bytes.Buffer
+	offsets := make([]int, 0, 3+2*len(pages))
+	writeObject := func(body string) {
+		offsets = append(offsets, buffer.Len())
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buffer.WriteString("%PDF-1.4\n")
+	writeObject("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	writeObject(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	writeObject("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			content.WriteString("(" + escapePDFText(line) + ") '\n")
+		}
+		content.WriteString("ET")
+
+		writeObject(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pdfPageWidth, pdfPageHeight, 5+2*i))
+		writeObject(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+	writeObject(fmt.Sprintf("<< /Title (%s) >>", escapePDFText(title)))
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, len(offsets), xref)
+
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+// escapePDFText escapes a string for a PDF literal string, replacing anything the
+// standard fonts cannot show.
+func escapePDFText(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 126:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
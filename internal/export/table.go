@@ -0,0 +1,29 @@
+package export
+
+// Column describes one column of a tabular export. Numeric columns are written as
+// numbers where the format supports it so spreadsheets can sum them.
+type Column struct {
+	Name    string
+	Numeric bool
+}
+
+// TableWriter writes rows one at a time so exports never hold the whole result in memory.
+type TableWriter interface {
+	WriteHeader(columns []Column) error
+	WriteRow(values []string) error
+	Close() error
+}
+
+const (
+	FORMAT_CSV  = "csv"
+	FORMAT_XLSX = "xlsx"
+)
+
+func ContentType(format string) string {
+	switch format {
+	case FORMAT_XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return "text/csv"
+	}
+}
@@ -0,0 +1,138 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Export" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+var errHeaderNotWritten = errors.New("export: header must be written before rows")
+
+// XLSXWriter streams a single sheet workbook. The fixed workbook parts are written
+// up front and the sheet is left open so rows are compressed out as they arrive.
+// Strings are stored inline so no shared string table has to be held in memory.
+type XLSXWriter struct {
+	archive *zip.Writer
+	sheet   io.Writer
+	columns []Column
+	row     int
+}
+
+func NewXLSXWriter(w io.Writer) TableWriter {
+	return &XLSXWriter{archive: zip.NewWriter(w)}
+}
+
+func (x *XLSXWriter) writePart(name string, content string) error {
+	part, err := x.archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(part, content)
+	return err
+}
+
+func (x *XLSXWriter) WriteHeader(columns []Column) error {
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		if err := x.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := x.archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(sheet, xlsxSheetStart); err != nil {
+		return err
+	}
+	x.sheet = sheet
+
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	// the header row is all text, the column types only apply to the data rows
+	err = x.writeCells(names, nil)
+	x.columns = columns
+	return err
+}
+
+func (x *XLSXWriter) WriteRow(values []string) error {
+	if x.sheet == nil {
+		return errHeaderNotWritten
+	}
+	return x.writeCells(values, x.columns)
+}
+
+func (x *XLSXWriter) writeCells(values []string, columns []Column) error {
+	x.row++
+	var row strings.Builder
+	row.WriteString(`<row r="` + strconv.Itoa(x.row) + `">`)
+	for i, value := range values {
+		ref := columnName(i) + strconv.Itoa(x.row)
+		numeric := i < len(columns) && columns[i].Numeric && value != ""
+		if numeric {
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				numeric = false
+			}
+		}
+		if numeric {
+			row.WriteString(`<c r="` + ref + `"><v>` + value + `</v></c>`)
+			continue
+		}
+		row.WriteString(`<c r="` + ref + `" t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(&row, []byte(value)); err != nil {
+			return err
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString(`</row>`)
+
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
+
+func (x *XLSXWriter) Close() error {
+	if x.sheet == nil {
+		if err := x.WriteHeader(nil); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(x.sheet, xlsxSheetEnd); err != nil {
+		return err
+	}
+	return x.archive.Close()
+}
+
+// columnName turns a zero based index into a spreadsheet column name, e.g. 0 is A and 27 is AB.
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
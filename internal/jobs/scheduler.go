@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+)
+
+// Schedule runs job straight away and then every interval in the background until
+// the returned stop function is called. Stop cancels the context of a run in progress
+// and waits for it to finish. A failing run is logged and retried on the next tick.
+func Schedule(name string, interval time.Duration, logger logger.Logger, job func(ctx context.Context) error) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	run := func() {
+		started := time.Now()
+		err := job(ctx)
+		if err != nil {
+			logger.Errorf("Job '%s' failed after %s: %s", name, time.Since(started), err.Error())
+			return
+		}
+		logger.Debugf("Job '%s' finished in %s", name, time.Since(started))
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		run()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				run()
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+		})
+	}
+}
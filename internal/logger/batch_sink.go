@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxPendingLines = 10000
+
+// Publisher ships a batch of encoded log lines to a log collector.
+type Publisher interface {
+	Publish(batch [][]byte) error
+}
+
+// BatchSink buffers lines until Flush, then publishes them batchSize at a time, the
+// way an SQS SendMessageBatch caps a request at ten messages. Lines past
+// maxPendingLines are dropped, and counted, rather than growing without bound.
+type BatchSink struct {
+	publisher Publisher
+	batchSize int
+
+	mu      sync.Mutex
+	pending [][]byte
+	dropped int
+}
+
+func NewBatchSink(publisher Publisher, batchSize int) *BatchSink {
+	if batchSize <= 0 {
+		batchSize = 10
+	}
+	return &BatchSink{publisher: publisher, batchSize: batchSize}
+}
+
+func (s *BatchSink) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) >= maxPendingLines {
+		s.dropped++
+		return nil
+	}
+	// the caller may reuse line once Write returns
+	s.pending = append(s.pending, append([]byte(nil), line...))
+	return nil
+}
+
+// Pending returns how many lines wait for the next Flush.
+func (s *BatchSink) Pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.pending)
+}
+
+// Discard drops the buffered lines without publishing them.
+func (s *BatchSink) Discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = nil
+	s.dropped = 0
+}
+
+// Flush publishes every buffered line. Batches that fail are not retried; the first
+// error is returned once the rest have been tried.
+func (s *BatchSink) Flush() error {
+	s.mu.Lock()
+	pending := s.pending
+	dropped := s.dropped
+	s.pending = nil
+	s.dropped = 0
+	s.mu.Unlock()
+
+	var firstErr error
+	if dropped > 0 {
+		firstErr = fmt.Errorf("dropped %d log lines, the publish buffer was full", dropped)
+	}
+	for start := 0; start < len(pending); start += s.batchSize {
+		end := start + s.batchSize
+		if end > len(pending) {
+			end = len(pending)
+		}
+		err := s.publisher.Publish(pending[start:end])
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (s *BatchSink) Close() error {
+	return s.Flush()
+}
+
+// HTTPPublisher posts each batch as newline delimited JSON to a collector endpoint,
+// such as a Sumo Logic HTTP source.
+type HTTPPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+func NewHTTPPublisher(endpoint string) *HTTPPublisher {
+	return &HTTPPublisher{endpoint: endpoint, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (p *HTTPPublisher) Publish(batch [][]byte) error {
+	response, err := p.client.Post(p.endpoint, "application/x-ndjson", bytes.NewReader(bytes.Join(batch, nil)))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("log collector at '%s' answered %s", p.endpoint, response.Status)
+	}
+	return nil
+}
+
+// LocalPublisher stands in for a collector when running locally, writing each batch
+// to w under a header line so batching can be seen working.
+type LocalPublisher struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewLocalPublisher(w io.Writer) *LocalPublisher {
+	return &LocalPublisher{w: w}
+}
+
+func (p *LocalPublisher) Publish(batch [][]byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- published batch of %d log lines\n", len(batch))
+	for _, line := range batch {
+		out.Write(line)
+	}
+	_, err := io.WriteString(p.w, out.String())
+	return err
+}
@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	sharedSinksOnce sync.Once
+	sharedSinks     []Sink
+)
+
+// defaultSinks opens the sinks named by LOG_SINKS once per process, so loggers
+// recreated by a recycled lambda keep writing to the same file: stdout (the default)
+// and file, which rotates LOG_FILE_PATH at LOG_FILE_MAX_MB keeping LOG_FILE_BACKUPS.
+func defaultSinks() []Sink {
+	sharedSinksOnce.Do(func() {
+		for _, name := range strings.Split(getenv("LOG_SINKS", "stdout"), ",") {
+			switch strings.TrimSpace(name) {
+			case "stdout":
+				sharedSinks = append(sharedSinks, NewWriterSink(os.Stdout))
+			case "file":
+				path := getenv("LOG_FILE_PATH", "logs/backend.log")
+				maxBytes := int64(getenvInt("LOG_FILE_MAX_MB", 100)) << 20
+				file, err := NewRotatingFileSink(path, maxBytes, getenvInt("LOG_FILE_BACKUPS", 5))
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Unabled to open log file '%s': %s\n", path, err.Error())
+					continue
+				}
+				sharedSinks = append(sharedSinks, file)
+			default:
+				fmt.Fprintf(os.Stderr, "Unknown log sink '%s'\n", name)
+			}
+		}
+		if len(sharedSinks) == 0 {
+			sharedSinks = append(sharedSinks, NewWriterSink(os.Stdout))
+		}
+	})
+	return sharedSinks
+}
+
+// defaultPublishSink buffers lines for PublishSumoLogs, posting them to
+// LOG_PUBLISH_ENDPOINT, or to stderr through the local stand-in when it is unset.
+func defaultPublishSink() *BatchSink {
+	batchSize := getenvInt("LOG_PUBLISH_BATCH_SIZE", 10)
+	if endpoint := os.Getenv("LOG_PUBLISH_ENDPOINT"); endpoint != "" {
+		return NewBatchSink(NewHTTPPublisher(endpoint), batchSize)
+	}
+	return NewBatchSink(NewLocalPublisher(os.Stderr), batchSize)
+}
+
+// defaultSampler lets LOG_SAMPLE_FIRST DEBUG and TRACE lines per call site through
+// each second, then every LOG_SAMPLE_THEREAFTER-th.
+func defaultSampler() *Sampler {
+	return NewSampler(getenvInt("LOG_SAMPLE_FIRST", 100), getenvInt("LOG_SAMPLE_THEREAFTER", 100), time.Second)
+}
+
+// getenv is utils.Getenv, which this package cannot import.
+func getenv(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func getenvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"context"
+)
+
+type contextKey struct{}
+
+// NewContext returns a context carrying logger, normally one derived for a request.
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, logger)
+}
+
+// FromContext returns the logger carried by ctx, or fallback outside a request.
+func FromContext(ctx context.Context, fallback Logger) Logger {
+	if logger, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return logger
+	}
+	return fallback
+}
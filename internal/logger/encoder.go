@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// Entry is one log line before encoding.
+type Entry struct {
+	Time    time.Time
+	Level   string
+	TraceID string
+	Source  string
+	Message string
+	Fields  []Field
+	// Args holds untyped arguments, written under "args".
+	Args []interface{}
+}
+
+// EncodeJSON writes entry as a single line of JSON, with time, level, trace_id,
+// source and message first and the fields after in the order given. Sensitive values
+// are redacted on the way.
+func EncodeJSON(entry Entry) []byte {
+	var line bytes.Buffer
+	line.WriteByte('{')
+	writeProperty(&line, "time", entry.Time.Format("2006-01-02T15:04:05.000Z07:00"), true)
+	writeProperty(&line, "level", entry.Level, false)
+	writeProperty(&line, "trace_id", entry.TraceID, false)
+	if entry.Source != "" {
+		writeProperty(&line, "source", entry.Source, false)
+	}
+	writeProperty(&line, "message", RedactString(entry.Message), false)
+
+	for _, field := range entry.Fields {
+		if isSensitiveKey(field.Key) {
+			writeProperty(&line, field.Key, redacted, false)
+			continue
+		}
+		writeProperty(&line, field.Key, Redact(field.Value), false)
+	}
+	switch len(entry.Args) {
+	case 0:
+	case 1:
+		writeProperty(&line, "args", Redact(entry.Args[0]), false)
+	default:
+		writeProperty(&line, "args", Redact(entry.Args), false)
+	}
+
+	line.WriteString("}\n")
+	return line.Bytes()
+}
+
+func writeProperty(line *bytes.Buffer, key string, value any, first bool) {
+	if !first {
+		line.WriteByte(',')
+	}
+	encodedKey, _ := json.Marshal(key)
+	line.Write(encodedKey)
+	line.WriteByte(':')
+	encodedValue, err := json.Marshal(value)
+	if err != nil {
+		encodedValue, _ = json.Marshal(redacted)
+	}
+	line.Write(encodedValue)
+}
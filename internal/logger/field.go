@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"time"
+)
+
+// Field is a typed key and value written as its own JSON property. Pass fields as
+// the args of any log call, e.g. Info("Order placed", String("order", id)).
+type Field struct {
+	Key   string
+	Value any
+}
+
+func String(key string, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: int64(value)}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration records d in milliseconds.
+func Duration(key string, d time.Duration) Field {
+	return Field{Key: key, Value: float64(d.Microseconds()) / 1000}
+}
+
+func Time(key string, t time.Time) Field {
+	return Field{Key: key, Value: t.Format(time.RFC3339Nano)}
+}
+
+// Err records err's message under "error", or nothing useful when err is nil.
+func Err(err error) Field {
+	if err == nil {
+		return Field{Key: "error", Value: nil}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+// Any records value as JSON, redacted like every other value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// splitArgs separates fields from the untyped args older calls pass, which are kept
+// under "args".
+func splitArgs(args []interface{}) ([]Field, []interface{}) {
+	var fields []Field
+	var rest []interface{}
+	for _, arg := range args {
+		switch typed := arg.(type) {
+		case Field:
+			fields = append(fields, typed)
+		case []Field:
+			fields = append(fields, typed...)
+		case nil:
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	return fields, rest
+}
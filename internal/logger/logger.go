@@ -1,10 +1,8 @@
 package logger
 
 import (
-	"encoding/json"
 	"fmt"
-	"log"
-	"reflect"
+	"os"
 	"runtime"
 	"strings"
 	"time"
@@ -29,18 +27,17 @@ type Logger interface {
 	Debugf(message string, args ...interface{})
 	Trace(message string, args ...interface{})
 	SetTraceId(traceId string)
+	Derive(traceId string, fields ...Field) Logger
 	PublishSumoLogs()
 	Refresh(logLevel string, pushToSumo bool)
 }
 
 const (
-	TRACE         = "TRACE"
-	DEBUG         = "DEBUG"
-	INFO          = "INFO"
-	WARN          = "WARN"
-	ERROR         = "ERROR"
-	logFormat     = "%s [%s] [%s] (%s) %s\n"
-	logInfoFormat = "%s [%s] [%s] %s\n"
+	TRACE = "TRACE"
+	DEBUG = "DEBUG"
+	INFO  = "INFO"
+	WARN  = "WARN"
+	ERROR = "ERROR"
 )
 
 func Trace(traceId string, message string, args interface{}) {
@@ -63,34 +60,28 @@ func Error(traceId string, message string, args interface{}) {
 	logIt(ERROR, traceId, message, args)
 }
 
-func logItf(level string, requestID string, format string, a ...interface{}) {
-	msg := fmt.Sprintf(format, a[:len(a)-1]...)
-	logIt(level, requestID, msg, a[len(a)-1])
-}
-
 func logIt(level string, requestID string, message string, args ...interface{}) {
-	src := src()
-	now := now()
-
-	var argList = make([]interface{}, 0)
-
-	argList = append(argList, message)
-	if args != nil && len(args) > 0 {
-		argList = append(argList, jsonIt(args[0]))
+	fields, rest := splitArgs(args)
+	entry := Entry{Time: time.Now(), Level: level, TraceID: requestID, Message: message, Fields: fields, Args: rest}
+	if level != INFO {
+		entry.Source = caller()
 	}
+	writeLine(defaultSinks(), EncodeJSON(entry))
+}
 
-	var msg = fmt.Sprint(argList...)
-
-	if level == INFO {
-		log.Printf(logInfoFormat, now, level, requestID, msg)
-	} else {
-		log.Printf(logFormat, now, level, requestID, src, msg)
+func writeLine(sinks []Sink, line []byte) {
+	for _, sink := range sinks {
+		err := sink.Write(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write log line: %s\n", err.Error())
+		}
 	}
 }
 
-func src() string {
-	// Determine caller func
-	pc, _, lineno, ok := runtime.Caller(4)
+// caller names the function that called the logger, three frames up: past caller
+// itself, the internal log function and the exported method.
+func caller() string {
+	pc, _, lineno, ok := runtime.Caller(3)
 	src := ""
 	if ok {
 		slice := strings.Split(runtime.FuncForPC(pc).Name(), "/")
@@ -99,30 +90,3 @@ func src() string {
 	}
 	return src
 }
-
-func now() string {
-	return time.Now().Format("2006-01-02T15:04:05-0700")
-}
-
-func jsonIt(a interface{}) string {
-	dataType := indirectType(reflect.TypeOf(a))
-	switch dataType.Kind() {
-	case reflect.String, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool,
-		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
-		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
-		return fmt.Sprintf("%v", a)
-	}
-	data, _ := json.Marshal(a)
-	return string(data)
-}
-
-func indirectType(reflectType reflect.Type) reflect.Type {
-	for isPtrType(reflectType) || reflectType.Kind() == reflect.Slice {
-		reflectType = reflectType.Elem()
-	}
-	return reflectType
-}
-
-func isPtrType(t reflect.Type) bool {
-	return t.Kind() == reflect.Ptr
-}
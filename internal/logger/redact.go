@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are matched as substrings of lowercased keys, so hashed_password,
+// Authorization and refresh_token are all caught.
+var sensitiveKeys = []string{"password", "secret", "token", "authorization", "jwt", "cookie"}
+
+// jwtPattern matches a signed JWT anywhere in a string: three base64url segments, the
+// first always starting with the encoded '{"'.
+var jwtPattern = regexp.MustCompile(`eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+
+// bcryptPattern matches a bcrypt hash, as the user queries log at debug.
+var bcryptPattern = regexp.MustCompile(`\$2[abxy]?\$\d{2}\$[./A-Za-z0-9]{53}`)
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString hides any JWT or bcrypt hash in value.
+func RedactString(value string) string {
+	value = jwtPattern.ReplaceAllString(value, redacted)
+	return bcryptPattern.ReplaceAllString(value, redacted)
+}
+
+// Redact returns value as plain JSON data with the values of sensitive keys, at any
+// depth, and JWTs and hashes inside strings replaced. Structs are read through their
+// json tags.
+func Redact(value any) any {
+	switch typed := value.(type) {
+	case nil, bool, int, int64, uint64, float64:
+		return typed
+	case string:
+		return RedactString(typed)
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return redacted
+	}
+	var plain any
+	err = json.Unmarshal(data, &plain)
+	if err != nil {
+		return redacted
+	}
+	return redactPlain(plain)
+}
+
+func redactPlain(value any) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, nested := range typed {
+			if isSensitiveKey(key) {
+				typed[key] = redacted
+			} else {
+				typed[key] = redactPlain(nested)
+			}
+		}
+		return typed
+	case []any:
+		for i, nested := range typed {
+			typed[i] = redactPlain(nested)
+		}
+		return typed
+	case string:
+		return RedactString(typed)
+	default:
+		return typed
+	}
+}
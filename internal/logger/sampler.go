@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampler thins out DEBUG and TRACE lines from busy call sites: in each tick it lets
+// the first lines from a call site through, then only every thereafter-th one. Other
+// levels are never sampled.
+type Sampler struct {
+	first      int
+	thereafter int
+	tick       time.Duration
+
+	mu     sync.Mutex
+	window time.Time
+	counts map[string]int
+}
+
+// NewSampler samples per tick; a thereafter of 0 drops every line past the first.
+func NewSampler(first int, thereafter int, tick time.Duration) *Sampler {
+	return &Sampler{
+		first:      first,
+		thereafter: thereafter,
+		tick:       tick,
+		counts:     map[string]int{},
+	}
+}
+
+// Sample reports whether a line at level from source should be written.
+func (s *Sampler) Sample(level string, source string, now time.Time) bool {
+	if s == nil || (level != DEBUG && level != TRACE) {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.window) >= s.tick {
+		s.window = now
+		s.counts = map[string]int{}
+	}
+	key := level + " " + source
+	s.counts[key]++
+	seen := s.counts[key]
+	if seen <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (seen-s.first)%s.thereafter == 0
+}
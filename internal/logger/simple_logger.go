@@ -2,32 +2,104 @@ package logger
 
 import (
 	"fmt"
+	"os"
+	"sync"
 	"time"
 )
 
+// SimpleLogger writes through settings it shares with every logger derived from it,
+// so one Refresh or PublishSumoLogs covers them all, while each keeps its own trace id
+// and fields. It is safe for concurrent use.
 type SimpleLogger struct {
-	traceId     string
-	logLevel    string
-	logMessages []map[string]interface{}
-	pushToSumo  bool
+	settings *settings
+
+	mu      sync.RWMutex
+	traceId string
+	fields  []Field
+}
+
+type settings struct {
+	mu         sync.RWMutex
+	logLevel   string
+	pushToSumo bool
+	sinks      []Sink
+	// publishing buffers lines for PublishSumoLogs while pushToSumo is set
+	publishing *BatchSink
+	sampler    *Sampler
+}
+
+// Option changes where and how a SimpleLogger writes.
+type Option func(*SimpleLogger)
+
+// WithSinks replaces the sinks configured by LOG_SINKS.
+func WithSinks(sinks ...Sink) Option {
+	return func(sl *SimpleLogger) { sl.settings.sinks = sinks }
+}
+
+// WithPublishSink replaces the sink PublishSumoLogs flushes.
+func WithPublishSink(sink *BatchSink) Option {
+	return func(sl *SimpleLogger) { sl.settings.publishing = sink }
 }
 
-func NewSimpleLogger(logLevel string, pushToSumo bool) *SimpleLogger {
-	return &SimpleLogger{
-		traceId:    "ROOT",
-		logLevel:   logLevel,
-		pushToSumo: pushToSumo,
+// WithSampler replaces the DEBUG and TRACE sampling configured by LOG_SAMPLE_FIRST and
+// LOG_SAMPLE_THEREAFTER; a nil sampler writes every line.
+func WithSampler(sampler *Sampler) Option {
+	return func(sl *SimpleLogger) { sl.settings.sampler = sampler }
+}
+
+// NewSimpleLogger writes JSON lines at logLevel and above to the LOG_SINKS sinks and,
+// when pushToSumo is set, buffers them for PublishSumoLogs.
+func NewSimpleLogger(logLevel string, pushToSumo bool, options ...Option) *SimpleLogger {
+	sl := &SimpleLogger{
+		traceId: "ROOT",
+		settings: &settings{
+			logLevel:   logLevel,
+			pushToSumo: pushToSumo,
+			sinks:      defaultSinks(),
+			sampler:    defaultSampler(),
+		},
+	}
+	for _, option := range options {
+		option(sl)
+	}
+	if pushToSumo && sl.settings.publishing == nil {
+		sl.settings.publishing = defaultPublishSink()
 	}
+	return sl
+}
+
+// Derive returns a logger for one request, writing with traceId, or this logger's
+// trace id when empty, and fields added to this logger's own.
+func (sl *SimpleLogger) Derive(traceId string, fields ...Field) Logger {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
+	if traceId == "" {
+		traceId = sl.traceId
+	}
+	derivedFields := make([]Field, 0, len(sl.fields)+len(fields))
+	derivedFields = append(derivedFields, sl.fields...)
+	derivedFields = append(derivedFields, fields...)
+	return &SimpleLogger{settings: sl.settings, traceId: traceId, fields: derivedFields}
 }
 
 func (sl *SimpleLogger) SetTraceId(traceId string) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
 	sl.traceId = traceId
 }
 
+// Refresh changes the level and publishing of this logger and every logger derived
+// from it, discarding lines not yet published.
 func (sl *SimpleLogger) Refresh(logLevel string, pushToSumo bool) {
-	sl.logLevel = logLevel
-	sl.pushToSumo = pushToSumo
-	sl.logMessages = nil
+	sl.settings.mu.Lock()
+	defer sl.settings.mu.Unlock()
+	sl.settings.logLevel = logLevel
+	sl.settings.pushToSumo = pushToSumo
+	if sl.settings.publishing != nil {
+		sl.settings.publishing.Discard()
+	} else if pushToSumo {
+		sl.settings.publishing = defaultPublishSink()
+	}
 }
 
 func (sl *SimpleLogger) Error(message string, args ...interface{}) {
@@ -72,87 +144,72 @@ func (sl *SimpleLogger) Log(traceId string, level string, message string, args .
 }
 
 func (sl *SimpleLogger) LogLevel() string {
-	return sl.logLevel
-}
-
-func (sl *SimpleLogger) LogMessages() []map[string]interface{} {
-	return sl.logMessages
+	sl.settings.mu.RLock()
+	defer sl.settings.mu.RUnlock()
+	return sl.settings.logLevel
 }
 
 func (sl *SimpleLogger) RequestId() string {
+	sl.mu.RLock()
+	defer sl.mu.RUnlock()
 	return sl.traceId
 }
 
 func (sl *SimpleLogger) log(level string, message string, args ...interface{}) {
-	if LogLevel[level] >= LogLevel[sl.logLevel] {
-		logIt(level, sl.traceId, message, args...)
-		if sl.pushToSumo {
-			sl.appendMessageToList(level, message, args...)
-		}
+	sl.settings.mu.RLock()
+	defer sl.settings.mu.RUnlock()
+	if LogLevel[level] < LogLevel[sl.settings.logLevel] {
+		return
+	}
+	now := time.Now()
+	source := caller()
+	if !sl.settings.sampler.Sample(level, source, now) {
+		return
 	}
-}
-
-func (sl *SimpleLogger) appendMessageToList(level string, message string, args ...interface{}) {
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
+	sl.mu.RLock()
+	traceId := sl.traceId
+	fields := sl.fields
+	sl.mu.RUnlock()
+	callFields, rest := splitArgs(args)
+	if len(callFields) > 0 {
+		fields = append(append([]Field(nil), fields...), callFields...)
+	}
 
-	var payload = make(map[string]interface{})
-	if LogLevel[level] == 2 || LogLevel[level] == 3 { //Dont add source for INFO & WARN log levels
-		payload[level] = fmt.Sprintf("[%s] [%s] - %s", timestamp, sl.traceId, message)
-	} else {
-		payload[level] = fmt.Sprintf("[%s] [%s] (%s) - %s", timestamp, sl.traceId, src(), message)
+	entry := Entry{Time: now, Level: level, TraceID: traceId, Message: message, Fields: fields, Args: rest}
+	if level != INFO && level != WARN { //Dont add source for INFO & WARN log levels
+		entry.Source = source
 	}
+	line := EncodeJSON(entry)
 
-	if args != nil && len(args) > 0 {
-		payload["msg-args"] = args[0]
+	writeLine(sl.settings.sinks, line)
+	if sl.settings.pushToSumo && sl.settings.publishing != nil {
+		sl.settings.publishing.Write(line)
 	}
-	sl.logMessages = append(sl.logMessages, payload)
 }
 
+// PublishSumoLogs publishes the lines buffered since the last call, by this logger or
+// any logger sharing its settings, and flushes the other sinks. Failures go to stderr,
+// since logging them could recurse.
 func (sl *SimpleLogger) PublishSumoLogs() {
-
-	// if sl.pushToSumo && sl.sqsProducer != nil {
-
-	// 	batchSize, err := strconv.Atoi(sl.config.BatchSize)
-	// 	if err != nil {
-	// 		Warn(sl.traceId, "Invalid sumo config batch-size value: ", err.Error())
-	// 		batchSize = 15
-	// 	}
-
-	// 	var batchCounter = 0
-	// 	var logBatch = make([]types.SendMessageBatchRequestEntry, 0)
-	// 	for i := 0; i < len(sl.logMessages); i++ {
-
-	// 		messageId := uuid.NewString()
-	// 		messageBody := jsonIt(map[string]interface{}{
-	// 			"host":        sl.config.OnboardHost,
-	// 			"category":    sl.config.Category,
-	// 			"sumoPayload": sl.logMessages[i],
-	// 		})
-
-	// 		logBatch = append(logBatch, types.SendMessageBatchRequestEntry{
-	// 			Id:          &messageId,
-	// 			MessageBody: &messageBody,
-	// 		})
-	// 		batchCounter++
-
-	// 		if batchCounter == batchSize || i == len(sl.logMessages)-1 {
-
-	// 			Debug(sl.traceId, "Publishing log batch to sumo: ", logBatch)
-	// 			err := sl.sqsProducer.SendMessageBatch(&logBatch)
-	// 			if err != nil {
-	// 				Error(sl.traceId, "Failed to publish sumo log batch: ", err.Error())
-	// 			}
-	// 			logBatch = make([]types.SendMessageBatchRequestEntry, 0)
-	// 			batchCounter = 0
-	// 		}
-	// 	}
-	// }
-	sl.logMessages = nil
+	sl.settings.mu.RLock()
+	defer sl.settings.mu.RUnlock()
+	if sl.settings.publishing != nil {
+		err := sl.settings.publishing.Flush()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to publish log batch: %s\n", err.Error())
+		}
+	}
+	for _, sink := range sl.settings.sinks {
+		err := sink.Flush()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to flush log sink: %s\n", err.Error())
+		}
+	}
 }
 
 func (sl *SimpleLogger) SetRequestId(traceId string) {
-	sl.traceId = traceId
+	sl.SetTraceId(traceId)
 }
 
 func (sl *SimpleLogger) setTimeZoneLocation(timeZoneLocation string) {
@@ -164,7 +221,7 @@ func (sl *SimpleLogger) setTimeZoneLocation(timeZoneLocation string) {
 
 	loc, err := time.LoadLocation(location)
 	if err != nil {
-		Warn(sl.traceId, fmt.Sprintf("Failed to load timezone location '%s': ", location), err.Error())
+		Warn(sl.RequestId(), fmt.Sprintf("Failed to load timezone location '%s': ", location), err.Error())
 		return
 	}
 	time.Local = loc
@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Sink receives encoded log lines. Sinks are shared by every logger writing to them,
+// so they must be safe for concurrent use.
+type Sink interface {
+	Write(line []byte) error
+	// Flush pushes out anything the sink buffers.
+	Flush() error
+	Close() error
+}
+
+// WriterSink writes each line straight to a writer such as stdout.
+type WriterSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{w: w}
+}
+
+func (s *WriterSink) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err := s.w.Write(line)
+	return err
+}
+
+func (s *WriterSink) Flush() error {
+	return nil
+}
+
+func (s *WriterSink) Close() error {
+	return nil
+}
+
+// RotatingFileSink appends to a file and, once it would grow past maxBytes, renames it
+// to path.1, shifting older files up to path.<maxBackups> and removing the oldest.
+type RotatingFileSink struct {
+	path       string
+	maxBytes   int64
+	maxBackups int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+func NewRotatingFileSink(path string, maxBytes int64, maxBackups int) (*RotatingFileSink, error) {
+	sink := &RotatingFileSink{path: path, maxBytes: maxBytes, maxBackups: maxBackups}
+	err := os.MkdirAll(filepath.Dir(path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+	err = sink.open()
+	if err != nil {
+		return nil, err
+	}
+	return sink, nil
+}
+
+func (s *RotatingFileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+func (s *RotatingFileSink) Write(line []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return fmt.Errorf("log file '%s' is closed", s.path)
+	}
+	if s.size > 0 && s.size+int64(len(line)) > s.maxBytes {
+		err := s.rotate()
+		if err != nil {
+			return err
+		}
+	}
+	written, err := s.file.Write(line)
+	s.size += int64(written)
+	return err
+}
+
+func (s *RotatingFileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return err
+	}
+
+	os.Remove(fmt.Sprintf("%s.%d", s.path, s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", s.path, i), fmt.Sprintf("%s.%d", s.path, i+1))
+	}
+	if s.maxBackups > 0 {
+		err = os.Rename(s.path, s.path+".1")
+	} else {
+		err = os.Remove(s.path)
+	}
+	if err != nil {
+		return err
+	}
+	return s.open()
+}
+
+func (s *RotatingFileSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Sync()
+}
+
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"bufio"
+	"sync"
+)
+
+// Counter is a value that only goes up, kept per combination of label values.
+type Counter struct {
+	name       string
+	help       string
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc adds one to the counter for labelValues, given in the order of its label names.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds delta, which must not be negative, to the counter for labelValues.
+func (c *Counter) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	key := labelKey(c.labelNames, labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: append([]string(nil), labelValues...)}
+		c.values[key] = value
+	}
+	value.value += delta
+}
+
+// Value returns the counter for labelValues.
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := labelKey(c.labelNames, labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value, ok := c.values[key]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (c *Counter) describe() (string, string, string) {
+	return c.name, c.help, counterType
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		writeSample(w, c.name, c.labelNames, value.labelValues, "", "", value.value)
+	}
+}
+
+func (c *Counter) snapshot() counterSnapshot {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make(map[string]float64, len(c.values))
+	for key, value := range c.values {
+		values[key] = value.value
+	}
+	return counterSnapshot{labelNames: c.labelNames, values: values}
+}
+
+// funcMetric is a counter or gauge read from a CollectFunc at scrape time.
+type funcMetric struct {
+	name       string
+	help       string
+	metricType string
+	labelNames []string
+	collect    CollectFunc
+}
+
+func (f *funcMetric) describe() (string, string, string) {
+	return f.name, f.help, f.metricType
+}
+
+func (f *funcMetric) write(w *bufio.Writer) {
+	for _, sample := range f.collect() {
+		labelKey(f.labelNames, sample.LabelValues)
+		writeSample(w, f.name, f.labelNames, sample.LabelValues, "", "", sample.Value)
+	}
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EMFWriter writes how much each counter grew since the last Flush as CloudWatch
+// embedded metric format documents, one JSON object per line. CloudWatch Logs turns
+// them into metrics when a lambda prints them, so no agent or API call is needed.
+// Label names become dimensions next to the writer's own.
+type EMFWriter struct {
+	registry   *Registry
+	namespace  string
+	dimensions map[string]string
+
+	mu   sync.Mutex
+	last map[string]map[string]float64
+}
+
+func NewEMFWriter(registry *Registry, namespace string, dimensions map[string]string) *EMFWriter {
+	return &EMFWriter{
+		registry:   registry,
+		namespace:  namespace,
+		dimensions: dimensions,
+		last:       map[string]map[string]float64{},
+	}
+}
+
+type emfMetric struct {
+	Name string `json:"Name"`
+	Unit string `json:"Unit"`
+}
+
+type emfDirective struct {
+	Namespace  string      `json:"Namespace"`
+	Dimensions [][]string  `json:"Dimensions"`
+	Metrics    []emfMetric `json:"Metrics"`
+}
+
+type emfMetadata struct {
+	Timestamp         int64          `json:"Timestamp"`
+	CloudWatchMetrics []emfDirective `json:"CloudWatchMetrics"`
+}
+
+// Flush writes a document for every counter sample that grew since the last Flush.
+func (e *EMFWriter) Flush(w io.Writer, now time.Time) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	snapshots := e.registry.counters()
+	encoder := json.NewEncoder(w)
+	for _, name := range sortedKeys(snapshots) {
+		snapshot := snapshots[name]
+		last, ok := e.last[name]
+		if !ok {
+			last = map[string]float64{}
+			e.last[name] = last
+		}
+
+		for _, key := range sortedKeys(snapshot.values) {
+			delta := snapshot.values[key] - last[key]
+			last[key] = snapshot.values[key]
+			if delta <= 0 {
+				continue
+			}
+
+			err := encoder.Encode(e.document(name, snapshot.labelNames, key, delta, now))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (e *EMFWriter) document(name string, labelNames []string, key string, value float64, now time.Time) map[string]any {
+	document := map[string]any{}
+	dimensions := make([]string, 0, len(e.dimensions)+len(labelNames))
+	for dimension, dimensionValue := range e.dimensions {
+		document[dimension] = dimensionValue
+		dimensions = append(dimensions, dimension)
+	}
+	sort.Strings(dimensions)
+	if len(labelNames) > 0 {
+		for i, labelValue := range strings.Split(key, labelSeparator) {
+			document[labelNames[i]] = labelValue
+		}
+		dimensions = append(dimensions, labelNames...)
+	}
+
+	document[name] = value
+	document["_aws"] = emfMetadata{
+		Timestamp: now.UnixMilli(),
+		CloudWatchMetrics: []emfDirective{{
+			Namespace:  e.namespace,
+			Dimensions: [][]string{dimensions},
+			Metrics:    []emfMetric{{Name: name, Unit: "Count"}},
+		}},
+	}
+	return document
+}
@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bufio"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultBuckets are latency bounds in seconds, from 5ms to 10s.
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Histogram counts observations into cumulative buckets, kept per combination of
+// label values.
+type Histogram struct {
+	name       string
+	help       string
+	buckets    []float64
+	labelNames []string
+
+	mu     sync.Mutex
+	values map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// Observe records value for labelValues, given in the order of its label names.
+func (h *Histogram) Observe(value float64, labelValues ...string) {
+	key := labelKey(h.labelNames, labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	entry, ok := h.values[key]
+	if !ok {
+		entry = &histogramValue{labelValues: append([]string(nil), labelValues...), counts: make([]uint64, len(h.buckets))}
+		h.values[key] = entry
+	}
+	for i, bound := range h.buckets {
+		if value <= bound {
+			entry.counts[i]++
+		}
+	}
+	entry.count++
+	entry.sum += value
+}
+
+// ObserveSince records the seconds elapsed since started.
+func (h *Histogram) ObserveSince(started time.Time, labelValues ...string) {
+	h.Observe(time.Since(started).Seconds(), labelValues...)
+}
+
+func (h *Histogram) describe() (string, string, string) {
+	return h.name, h.help, histogramType
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.values) {
+		entry := h.values[key]
+		for i, bound := range h.buckets {
+			writeSample(w, h.name+"_bucket", h.labelNames, entry.labelValues, "le", formatValue(bound), float64(entry.counts[i]))
+		}
+		writeSample(w, h.name+"_bucket", h.labelNames, entry.labelValues, "le", formatValue(math.Inf(1)), float64(entry.count))
+		writeSample(w, h.name+"_sum", h.labelNames, entry.labelValues, "", "", entry.sum)
+		writeSample(w, h.name+"_count", h.labelNames, entry.labelValues, "", "", float64(entry.count))
+	}
+}
@@ -0,0 +1,29 @@
+package metrics
+
+// Default is the registry served on /metrics and flushed as EMF by the lambdas.
+var Default = NewRegistry()
+
+// HTTP
+var (
+	HTTPRequests        = Default.NewCounter("http_requests_total", "Requests served, by method, route and status code.", "method", "route", "status")
+	HTTPRequestDuration = Default.NewHistogram("http_request_duration_seconds", "Time spent serving requests, by method and route.", DefaultBuckets, "method", "route")
+)
+
+// Database
+var (
+	DBQueryDuration = Default.NewHistogram("db_query_duration_seconds", "Time spent running queries, by the query name passed to flows.", DefaultBuckets, "query")
+	DBQueryErrors   = Default.NewCounter("db_query_errors_total", "Queries that failed, by query name and whether they timed out.", "query", "kind")
+)
+
+// Business events
+var (
+	Registrations = Default.NewCounter("user_registrations_total", "Users registered.")
+	Logins        = Default.NewCounter("user_logins_total", "Successful logins.")
+	FailedLogins  = Default.NewCounter("user_failed_logins_total", "Logins refused for an unknown user or a wrong password.")
+	OrdersPlaced  = Default.NewCounter("orders_placed_total", "Orders placed.")
+)
+
+const (
+	QueryErrorTimeout = "timeout"
+	QueryErrorFailed  = "error"
+)
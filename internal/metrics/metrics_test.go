@@ -0,0 +1,86 @@
+package metrics_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/metrics"
+)
+
+func TestWritePrometheus_withCounterAndHistogram_shouldWriteExpositionFormat(t *testing.T) {
+	registry := metrics.NewRegistry()
+	requests := registry.NewCounter("requests_total", "Requests served.", "route", "status")
+	duration := registry.NewHistogram("duration_seconds", "Time spent.", []float64{0.1, 1}, "route")
+	registry.NewGaugeFunc("pool_open", "Open connections.", func() []metrics.Sample {
+		return []metrics.Sample{{LabelValues: []string{"writer"}, Value: 3}}
+	}, "pool")
+
+	requests.Inc("/api/order/:id", "200")
+	requests.Add(2, "/api/order/:id", "200")
+	requests.Inc(`/say "hi"`, "404")
+	duration.Observe(0.05, "/api/order/:id")
+	duration.Observe(0.5, "/api/order/:id")
+	duration.Observe(5, "/api/order/:id")
+
+	var out bytes.Buffer
+	err := registry.WritePrometheus(&out)
+	if err != nil {
+		t.Fatalf("WritePrometheus failed: %v", err)
+	}
+
+	expected := `# HELP duration_seconds Time spent.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{route="/api/order/:id",le="0.1"} 1
+duration_seconds_bucket{route="/api/order/:id",le="1"} 2
+duration_seconds_bucket{route="/api/order/:id",le="+Inf"} 3
+duration_seconds_sum{route="/api/order/:id"} 5.55
+duration_seconds_count{route="/api/order/:id"} 3
+# HELP pool_open Open connections.
+# TYPE pool_open gauge
+pool_open{pool="writer"} 3
+# HELP requests_total Requests served.
+# TYPE requests_total counter
+requests_total{route="/api/order/:id",status="200"} 3
+requests_total{route="/say \"hi\"",status="404"} 1
+`
+	if out.String() != expected {
+		t.Errorf("WritePrometheus test failed, expected[\n%s], got[\n%s]", expected, out.String())
+	}
+}
+
+func TestEMFWriter_withCounters_shouldFlushOnlyIncreases(t *testing.T) {
+	registry := metrics.NewRegistry()
+	logins := registry.NewCounter("logins_total", "Logins.")
+	requests := registry.NewCounter("requests_total", "Requests.", "status")
+	emf := metrics.NewEMFWriter(registry, "Backend", map[string]string{"function": "public"})
+
+	logins.Inc()
+	requests.Add(2, "200")
+	var first bytes.Buffer
+	emf.Flush(&first, time.UnixMilli(1000))
+	lines := strings.Split(strings.TrimSpace(first.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Flush test failed, expected[2] documents, got[%d]: %s", len(lines), first.String())
+	}
+
+	var document map[string]any
+	json.Unmarshal([]byte(lines[1]), &document)
+	if document["requests_total"] != 2.0 || document["status"] != "200" || document["function"] != "public" {
+		t.Errorf("Flush test failed, expected requests_total 2 with its dimensions, got[%s]", lines[1])
+	}
+	directive := document["_aws"].(map[string]any)["CloudWatchMetrics"].([]any)[0].(map[string]any)
+	dimensions, _ := json.Marshal(directive["Dimensions"])
+	if string(dimensions) != `[["function","status"]]` || directive["Namespace"] != "Backend" {
+		t.Errorf("Flush test failed, expected namespace Backend and dimensions function and status, got[%v]", directive)
+	}
+
+	requests.Inc("200")
+	var second bytes.Buffer
+	emf.Flush(&second, time.UnixMilli(2000))
+	if strings.Count(second.String(), "\n") != 1 || !strings.Contains(second.String(), `"requests_total":1`) {
+		t.Errorf("Flush test failed, expected only the increase of requests_total, got[%s]", second.String())
+	}
+}
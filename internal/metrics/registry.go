@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	counterType   = "counter"
+	gaugeType     = "gauge"
+	histogramType = "histogram"
+)
+
+// labelSeparator joins label values into map keys; it cannot appear in UTF-8 text.
+const labelSeparator = "\xff"
+
+// Sample is one labelled value reported by a CollectFunc.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// CollectFunc reports the current values of a metric read from elsewhere at scrape
+// time, such as connection pool stats.
+type CollectFunc func() []Sample
+
+type metric interface {
+	describe() (name string, help string, metricType string)
+	write(w *bufio.Writer)
+}
+
+// Registry holds metrics and writes them in the Prometheus text exposition format.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]metric
+}
+
+func NewRegistry() *Registry {
+	return &Registry{metrics: map[string]metric{}}
+}
+
+func (r *Registry) register(m metric) {
+	name, _, _ := m.describe()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.metrics[name]; ok {
+		panic(fmt.Sprintf("metric '%s' is already registered", name))
+	}
+	r.metrics[name] = m
+}
+
+// NewCounter registers a counter with the given label names.
+func (r *Registry) NewCounter(name string, help string, labelNames ...string) *Counter {
+	c := &Counter{name: name, help: help, labelNames: labelNames, values: map[string]*counterValue{}}
+	r.register(c)
+	return c
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds, in
+// ascending order, and label names.
+func (r *Registry) NewHistogram(name string, help string, buckets []float64, labelNames ...string) *Histogram {
+	h := &Histogram{name: name, help: help, buckets: buckets, labelNames: labelNames, values: map[string]*histogramValue{}}
+	r.register(h)
+	return h
+}
+
+// NewCounterFunc registers a counter whose samples are read from collect at scrape.
+func (r *Registry) NewCounterFunc(name string, help string, collect CollectFunc, labelNames ...string) {
+	r.register(&funcMetric{name: name, help: help, metricType: counterType, labelNames: labelNames, collect: collect})
+}
+
+// NewGaugeFunc registers a gauge whose samples are read from collect at scrape.
+func (r *Registry) NewGaugeFunc(name string, help string, collect CollectFunc, labelNames ...string) {
+	r.register(&funcMetric{name: name, help: help, metricType: gaugeType, labelNames: labelNames, collect: collect})
+}
+
+// WritePrometheus writes every metric, sorted by name, in the text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	metrics := make([]metric, len(names))
+	for i, name := range names {
+		metrics[i] = r.metrics[name]
+	}
+	r.mu.RUnlock()
+
+	buffered := bufio.NewWriter(w)
+	for _, m := range metrics {
+		name, help, metricType := m.describe()
+		fmt.Fprintf(buffered, "# HELP %s %s\n", name, strings.ReplaceAll(help, "\n", " "))
+		fmt.Fprintf(buffered, "# TYPE %s %s\n", name, metricType)
+		m.write(buffered)
+	}
+	return buffered.Flush()
+}
+
+// counters returns the current value of every counter sample, keyed by metric name
+// and then by label values joined with labelSeparator.
+func (r *Registry) counters() map[string]counterSnapshot {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	snapshots := map[string]counterSnapshot{}
+	for name, m := range r.metrics {
+		if c, ok := m.(*Counter); ok {
+			snapshots[name] = c.snapshot()
+		}
+	}
+	return snapshots
+}
+
+type counterSnapshot struct {
+	labelNames []string
+	values     map[string]float64
+}
+
+func writeSample(w *bufio.Writer, name string, labelNames []string, labelValues []string, extraName string, extraValue string, value float64) {
+	w.WriteString(name)
+	if len(labelNames) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, labelName := range labelNames {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labelName)
+			w.WriteString(`="`)
+			w.WriteString(escapeLabelValue(labelValues[i]))
+			w.WriteByte('"')
+		}
+		if extraName != "" {
+			if len(labelNames) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName)
+			w.WriteString(`="`)
+			w.WriteString(extraValue)
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatValue(value))
+	w.WriteByte('\n')
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func labelKey(labelNames []string, labelValues []string) string {
+	if len(labelValues) != len(labelNames) {
+		panic(fmt.Sprintf("expected %d label values for %v, got %d", len(labelNames), labelNames, len(labelValues)))
+	}
+	return strings.Join(labelValues, labelSeparator)
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"tannar.moss/backend/internal/money"
+)
+
+type SalesSummary struct {
+	BucketStart time.Time
+	OrderCount  uint64
+	Revenue     money.Money
+}
+
+type ProductSales struct {
+	ProductTitle string      `json:"product_title"`
+	Quantity     uint64      `json:"quantity"`
+	Revenue      money.Money `json:"revenue"`
+}
+
+type ChartSeries struct {
+	Revenue           []money.Money `json:"revenue"`
+	OrderCount        []uint64      `json:"order_count"`
+	AverageOrderValue []money.Money `json:"average_order_value"`
+}
+
+type ChartResponse struct {
+	Interval    string         `json:"interval"`
+	Timezone    string         `json:"timezone"`
+	From        string         `json:"from"`
+	To          string         `json:"to"`
+	Labels      []string       `json:"labels"`
+	Series      ChartSeries    `json:"series"`
+	TopProducts []ProductSales `json:"top_products"`
+}
+
+type ChartRequest struct {
+	From     string
+	To       string
+	Interval string
+	Timezone string
+	Limit    int
+}
@@ -0,0 +1,29 @@
+package model
+
+import "encoding/json"
+
+type AuditLogRequest struct {
+	Entity    string  `json:"entity" validate:"omitempty,lte=50"`
+	EntityID  *uint64 `json:"entity_id" validate:"omitempty,gt=0"`
+	ActorUser *uint64 `json:"actor_user" validate:"omitempty,gt=0"`
+	Action    string  `json:"action" validate:"omitempty,oneof=create update delete"`
+	Page      int     `json:"page" validate:"gte=0"`
+	PerPage   int     `json:"per_page" validate:"gte=0,lte=100"`
+}
+
+type AuditLogResponse struct {
+	ID        uint64          `json:"id"`
+	ActorUser uint64          `json:"actor_user"`
+	Entity    string          `json:"entity"`
+	EntityID  uint64          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Changes   json.RawMessage `json:"changes"`
+	TraceID   *string         `json:"trace_id"`
+	SourceIP  *string         `json:"source_ip"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type AuditLogPageResponse struct {
+	PagingResponse
+	Entries []AuditLogResponse `json:"entries"`
+}
@@ -0,0 +1,43 @@
+package model
+
+type CategoryRequest struct {
+	ParentID    *uint64 `json:"parent_id" validate:"omitempty,gt=0"`
+	Name        string  `json:"name" validate:"required,lte=50"`
+	Slug        string  `json:"slug" validate:"lte=100"`
+	Description string  `json:"description" validate:"lte=225"`
+}
+
+type CategoryResponse struct {
+	ID          uint64  `json:"id"`
+	ParentID    *uint64 `json:"parent_id"`
+	Name        string  `json:"name"`
+	Slug        string  `json:"slug"`
+	Description string  `json:"description"`
+	CreatedUser uint64  `json:"created_user"`
+	CreatedAt   string  `json:"created_at"`
+	UpdatedUser *uint64 `json:"updated_user"`
+	UpdatedAt   *string `json:"updated_at"`
+}
+
+type Breadcrumb struct {
+	ID   uint64 `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type CategoryNode struct {
+	CategoryResponse
+	Children []CategoryNode `json:"children"`
+}
+
+type CategoryDetailResponse struct {
+	Category    CategoryResponse   `json:"category"`
+	Breadcrumbs []Breadcrumb       `json:"breadcrumbs"`
+	Children    []CategoryResponse `json:"children"`
+}
+
+type CategoryProductsResponse struct {
+	Category    CategoryResponse      `json:"category"`
+	Breadcrumbs []Breadcrumb          `json:"breadcrumbs"`
+	Products    ProductSearchResponse `json:"products"`
+}
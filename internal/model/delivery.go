@@ -0,0 +1,51 @@
+package model
+
+import "time"
+
+type DeliveryAddress struct {
+	Country  string
+	City     string
+	AreaName string
+}
+
+type DeliveryDetailsRequest struct {
+	StreetNumber string `json:"street_number" validate:"lte=50"`
+	StreetName   string `json:"street_name" validate:"required,lte=225"`
+	ComplexName  string `json:"complex_name" validate:"lte=50"`
+	AreaName     string `json:"area_name" validate:"lte=50"`
+	City         string `json:"city" validate:"required,lte=50"`
+	Country      string `json:"country" validate:"required,lte=50"`
+	Notes        string `json:"notes" validate:"lte=225"`
+}
+
+type DeliveryDetailsResponse struct {
+	ID             uint64     `json:"id"`
+	StreetNumber   *string    `json:"street_number"`
+	StreetName     *string    `json:"street_name"`
+	ComplexName    *string    `json:"complex_name"`
+	AreaName       *string    `json:"area_name"`
+	City           *string    `json:"city"`
+	Country        *string    `json:"country"`
+	DeliverySlotID *uint64    `json:"delivery_slot_id"`
+	DesiredTime    *time.Time `json:"desired_time"`
+	Notes          *string    `json:"notes"`
+	FullfilledTime *time.Time `json:"fullfilled_time"`
+	CreatedUser    uint64     `json:"created_user"`
+	CreatedAt      string     `json:"created_at"`
+	UpdatedUser    *uint64    `json:"updated_user"`
+	UpdatedAt      *string    `json:"updated_at"`
+}
+
+func (details DeliveryDetailsResponse) Address() DeliveryAddress {
+	var address DeliveryAddress
+	if details.Country != nil {
+		address.Country = *details.Country
+	}
+	if details.City != nil {
+		address.City = *details.City
+	}
+	if details.AreaName != nil {
+		address.AreaName = *details.AreaName
+	}
+	return address
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"tannar.moss/backend/internal/money"
+)
+
+type DiscountRequest struct {
+	Code              *string       `json:"code" validate:"omitempty,gt=0,lte=50"`
+	Description       string        `json:"description" validate:"lte=225"`
+	DiscountTypeID    uint64        `json:"discount_type_id" validate:"required,oneof=1 2"`
+	Value             money.Decimal `json:"value"`
+	MinimumSpend      money.Money   `json:"minimum_spend"`
+	GlobalUsageLimit  *uint64       `json:"global_usage_limit" validate:"omitempty,gt=0"`
+	PerUserUsageLimit *uint64       `json:"per_user_usage_limit" validate:"omitempty,gt=0"`
+	StartsAt          *time.Time    `json:"starts_at"`
+	EndsAt            *time.Time    `json:"ends_at"`
+	ProductIDs        []uint64      `json:"product_ids" validate:"dive,gt=0"`
+}
+
+type DiscountResponse struct {
+	ID                uint64        `json:"id"`
+	Code              *string       `json:"code"`
+	Description       string        `json:"description"`
+	DiscountTypeID    uint64        `json:"discount_type_id"`
+	Value             money.Decimal `json:"value"`
+	MinimumSpend      money.Money   `json:"minimum_spend"`
+	GlobalUsageLimit  *uint64       `json:"global_usage_limit"`
+	PerUserUsageLimit *uint64       `json:"per_user_usage_limit"`
+	StartsAt          *time.Time    `json:"starts_at"`
+	EndsAt            *time.Time    `json:"ends_at"`
+	ProductIDs        []uint64      `json:"product_ids"`
+	CreatedUser       uint64        `json:"created_user"`
+	CreatedAt         string        `json:"created_at"`
+	UpdatedUser       *uint64       `json:"updated_user"`
+	UpdatedAt         *string       `json:"updated_at"`
+}
+
+type DiscountUsage struct {
+	Global  uint64
+	PerUser uint64
+}
+
+type AppliedDiscount struct {
+	DiscountID     uint64        `json:"discount_id"`
+	Code           *string       `json:"code"`
+	DiscountTypeID uint64        `json:"discount_type_id"`
+	Value          money.Decimal `json:"value"`
+	DiscountAmount money.Money   `json:"discount_amount"`
+}
+
+type PricedItem struct {
+	ProductID *uint64     `json:"product_id"`
+	Price     money.Money `json:"price"`
+	Quantity  uint64      `json:"quantity"`
+}
+
+type QuoteItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required,gt=0"`
+	VariantID *uint64 `json:"variant_id" validate:"omitempty,gt=0"`
+	Quantity  uint64  `json:"quantity" validate:"required,gt=0"`
+}
+
+type QuoteRequest struct {
+	Code  *string            `json:"code" validate:"omitempty,gt=0,lte=50"`
+	Items []QuoteItemRequest `json:"items" validate:"required,gt=0,dive"`
+}
+
+type ApplyDiscountRequest struct {
+	Code *string `json:"code" validate:"omitempty,gt=0,lte=50"`
+}
+
+type PriceQuoteResponse struct {
+	Subtotal      money.Money       `json:"subtotal"`
+	DiscountTotal money.Money       `json:"discount_total"`
+	Total         money.Money       `json:"total"`
+	Discounts     []AppliedDiscount `json:"discounts"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type OrderExportRequest struct {
+	Format    string    `json:"format" validate:"required,oneof=csv xlsx"`
+	From      time.Time `json:"from" validate:"required"`
+	To        time.Time `json:"to" validate:"required"`
+	StatusIDs []uint64  `json:"status_ids" validate:"dive,gt=0"`
+}
+
+// OrderExportRow is one order line of an export. Item is nil for an order without items.
+type OrderExportRow struct {
+	Order      OrderResponse
+	StatusName string
+	Item       *OrderItemResponse
+}
@@ -0,0 +1,13 @@
+package model
+
+type HealthCheckResponse struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Critical   bool    `json:"critical"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+type HealthResponse struct {
+	Status string                `json:"status"`
+	Checks []HealthCheckResponse `json:"checks,omitempty"`
+}
@@ -0,0 +1,30 @@
+package model
+
+// ProductImportRow is a parsed CSV row. Row is the line in the file, counting the
+// header as line 1, so errors can be matched to the spreadsheet.
+type ProductImportRow struct {
+	Row     uint64
+	ID      uint64
+	Request ProductRequest
+}
+
+type ImportRowError struct {
+	Row     uint64 `json:"row"`
+	Message string `json:"message"`
+}
+
+type ProductImportResponse struct {
+	ID            uint64           `json:"id"`
+	Status        string           `json:"status"`
+	TotalRows     uint64           `json:"total_rows"`
+	ProcessedRows uint64           `json:"processed_rows"`
+	Created       uint64           `json:"created"`
+	Updated       uint64           `json:"updated"`
+	Failed        uint64           `json:"failed"`
+	Errors        []ImportRowError `json:"errors"`
+	FinishedAt    *string          `json:"finished_at"`
+	CreatedUser   uint64           `json:"created_user"`
+	CreatedAt     string           `json:"created_at"`
+	UpdatedUser   *uint64          `json:"updated_user"`
+	UpdatedAt     *string          `json:"updated_at"`
+}
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"time"
+
+	"tannar.moss/backend/internal/money"
+)
+
+type OrderItemResponse struct {
+	ID                uint64             `json:"id"`
+	OrderID           uint64             `json:"order_id"`
+	ProductID         *uint64            `json:"product_id"`
+	VariantID         *uint64            `json:"variant_id"`
+	SKU               *string            `json:"sku"`
+	ProductTitle      string             `json:"product_title"`
+	VariantAttributes []VariantAttribute `json:"variant_attributes"`
+	Price             money.Money        `json:"price"`
+	Quantity          uint64             `json:"quantity"`
+	DiscountAmount    money.Money        `json:"discount_amount"`
+	TaxClassID        *uint64            `json:"tax_class_id"`
+	TaxRate           money.Decimal      `json:"tax_rate"`
+	TaxAmount         money.Money        `json:"tax_amount"`
+	PricesIncludeTax  bool               `json:"prices_include_tax"`
+	CreatedUser       uint64             `json:"created_user"`
+	CreatedAt         string             `json:"created_at"`
+	UpdatedUser       *uint64            `json:"updated_user"`
+	UpdatedAt         *string            `json:"updated_at"`
+}
+
+type OrderResponse struct {
+	ID                uint64              `json:"id"`
+	FirstName         string              `json:"first_name"`
+	LastName          string              `json:"last_name"`
+	Email             string              `json:"email"`
+	StatusID          uint64              `json:"status_id"`
+	DeliveryDetailsID uint64              `json:"delivery_details_id"`
+	Subtotal          money.Money         `json:"subtotal"`
+	DiscountTotal     money.Money         `json:"discount_total"`
+	TaxTotal          money.Money         `json:"tax_total"`
+	ShippingRateID    *uint64             `json:"shipping_rate_id"`
+	ShippingTotal     money.Money         `json:"shipping_total"`
+	Total             money.Money         `json:"total"`
+	CreatedUser       uint64              `json:"created_user"`
+	CreatedAt         string              `json:"created_at"`
+	UpdatedUser       *uint64             `json:"updated_user"`
+	UpdatedAt         *string             `json:"updated_at"`
+	Items             []OrderItemResponse `json:"items"`
+	Discounts         []AppliedDiscount   `json:"discounts"`
+}
+
+type OrderItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required,gt=0"`
+	VariantID *uint64 `json:"variant_id" validate:"omitempty,gt=0"`
+	Quantity  uint64  `json:"quantity" validate:"required,gt=0"`
+}
+
+type OrderRequest struct {
+	FirstName       string                 `json:"first_name" validate:"required,lte=50"`
+	LastName        string                 `json:"last_name" validate:"required,lte=50"`
+	Email           string                 `json:"email" validate:"required,email,lte=225"`
+	DeliveryDetails DeliveryDetailsRequest `json:"delivery_details" validate:"required"`
+	Items           []OrderItemRequest     `json:"items" validate:"required,gt=0,dive"`
+	Code            *string                `json:"code" validate:"omitempty,gt=0,lte=50"`
+	DeliverySlotID  *uint64                `json:"delivery_slot_id" validate:"omitempty,gt=0"`
+	DesiredTime     *time.Time             `json:"desired_time" validate:"excluded_without=DeliverySlotID"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type ProductResponse struct {
+	ID            uint64            `json:"id"`
+	SKU           *string           `json:"sku"`
+	Title         string            `json:"title"`
+	Description   string            `json:"description"`
+	Price         money.Money       `json:"price"`
+	Stock         uint64            `json:"stock"`
+	TaxClassID    uint64            `json:"tax_class_id"`
+	Weight        money.Decimal     `json:"weight"`
+	CategoryIDs   []uint64          `json:"category_ids"`
+	Pictures      []string          `json:"pictures"`
+	RatingAverage money.Decimal     `json:"rating_average"`
+	RatingCount   uint64            `json:"rating_count"`
+	Breadcrumbs   [][]Breadcrumb    `json:"breadcrumbs"`
+	Variants      []VariantResponse `json:"variants,omitempty"`
+	CreatedUser   uint64            `json:"created_user"`
+	CreatedAt     string            `json:"created_at"`
+	UpdatedUser   *uint64           `json:"updated_user"`
+	UpdatedAt     *string           `json:"updated_at"`
+}
+
+type ProductRequest struct {
+	SKU         *string       `json:"sku" validate:"omitempty,gt=0,lte=64"`
+	Title       string        `json:"title" validate:"required,lte=50"`
+	Description string        `json:"description" validate:"lte=225"`
+	Price       money.Money   `json:"price"`
+	Stock       uint64        `json:"stock"`
+	TaxClassID  uint64        `json:"tax_class_id" validate:"required,oneof=1 2 3"`
+	Weight      money.Decimal `json:"weight"`
+	CategoryIDs []uint64      `json:"category_ids" validate:"dive,gt=0"`
+	Pictures    []string      `json:"pictures" validate:"dive,required,lte=225"`
+}
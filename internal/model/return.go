@@ -0,0 +1,41 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type ReturnItemRequest struct {
+	OrderItemID uint64 `json:"order_item_id" validate:"required,gt=0"`
+	Quantity    uint64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type ReturnRequest struct {
+	Reason string              `json:"reason" validate:"required,gt=0,lte=225"`
+	Items  []ReturnItemRequest `json:"items" validate:"required,gt=0,dive"`
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason" validate:"required,gt=0,lte=225"`
+}
+
+type ReturnItemResponse struct {
+	ID           uint64      `json:"id"`
+	ReturnID     uint64      `json:"return_id"`
+	OrderItemID  uint64      `json:"order_item_id"`
+	ProductID    *uint64     `json:"product_id"`
+	VariantID    *uint64     `json:"variant_id"`
+	Quantity     uint64      `json:"quantity"`
+	RefundAmount money.Money `json:"refund_amount"`
+}
+
+type ReturnResponse struct {
+	ID               uint64               `json:"id"`
+	OrderID          uint64               `json:"order_id"`
+	StatusID         uint64               `json:"status_id"`
+	Reason           string               `json:"reason"`
+	RefundAmount     money.Money          `json:"refund_amount"`
+	PaymentReference *string              `json:"payment_reference"`
+	CreatedUser      uint64               `json:"created_user"`
+	CreatedAt        string               `json:"created_at"`
+	UpdatedUser      *uint64              `json:"updated_user"`
+	UpdatedAt        *string              `json:"updated_at"`
+	Items            []ReturnItemResponse `json:"items"`
+}
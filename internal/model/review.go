@@ -0,0 +1,37 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type ReviewRequest struct {
+	Rating uint64 `json:"rating" validate:"required,gte=1,lte=5"`
+	Title  string `json:"title" validate:"lte=50"`
+	Body   string `json:"body" validate:"lte=1000"`
+}
+
+type ReviewModerationRequest struct {
+	StatusID uint64  `json:"status_id" validate:"required,oneof=2 3"`
+	Note     *string `json:"note" validate:"omitempty,lte=225"`
+}
+
+type ReviewResponse struct {
+	ID             uint64  `json:"id"`
+	ProductID      uint64  `json:"product_id"`
+	StatusID       uint64  `json:"status_id"`
+	Rating         uint64  `json:"rating"`
+	Title          string  `json:"title"`
+	Body           string  `json:"body"`
+	ModerationNote *string `json:"moderation_note"`
+	ModeratedUser  *uint64 `json:"moderated_user"`
+	ModeratedAt    *string `json:"moderated_at"`
+	CreatedUser    uint64  `json:"created_user"`
+	CreatedAt      string  `json:"created_at"`
+	UpdatedUser    *uint64 `json:"updated_user"`
+	UpdatedAt      *string `json:"updated_at"`
+}
+
+type ProductReviewsResponse struct {
+	ProductID     uint64           `json:"product_id"`
+	RatingAverage money.Decimal    `json:"rating_average"`
+	RatingCount   uint64           `json:"rating_count"`
+	Reviews       []ReviewResponse `json:"reviews"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type ProductSearchRequest struct {
+	Query    string       `json:"query" validate:"lte=200"`
+	MinPrice *money.Money `json:"min_price"`
+	MaxPrice *money.Money `json:"max_price"`
+	Sort     string       `json:"sort" validate:"omitempty,oneof=relevance price_asc price_desc newest title"`
+	Page     int          `json:"page" validate:"gte=0"`
+	PerPage  int          `json:"per_page" validate:"gte=0,lte=100"`
+	// CategoryIDs limits matches to products in any of the categories.
+	CategoryIDs []uint64 `json:"category_ids" validate:"dive,gt=0"`
+}
+
+type ProductSearchHit struct {
+	Product ProductResponse `json:"product"`
+	Score   float64         `json:"score"`
+}
+
+// PriceFacet counts the matching products priced from Min up to, but excluding, Max.
+// The last facet has no upper bound.
+type PriceFacet struct {
+	Min   money.Money  `json:"min"`
+	Max   *money.Money `json:"max"`
+	Count uint64       `json:"count"`
+}
+
+type ProductSearchResponse struct {
+	Query          string             `json:"query"`
+	CorrectedQuery *string            `json:"corrected_query"`
+	Sort           string             `json:"sort"`
+	Page           int                `json:"page"`
+	PerPage        int                `json:"per_page"`
+	Total          uint64             `json:"total"`
+	Hits           []ProductSearchHit `json:"hits"`
+	PriceFacets    []PriceFacet       `json:"price_facets"`
+}
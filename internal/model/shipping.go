@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"tannar.moss/backend/internal/money"
+)
+
+type ShippingRateRequest struct {
+	RateBasisID uint64         `json:"rate_basis_id" validate:"required,oneof=1 2"`
+	MinValue    money.Decimal  `json:"min_value"`
+	MaxValue    *money.Decimal `json:"max_value"`
+	Price       money.Money    `json:"price"`
+}
+
+type ShippingRateResponse struct {
+	ID          uint64         `json:"id"`
+	ZoneID      uint64         `json:"zone_id"`
+	RateBasisID uint64         `json:"rate_basis_id"`
+	MinValue    money.Decimal  `json:"min_value"`
+	MaxValue    *money.Decimal `json:"max_value"`
+	Price       money.Money    `json:"price"`
+}
+
+type ShippingZoneRequest struct {
+	Name     string                `json:"name" validate:"required,gt=0,lte=50"`
+	Country  string                `json:"country" validate:"required,gt=0,lte=50"`
+	City     *string               `json:"city" validate:"omitempty,gt=0,lte=50"`
+	AreaName *string               `json:"area_name" validate:"omitempty,gt=0,lte=50"`
+	Rates    []ShippingRateRequest `json:"rates" validate:"required,gt=0,dive"`
+}
+
+type ShippingZoneResponse struct {
+	ID          uint64                 `json:"id"`
+	Name        string                 `json:"name"`
+	Country     string                 `json:"country"`
+	City        *string                `json:"city"`
+	AreaName    *string                `json:"area_name"`
+	Rates       []ShippingRateResponse `json:"rates"`
+	CreatedUser uint64                 `json:"created_user"`
+	CreatedAt   string                 `json:"created_at"`
+	UpdatedUser *uint64                `json:"updated_user"`
+	UpdatedAt   *string                `json:"updated_at"`
+}
+
+type DeliverySlotRequest struct {
+	ZoneID   uint64    `json:"zone_id" validate:"required,gt=0"`
+	StartsAt time.Time `json:"starts_at" validate:"required"`
+	EndsAt   time.Time `json:"ends_at" validate:"required"`
+	Capacity uint64    `json:"capacity" validate:"required,gt=0"`
+}
+
+type DeliverySlotResponse struct {
+	ID          uint64    `json:"id"`
+	ZoneID      uint64    `json:"zone_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	Capacity    uint64    `json:"capacity"`
+	Reserved    uint64    `json:"reserved"`
+	CreatedUser uint64    `json:"created_user"`
+	CreatedAt   string    `json:"created_at"`
+	UpdatedUser *uint64   `json:"updated_user"`
+	UpdatedAt   *string   `json:"updated_at"`
+}
+
+type ReserveSlotRequest struct {
+	DeliverySlotID uint64     `json:"delivery_slot_id" validate:"required,gt=0"`
+	DesiredTime    *time.Time `json:"desired_time"`
+}
+
+type ShippingQuoteResponse struct {
+	ZoneID uint64                 `json:"zone_id"`
+	RateID uint64                 `json:"rate_id"`
+	Weight money.Decimal          `json:"weight"`
+	Price  money.Money            `json:"price"`
+	Slots  []DeliverySlotResponse `json:"slots"`
+}
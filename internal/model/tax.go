@@ -0,0 +1,49 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type TaxRateRequest struct {
+	Country          string        `json:"country" validate:"required,gt=0,lte=50"`
+	Region           *string       `json:"region" validate:"omitempty,gt=0,lte=50"`
+	TaxClassID       uint64        `json:"tax_class_id" validate:"required,gt=0"`
+	Name             string        `json:"name" validate:"required,gt=0,lte=50"`
+	Rate             money.Decimal `json:"rate"`
+	PricesIncludeTax bool          `json:"prices_include_tax"`
+}
+
+type TaxRateResponse struct {
+	ID               uint64        `json:"id"`
+	Country          string        `json:"country"`
+	Region           *string       `json:"region"`
+	TaxClassID       uint64        `json:"tax_class_id"`
+	Name             string        `json:"name"`
+	Rate             money.Decimal `json:"rate"`
+	PricesIncludeTax bool          `json:"prices_include_tax"`
+	CreatedUser      uint64        `json:"created_user"`
+	CreatedAt        string        `json:"created_at"`
+	UpdatedUser      *uint64       `json:"updated_user"`
+	UpdatedAt        *string       `json:"updated_at"`
+}
+
+type TaxableItem struct {
+	OrderItemID uint64
+	TaxClassID  uint64
+	Price       money.Money
+	Quantity    uint64
+}
+
+type ItemTax struct {
+	OrderItemID      uint64
+	DiscountAmount   money.Money
+	TaxClassID       uint64
+	TaxRate          money.Decimal
+	TaxAmount        money.Money
+	PricesIncludeTax bool
+}
+
+type OrderTax struct {
+	Items    []ItemTax
+	TaxTotal money.Money
+	// ExclusiveTaxTotal is the part of TaxTotal that is added on top of prices.
+	ExclusiveTaxTotal money.Money
+}
@@ -0,0 +1,54 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type AttributeRequest struct {
+	Name   string   `json:"name" validate:"required,lte=50"`
+	Values []string `json:"values" validate:"required,gt=0,dive,required,lte=50"`
+}
+
+type AttributeValueResponse struct {
+	ID          uint64 `json:"id"`
+	AttributeID uint64 `json:"attribute_id"`
+	Value       string `json:"value"`
+}
+
+type AttributeResponse struct {
+	ID          uint64                   `json:"id"`
+	Name        string                   `json:"name"`
+	Values      []AttributeValueResponse `json:"values"`
+	CreatedUser uint64                   `json:"created_user"`
+	CreatedAt   string                   `json:"created_at"`
+	UpdatedUser *uint64                  `json:"updated_user"`
+	UpdatedAt   *string                  `json:"updated_at"`
+}
+
+type VariantRequest struct {
+	SKU               string       `json:"sku" validate:"required,lte=64"`
+	Price             *money.Money `json:"price"`
+	Stock             uint64       `json:"stock"`
+	AttributeValueIDs []uint64     `json:"attribute_value_ids" validate:"required,gt=0,dive,gt=0"`
+	Pictures          []string     `json:"pictures" validate:"dive,required,lte=225"`
+}
+
+// VariantAttribute is one attribute of a variant, e.g. Size: Medium. Order items keep
+// a copy so the order still reads correctly after the variant changes.
+type VariantAttribute struct {
+	AttributeValueID uint64 `json:"attribute_value_id"`
+	Name             string `json:"name"`
+	Value            string `json:"value"`
+}
+
+type VariantResponse struct {
+	ID          uint64             `json:"id"`
+	ProductID   uint64             `json:"product_id"`
+	SKU         string             `json:"sku"`
+	Price       *money.Money       `json:"price"`
+	Stock       uint64             `json:"stock"`
+	Attributes  []VariantAttribute `json:"attributes"`
+	Pictures    []string           `json:"pictures"`
+	CreatedUser uint64             `json:"created_user"`
+	CreatedAt   string             `json:"created_at"`
+	UpdatedUser *uint64            `json:"updated_user"`
+	UpdatedAt   *string            `json:"updated_at"`
+}
@@ -0,0 +1,30 @@
+package model
+
+import "tannar.moss/backend/internal/money"
+
+type WishlistItemRequest struct {
+	ProductID uint64  `json:"product_id" validate:"required,gt=0"`
+	VariantID *uint64 `json:"variant_id" validate:"omitempty,gt=0"`
+}
+
+type MoveWishlistItemRequest struct {
+	Quantity uint64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type WishlistItemResponse struct {
+	ID           uint64      `json:"id"`
+	ProductID    uint64      `json:"product_id"`
+	VariantID    *uint64     `json:"variant_id"`
+	SKU          *string     `json:"sku"`
+	Title        string      `json:"title"`
+	SavedPrice   money.Money `json:"saved_price"`
+	SavedStock   uint64      `json:"saved_stock"`
+	Price        money.Money `json:"price"`
+	Stock        uint64      `json:"stock"`
+	PriceDropped bool        `json:"price_dropped"`
+	BackInStock  bool        `json:"back_in_stock"`
+	CreatedUser  uint64      `json:"created_user"`
+	CreatedAt    string      `json:"created_at"`
+	UpdatedUser  *uint64     `json:"updated_user"`
+	UpdatedAt    *string     `json:"updated_at"`
+}
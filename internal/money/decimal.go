@@ -0,0 +1,201 @@
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	decimalPlaces = 4
+	decimalFactor = 10000
+)
+
+type RoundingMode int
+
+const (
+	// HalfUp rounds halves away from zero, the usual rule for prices and discounts.
+	HalfUp RoundingMode = iota
+	// HalfEven rounds halves to the nearest even digit (banker's rounding).
+	HalfEven
+	// Down truncates towards zero.
+	Down
+)
+
+// Decimal is a fixed point number with four decimal places, used for rates and
+// percentages that are not themselves amounts of money.
+type Decimal struct {
+	scaled int64
+}
+
+func ParseDecimal(value string) (Decimal, error) {
+	scaled, err := parseScaled(value, decimalPlaces, HalfUp)
+	if err != nil {
+		return Decimal{}, err
+	}
+	return Decimal{scaled: scaled}, nil
+}
+
+// NewDecimal builds a decimal from a whole number, e.g. NewDecimal(15) is 15.0000.
+func NewDecimal(units int64) Decimal {
+	return Decimal{scaled: units * decimalFactor}
+}
+
+func (d Decimal) IsZero() bool {
+	return d.scaled == 0
+}
+
+func (d Decimal) IsPositive() bool {
+	return d.scaled > 0
+}
+
+func (d Decimal) Cmp(other Decimal) int {
+	switch {
+	case d.scaled < other.scaled:
+		return -1
+	case d.scaled > other.scaled:
+		return 1
+	}
+	return 0
+}
+
+// Money reads the decimal as an amount in major units of the currency.
+func (d Decimal) Money(currency string, mode RoundingMode) (Money, error) {
+	exp := exponent(currency)
+	numerator := new(big.Int).Mul(big.NewInt(d.scaled), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+	minor, err := divRound(numerator, big.NewInt(decimalFactor), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(minor, currency), nil
+}
+
+func (d Decimal) String() string {
+	return strings.TrimRight(strings.TrimRight(formatScaled(d.scaled, decimalPlaces), "0"), ".")
+}
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" || value == "" {
+		*d = Decimal{}
+		return nil
+	}
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d Decimal) Value() (driver.Value, error) {
+	return formatScaled(d.scaled, decimalPlaces), nil
+}
+
+func (d *Decimal) Scan(src any) error {
+	var value string
+	switch typed := src.(type) {
+	case nil:
+		*d = Decimal{}
+		return nil
+	case []byte:
+		value = string(typed)
+	case string:
+		value = typed
+	case int64:
+		value = strconv.FormatInt(typed, 10)
+	case float64:
+		value = strconv.FormatFloat(typed, 'f', -1, 64)
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	parsed, err := ParseDecimal(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// parseScaled reads a plain decimal string into an integer scaled by 10^places.
+func parseScaled(value string, places int, mode RoundingMode) (int64, error) {
+	value = strings.TrimSpace(value)
+	rat, ok := new(big.Rat).SetString(value)
+	if !ok || value == "" || strings.ContainsAny(value, "/eE") {
+		return 0, ErrInvalidAmount
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	numerator := new(big.Int).Mul(rat.Num(), scale)
+	return divRound(numerator, rat.Denom(), mode)
+}
+
+func formatScaled(scaled int64, places int) string {
+	sign := ""
+	magnitude := new(big.Int).SetInt64(scaled)
+	if scaled < 0 {
+		sign = "-"
+		magnitude.Neg(magnitude)
+	}
+	digits := magnitude.String()
+	if places == 0 {
+		return sign + digits
+	}
+	if len(digits) <= places {
+		digits = strings.Repeat("0", places-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-places] + "." + digits[len(digits)-places:]
+}
+
+// divRound divides numerator by denominator, rounding to an integer with the given mode.
+func divRound(numerator *big.Int, denominator *big.Int, mode RoundingMode) (int64, error) {
+	if denominator.Sign() == 0 {
+		return 0, ErrDivideByZero
+	}
+	if denominator.Sign() < 0 {
+		numerator = new(big.Int).Neg(numerator)
+		denominator = new(big.Int).Neg(denominator)
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() != 0 && mode != Down {
+		twiceRemainder := new(big.Int).Abs(remainder)
+		twiceRemainder.Mul(twiceRemainder, big.NewInt(2))
+		cmp := twiceRemainder.Cmp(denominator)
+		roundAway := cmp > 0 || (cmp == 0 && (mode == HalfUp || quotient.Bit(0) == 1))
+		if roundAway {
+			if numerator.Sign() < 0 {
+				quotient.Sub(quotient, big.NewInt(1))
+			} else {
+				quotient.Add(quotient, big.NewInt(1))
+			}
+		}
+	}
+
+	if !quotient.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return quotient.Int64(), nil
+}
+
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	sum := new(big.Int).Add(big.NewInt(d.scaled), big.NewInt(other.scaled))
+	if !sum.IsInt64() {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{scaled: sum.Int64()}, nil
+}
+
+// Mul multiplies the decimal by a whole quantity, e.g. a unit weight by the number of units.
+func (d Decimal) Mul(quantity int64) (Decimal, error) {
+	product := new(big.Int).Mul(big.NewInt(d.scaled), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Decimal{}, ErrOverflow
+	}
+	return Decimal{scaled: product.Int64()}, nil
+}
@@ -0,0 +1,308 @@
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the one currency the shop prices in. The database and the JSON
+// API hold amounts as bare decimals with no currency code, so every amount read is
+// in this currency, and writing an amount in any other currency is refused with
+// ErrUnsupportedCurrency rather than silently relabelled.
+const DefaultCurrency = "ZAR"
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: amount overflows int64 minor units")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrDivideByZero     = errors.New("money: divide by zero")
+	// ErrUnsupportedCurrency is returned when an amount in a currency other than
+	// DefaultCurrency is marshalled or stored.
+	ErrUnsupportedCurrency = errors.New("money: only " + DefaultCurrency + " amounts can be marshalled or stored")
+)
+
+// minor unit exponents for currencies that do not use two decimal places
+var exponents = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+}
+
+func exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Money is an amount held in integer minor units (e.g. cents) of a currency.
+// The zero value is an empty amount with no currency, which takes on the currency
+// of whatever it is first added to.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+func New(minor int64, currency string) Money {
+	return Money{minor: minor, currency: currency}
+}
+
+func Zero(currency string) Money {
+	return Money{currency: currency}
+}
+
+// Parse reads a decimal string such as "29.99", rounding half up when it has more
+// decimal places than the currency's minor unit.
+func Parse(value string, currency string) (Money, error) {
+	minor, err := parseScaled(value, exponent(currency), HalfUp)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(minor, currency), nil
+}
+
+func (m Money) Minor() int64 {
+	return m.minor
+}
+
+func (m Money) Currency() string {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+func (m Money) resolveCurrency(other Money) (string, error) {
+	switch {
+	case m.currency == other.currency:
+		return m.currency, nil
+	case m.currency == "" && m.minor == 0:
+		return other.currency, nil
+	case other.currency == "" && other.minor == 0:
+		return m.currency, nil
+	}
+	return "", ErrCurrencyMismatch
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	currency, err := m.resolveCurrency(other)
+	if err != nil {
+		return Money{}, err
+	}
+	sum := m.minor + other.minor
+	if (other.minor > 0 && sum < m.minor) || (other.minor < 0 && sum > m.minor) {
+		return Money{}, ErrOverflow
+	}
+	return New(sum, currency), nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if other.minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(New(-other.minor, other.currency))
+}
+
+// Mul multiplies the amount by a whole quantity.
+func (m Money) Mul(quantity int64) (Money, error) {
+	return m.MulRatio(quantity, 1, HalfUp)
+}
+
+// MulRatio multiplies the amount by numerator/denominator, rounding the result to
+// whole minor units with the given mode.
+func (m Money) MulRatio(numerator int64, denominator int64, mode RoundingMode) (Money, error) {
+	if denominator == 0 {
+		return Money{}, ErrDivideByZero
+	}
+	product := new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(numerator))
+	minor, err := divRound(product, big.NewInt(denominator), mode)
+	if err != nil {
+		return Money{}, err
+	}
+	return New(minor, m.currency), nil
+}
+
+// Percent takes rate percent of the amount, e.g. a rate of 15 gives 15% of m.
+func (m Money) Percent(rate Decimal, mode RoundingMode) (Money, error) {
+	return m.MulRatio(rate.scaled, 100*decimalFactor, mode)
+}
+
+// Compare returns -1, 0 or 1 when m is less than, equal to or greater than other.
+func (m Money) Compare(other Money) (int, error) {
+	if _, err := m.resolveCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Min returns the smaller of the two amounts.
+func (m Money) Min(other Money) (Money, error) {
+	cmp, err := m.Compare(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp <= 0 {
+		return m, nil
+	}
+	return other, nil
+}
+
+// Sum adds up the amounts in the given currency.
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		var err error
+		total, err = total.Add(amount)
+		if err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount as a plain decimal in major units, e.g. "29.99".
+func (m Money) String() string {
+	return formatScaled(m.minor, exponent(m.currency))
+}
+
+// checkCurrency refuses amounts that would read back in the wrong currency. An
+// amount without a currency is taken to be in DefaultCurrency.
+func (m Money) checkCurrency() error {
+	if m.currency != "" && m.currency != DefaultCurrency {
+		return ErrUnsupportedCurrency
+	}
+	return nil
+}
+
+// MarshalJSON writes a JSON number in major units of DefaultCurrency.
+func (m Money) MarshalJSON() ([]byte, error) {
+	if err := m.checkCurrency(); err != nil {
+		return nil, err
+	}
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON accepts a JSON number or string in major units in the default currency.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "null" || value == "" {
+		*m = Zero(DefaultCurrency)
+		return nil
+	}
+	parsed, err := Parse(value, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// Value stores the amount as a DECIMAL string in major units of DefaultCurrency.
+func (m Money) Value() (driver.Value, error) {
+	if err := m.checkCurrency(); err != nil {
+		return nil, err
+	}
+	return m.String(), nil
+}
+
+// Scan reads a DECIMAL column in the default currency. NULL reads as zero.
+func (m *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*m = Zero(DefaultCurrency)
+		return nil
+	case []byte:
+		return m.scanString(string(value))
+	case string:
+		return m.scanString(value)
+	case int64:
+		return m.scanString(strconv.FormatInt(value, 10))
+	case float64:
+		return m.scanString(strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return fmt.Errorf("money: cannot scan %T", src)
+}
+
+func (m *Money) scanString(value string) error {
+	parsed, err := Parse(value, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// IncludedPercent works out the part of a tax inclusive amount that is rate percent
+// tax, e.g. 115.00 at a rate of 15 includes 15.00.
+func (m Money) IncludedPercent(rate Decimal, mode RoundingMode) (Money, error) {
+	return m.MulRatio(rate.scaled, 100*decimalFactor+rate.scaled, mode)
+}
+
+// Allocate splits the amount across the weights in proportion, handing the minor
+// units lost to rounding to the largest remainders so the parts always add up to m.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	parts := make([]Money, len(weights))
+	var total int64
+	for _, weight := range weights {
+		if weight < 0 {
+			return nil, ErrInvalidAmount
+		}
+		if total > math.MaxInt64-weight {
+			return nil, ErrOverflow
+		}
+		total += weight
+	}
+	if total == 0 {
+		for i := range parts {
+			parts[i] = Zero(m.currency)
+		}
+		return parts, nil
+	}
+
+	remainders := make([]*big.Int, len(weights))
+	allocated := int64(0)
+	for i, weight := range weights {
+		share, remainder := new(big.Int).QuoRem(new(big.Int).Mul(big.NewInt(m.minor), big.NewInt(weight)), big.NewInt(total), new(big.Int))
+		parts[i] = New(share.Int64(), m.currency)
+		remainders[i] = remainder.Abs(remainder)
+		allocated += share.Int64()
+	}
+
+	step := int64(1)
+	if m.minor < 0 {
+		step = -1
+	}
+	for leftover := m.minor - allocated; leftover != 0; leftover -= step {
+		largest := 0
+		for i := range remainders {
+			if remainders[i].Cmp(remainders[largest]) > 0 {
+				largest = i
+			}
+		}
+		parts[largest].minor += step
+		remainders[largest] = new(big.Int)
+	}
+
+	return parts, nil
+}
@@ -0,0 +1,226 @@
+package money_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"tannar.moss/backend/internal/money"
+)
+
+func mustParse(t *testing.T, value string) money.Money {
+	amount, err := money.Parse(value, money.DefaultCurrency)
+	if err != nil {
+		t.Fatalf("Error parsing '%s': %v", value, err)
+	}
+	return amount
+}
+
+func TestParse_withDecimalStrings_shouldStoreMinorUnits(t *testing.T) {
+	cases := map[string]int64{
+		"29.99":  2999,
+		"19.9":   1990,
+		"5":      500,
+		"0.005":  1,
+		"0.0049": 0,
+		"-1.005": -101,
+	}
+	for value, expected := range cases {
+		if minor := mustParse(t, value).Minor(); minor != expected {
+			t.Errorf("Expected '%s' to parse to %d minor units but got %d", value, expected, minor)
+		}
+	}
+}
+
+func TestParse_withInvalidStrings_shouldError(t *testing.T) {
+	for _, value := range []string{"", "abc", "1/3", "1e3"} {
+		if _, err := money.Parse(value, money.DefaultCurrency); err == nil {
+			t.Errorf("Expected error parsing '%s'", value)
+		}
+	}
+}
+
+func TestAdd_withRepeatedCents_shouldNotDrift(t *testing.T) {
+	total := money.Money{}
+	tenCents := mustParse(t, "0.10")
+	for i := 0; i < 10; i++ {
+		var err error
+		total, err = total.Add(tenCents)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if total.String() != "1.00" || total.Currency() != money.DefaultCurrency {
+		t.Errorf("Expected 1.00 %s but got %s %s", money.DefaultCurrency, total.String(), total.Currency())
+	}
+}
+
+func TestAdd_withDifferentCurrencies_shouldError(t *testing.T) {
+	_, err := money.New(100, "ZAR").Add(money.New(100, "USD"))
+	if err != money.ErrCurrencyMismatch {
+		t.Errorf("Expected currency mismatch but got %v", err)
+	}
+}
+
+func TestAdd_withOverflow_shouldError(t *testing.T) {
+	_, err := money.New(math.MaxInt64, "ZAR").Add(money.New(1, "ZAR"))
+	if err != money.ErrOverflow {
+		t.Errorf("Expected overflow but got %v", err)
+	}
+
+	_, err = money.New(math.MaxInt64, "ZAR").Mul(2)
+	if err != money.ErrOverflow {
+		t.Errorf("Expected overflow but got %v", err)
+	}
+}
+
+func TestMulRatio_withRoundingModes_shouldRoundHalves(t *testing.T) {
+	amount := money.New(25, "ZAR")
+
+	cases := []struct {
+		mode     money.RoundingMode
+		expected int64
+	}{
+		{money.HalfUp, 13},
+		{money.HalfEven, 12},
+		{money.Down, 12},
+	}
+	for _, c := range cases {
+		result, err := amount.MulRatio(1, 2, c.mode)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if result.Minor() != c.expected {
+			t.Errorf("Expected %d with mode %d but got %d", c.expected, c.mode, result.Minor())
+		}
+	}
+}
+
+func TestPercent_withFractionalRate_shouldRoundToCents(t *testing.T) {
+	rate, err := money.ParseDecimal("12.5")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := mustParse(t, "79.97").Percent(rate, money.HalfUp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.String() != "10.00" {
+		t.Errorf("Expected 10.00 but got %s", result.String())
+	}
+}
+
+func TestDecimalMoney_withMoreDecimalsThanCurrency_shouldRound(t *testing.T) {
+	value, err := money.ParseDecimal("19.995")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	amount, err := value.Money(money.DefaultCurrency, money.HalfUp)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amount.String() != "20.00" {
+		t.Errorf("Expected 20.00 but got %s", amount.String())
+	}
+}
+
+func TestMoneyJSON_withRoundTrip_shouldKeepNumberShape(t *testing.T) {
+	type payload struct {
+		Price money.Money   `json:"price"`
+		Rate  money.Decimal `json:"rate"`
+	}
+
+	var decoded payload
+	err := json.Unmarshal([]byte(`{"price": 29.99, "rate": "12.50"}`), &decoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(encoded) != `{"price":29.99,"rate":12.5}` {
+		t.Errorf("Unexpected JSON %s", encoded)
+	}
+}
+
+func TestMoneySQL_withDecimalColumn_shouldScanAndValue(t *testing.T) {
+	var amount money.Money
+	if err := amount.Scan([]byte("1234.50")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amount.Minor() != 123450 {
+		t.Errorf("Expected 123450 minor units but got %d", amount.Minor())
+	}
+
+	value, err := amount.Value()
+	if err != nil || value != "1234.50" {
+		t.Errorf("Expected driver value '1234.50' but got '%v' (%v)", value, err)
+	}
+
+	if err := amount.Scan(nil); err != nil || !amount.IsZero() {
+		t.Errorf("Expected NULL to scan as zero but got %s (%v)", amount.String(), err)
+	}
+}
+
+func TestMoneyJSONAndSQL_withOtherCurrency_shouldRefuse(t *testing.T) {
+	amount := money.New(1500, "USD")
+	if _, err := json.Marshal(amount); !errors.Is(err, money.ErrUnsupportedCurrency) {
+		t.Errorf("Expected marshalling USD to fail with ErrUnsupportedCurrency but got %v", err)
+	}
+	if _, err := amount.Value(); !errors.Is(err, money.ErrUnsupportedCurrency) {
+		t.Errorf("Expected storing USD to fail with ErrUnsupportedCurrency but got %v", err)
+	}
+	if _, err := money.New(1500, "").Value(); err != nil {
+		t.Errorf("Expected an amount without a currency to store as %s but got %v", money.DefaultCurrency, err)
+	}
+}
+
+func TestString_withZeroExponentCurrency_shouldHaveNoDecimals(t *testing.T) {
+	amount, err := money.Parse("1500", "JPY")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if amount.String() != "1500" || amount.Minor() != 1500 {
+		t.Errorf("Expected 1500 JPY but got %s (%d)", amount.String(), amount.Minor())
+	}
+}
+
+func TestIncludedPercent_withInclusivePrice_shouldExtractTax(t *testing.T) {
+	rate, err := money.ParseDecimal("15")
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	tax, err := mustParse(t, "115.00").IncludedPercent(rate, money.HalfUp)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if tax.String() != "15.00" {
+		t.Errorf("Expected 15.00 tax but got %s", tax)
+	}
+}
+
+func TestAllocate_withUnevenSplit_shouldAddUpToAmount(t *testing.T) {
+	parts, err := mustParse(t, "10.00").Allocate(1, 1, 1)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if parts[0].String() != "3.34" || parts[1].String() != "3.33" || parts[2].String() != "3.33" {
+		t.Errorf("Expected 3.34, 3.33 and 3.33 but got %v", parts)
+	}
+
+	parts, err = mustParse(t, "5.00").Allocate(5998, 1999)
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if parts[0].String() != "3.75" || parts[1].String() != "1.25" {
+		t.Errorf("Expected 3.75 and 1.25 but got %v", parts)
+	}
+}
@@ -0,0 +1,53 @@
+package payment
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/types"
+)
+
+type Gateway interface {
+	// Refund pays amount back for the order. reference is the idempotency key: a
+	// repeated refund with the same reference returns the first refund's payment
+	// reference without paying out again.
+	Refund(orderId uint64, amount money.Money, reference string) (string, error)
+}
+
+// StandInGateway acknowledges every refund without contacting a provider.
+// It is used until a real payment provider is plugged in.
+type StandInGateway struct {
+	logger logger.Logger
+
+	mu       sync.Mutex
+	refunded map[string]string
+}
+
+func NewStandInGateway(logger logger.Logger) Gateway {
+	return &StandInGateway{
+		logger:   logger,
+		refunded: map[string]string{},
+	}
+}
+
+func (g *StandInGateway) Refund(orderId uint64, amount money.Money, reference string) (string, error) {
+	if !amount.IsPositive() {
+		g.logger.Errorf("Refusing refund of '%s' for order '%d'", amount, orderId)
+		return "", types.NewInvalidInputError()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if paymentReference, ok := g.refunded[reference]; ok {
+		g.logger.Infof("Refund '%s' for order '%d' was already paid with reference '%s'", reference, orderId, paymentReference)
+		return paymentReference, nil
+	}
+
+	paymentReference := "refund-" + uuid.NewString()
+	g.refunded[reference] = paymentReference
+	g.logger.Infof("Refunded '%s %s' for order '%d' (%s) with reference '%s'", amount.Currency(), amount, orderId, reference, paymentReference)
+
+	return paymentReference, nil
+}
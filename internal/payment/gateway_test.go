@@ -0,0 +1,28 @@
+package payment_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/payment"
+)
+
+func TestStandInRefund_withRepeatedReference_shouldReturnFirstRefund(t *testing.T) {
+	gateway := payment.NewStandInGateway(logger.NewSimpleLogger("ERROR", false))
+	amount := money.New(2999, money.DefaultCurrency)
+
+	first, err := gateway.Refund(7, amount, "RMA-1")
+	if err != nil {
+		t.Fatalf("Refund failed: %v", err)
+	}
+	second, _ := gateway.Refund(7, amount, "RMA-1")
+	other, _ := gateway.Refund(7, amount, "RMA-2")
+
+	if second != first {
+		t.Errorf("Refund test failed, expected[%s] for the repeated reference, got[%s]", first, second)
+	}
+	if other == first {
+		t.Errorf("Refund test failed, expected a new refund for another reference")
+	}
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/utils"
+)
+
+type AnalyticsRepository interface {
+	RefreshSummaries(ctx context.Context, from time.Time, to time.Time, refreshedAt time.Time) error
+	GetSalesSummaries(ctx context.Context, from time.Time, to time.Time) ([]model.SalesSummary, error)
+	GetTopProducts(ctx context.Context, from time.Time, to time.Time, limit int) ([]model.ProductSales, error)
+	Shutdown()
+}
+
+type MySqlAnalyticsRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlAnalyticsRepository(logger logger.Logger, db mysql.DbConnection) AnalyticsRepository {
+	return &MySqlAnalyticsRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlAnalyticsRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close analytics repo: %s", err.Error())
+	}
+}
+
+// RefreshSummaries recomputes the hourly buckets between from and to. Buckets are
+// upserted rather than cleared first so charts never see a half built window, then
+// any bucket this refresh did not touch (all its orders were cancelled) is removed.
+func (repo *MySqlAnalyticsRepository) RefreshSummaries(ctx context.Context, from time.Time, to time.Time, refreshedAt time.Time) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO sales_summaries (bucket_start, order_count, revenue, refreshed_at) SELECT DATE_FORMAT(o.created_at, '%Y-%m-%d %H:00:00'), COUNT(*), SUM(o.total), ? FROM orders o WHERE o.deleted_at IS NULL AND o.status_id NOT IN (?, ?) AND o.created_at >= ? AND o.created_at < ? GROUP BY 1 ON DUPLICATE KEY UPDATE order_count = VALUES(order_count), revenue = VALUES(revenue), refreshed_at = VALUES(refreshed_at)"
+	log.Debugf("Running query '%s' with parameter '%s', '%s' and '%s'", query, refreshedAt, from, to)
+	_, err := flows.PerformEdit(
+		ctx,
+		"RefreshSalesSummaries",
+		query,
+		repo.DB,
+		log,
+		refreshedAt, constant.ORDER_STATUS_AWAITING_PAYMENT, constant.ORDER_STATUS_CANCELLED, from, to)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO product_sales_summaries (bucket_start, product_title, product_title_hash, quantity, revenue, refreshed_at) SELECT DATE_FORMAT(o.created_at, '%Y-%m-%d %H:00:00'), i.product_title, SHA2(COALESCE(i.product_title, ''), 256), SUM(i.quantity), SUM(i.price * i.quantity - i.discount_amount), ? FROM order_items i JOIN orders o ON o.id = i.order_id WHERE i.deleted_at IS NULL AND o.deleted_at IS NULL AND o.status_id NOT IN (?, ?) AND o.created_at >= ? AND o.created_at < ? GROUP BY 1, i.product_title ON DUPLICATE KEY UPDATE quantity = VALUES(quantity), revenue = VALUES(revenue), refreshed_at = VALUES(refreshed_at)"
+	log.Debugf("Running query '%s' with parameter '%s', '%s' and '%s'", query, refreshedAt, from, to)
+	_, err = flows.PerformEdit(
+		ctx,
+		"RefreshProductSalesSummaries",
+		query,
+		repo.DB,
+		log,
+		refreshedAt, constant.ORDER_STATUS_AWAITING_PAYMENT, constant.ORDER_STATUS_CANCELLED, from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"sales_summaries", "product_sales_summaries"} {
+		query = "DELETE FROM " + table + " WHERE bucket_start >= ? AND bucket_start < ? AND refreshed_at <> ?"
+		log.Debugf("Running query '%s' with parameter '%s', '%s' and '%s'", query, from, to, refreshedAt)
+		_, err = flows.PerformEdit(
+			ctx,
+			"ClearStaleSummaries",
+			query,
+			repo.DB,
+			log,
+			from, to, refreshedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlAnalyticsRepository) GetSalesSummaries(ctx context.Context, from time.Time, to time.Time) ([]model.SalesSummary, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT bucket_start, order_count, revenue FROM sales_summaries WHERE bucket_start >= ? AND bucket_start < ? ORDER BY bucket_start"
+	stmt, err := flows.GetReaderStatement(ctx, "GetSalesSummaries", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%s' and '%s'", query, from, to)
+
+	rows, err := stmt.Query(from, to)
+	if err != nil {
+		return nil, utils.QueryError("GetSalesSummaries", log, err)
+	}
+	defer rows.Close()
+
+	summaries := make([]model.SalesSummary, 0)
+	for rows.Next() {
+		var summary model.SalesSummary
+		err = rows.Scan(&summary.BucketStart, &summary.OrderCount, &summary.Revenue)
+		if err != nil {
+			return nil, utils.QueryError("GetSalesSummaries", log, err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetSalesSummaries", log, err)
+	}
+
+	return summaries, nil
+}
+
+func (repo *MySqlAnalyticsRepository) GetTopProducts(ctx context.Context, from time.Time, to time.Time, limit int) ([]model.ProductSales, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT COALESCE(product_title, ''), SUM(quantity), SUM(revenue) FROM product_sales_summaries WHERE bucket_start >= ? AND bucket_start < ? GROUP BY product_title_hash, product_title ORDER BY SUM(revenue) DESC, SUM(quantity) DESC LIMIT ?"
+	stmt, err := flows.GetReaderStatement(ctx, "GetTopProducts", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%s', '%s' and '%d'", query, from, to, limit)
+
+	rows, err := stmt.Query(from, to, limit)
+	if err != nil {
+		return nil, utils.QueryError("GetTopProducts", log, err)
+	}
+	defer rows.Close()
+
+	products := make([]model.ProductSales, 0)
+	for rows.Next() {
+		var product model.ProductSales
+		err = rows.Scan(&product.ProductTitle, &product.Quantity, &product.Revenue)
+		if err != nil {
+			return nil, utils.QueryError("GetTopProducts", log, err)
+		}
+		products = append(products, product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetTopProducts", log, err)
+	}
+
+	return products, nil
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type AttributeRepository interface {
+	GetAll(ctx context.Context) ([]model.AttributeResponse, error)
+	GetByID(ctx context.Context, attributeId uint64) (*model.AttributeResponse, error)
+	Create(ctx context.Context, request model.AttributeRequest, creatingUserId uint64) (*model.AttributeResponse, error)
+	Update(ctx context.Context, attributeId uint64, request model.AttributeRequest, updatingUserId uint64) (*model.AttributeResponse, error)
+	Delete(ctx context.Context, attributeId uint64, deletingUserId uint64) error
+	InUse(ctx context.Context, attributeId uint64) (bool, error)
+	Shutdown()
+}
+
+type MySqlAttributeRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlAttributeRepository(logger logger.Logger, db mysql.DbConnection) AttributeRepository {
+	return &MySqlAttributeRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlAttributeRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close attribute repo: %s", err.Error())
+	}
+}
+
+const attributeColumns = "id, name, created_user, created_at, updated_user, updated_at"
+
+type attributeScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlAttributeRepository) scanAttribute(row attributeScanner) (*model.AttributeResponse, error) {
+	var attribute model.AttributeResponse
+	err := row.Scan(&attribute.ID, &attribute.Name, &attribute.CreatedUser, &attribute.CreatedAt, &attribute.UpdatedUser, &attribute.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for attribute: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal attribute response: %s", err.Error())
+		return nil, err
+	}
+	attribute.Values = make([]model.AttributeValueResponse, 0)
+
+	return &attribute, nil
+}
+
+// fillValues loads the live values of every given attribute in a single query.
+func (repo *MySqlAttributeRepository) fillValues(ctx context.Context, attributes []model.AttributeResponse) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	byId := make(map[uint64]*model.AttributeResponse, len(attributes))
+	for i := range attributes {
+		byId[attributes[i].ID] = &attributes[i]
+	}
+
+	query := "SELECT id, attribute_id, value FROM attribute_values WHERE deleted_at IS NULL ORDER BY id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetAttributeValues", query, repo.DB, log)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s'", query)
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return utils.QueryError("GetAttributeValues", log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var value model.AttributeValueResponse
+		err = rows.Scan(&value.ID, &value.AttributeID, &value.Value)
+		if err != nil {
+			return utils.QueryError("GetAttributeValues", log, err)
+		}
+		if attribute, ok := byId[value.AttributeID]; ok {
+			attribute.Values = append(attribute.Values, value)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return utils.QueryError("GetAttributeValues", log, err)
+	}
+
+	return nil
+}
+
+func (repo *MySqlAttributeRepository) GetAll(ctx context.Context) ([]model.AttributeResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + attributeColumns + " FROM attributes WHERE deleted_at IS NULL ORDER BY name, id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetAllAttributes", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s'", query)
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, utils.QueryError("GetAllAttributes", log, err)
+	}
+	defer rows.Close()
+
+	attributes := make([]model.AttributeResponse, 0)
+	for rows.Next() {
+		attribute, err := repo.scanAttribute(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetAllAttributes", log, err)
+		}
+		attributes = append(attributes, *attribute)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetAllAttributes", log, err)
+	}
+
+	err = repo.fillValues(ctx, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+func (repo *MySqlAttributeRepository) GetByID(ctx context.Context, attributeId uint64) (*model.AttributeResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + attributeColumns + " FROM attributes WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetAttributeByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, attributeId)
+
+	attribute, err := repo.scanAttribute(stmt.QueryRow(attributeId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetAttributeByID", log, err)
+	}
+
+	attributes := []model.AttributeResponse{*attribute}
+	err = repo.fillValues(ctx, attributes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &attributes[0], nil
+}
+
+func (repo *MySqlAttributeRepository) addValues(ctx context.Context, attributeId uint64, values []string, creatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO attribute_values (attribute_id, value, created_user, created_at) VALUES (?, ?, ?, now())"
+	for _, value := range values {
+		log.Debugf("Running query '%s' with parameter '%d', '%s' and '%d'", query, attributeId, value, creatingUserId)
+		_, err := flows.PerformEdit(
+			ctx,
+			"AddAttributeValue",
+			query,
+			repo.DB,
+			log,
+			attributeId, value, creatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlAttributeRepository) Create(ctx context.Context, request model.AttributeRequest, creatingUserId uint64) (*model.AttributeResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO attributes (name, created_user, created_at) VALUES (?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	attributeId, err := flows.PerformEdit(
+		ctx,
+		"CreateAttribute",
+		query,
+		repo.DB,
+		log,
+		request.Name, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.addValues(ctx, uint64(attributeId), request.Values, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(attributeId))
+}
+
+// Update renames the attribute and adds any values it does not have yet. Existing
+// values are kept since variants and order snapshots may point at them.
+func (repo *MySqlAttributeRepository) Update(ctx context.Context, attributeId uint64, request model.AttributeRequest, updatingUserId uint64) (*model.AttributeResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	attribute, err := repo.GetByID(ctx, attributeId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "UPDATE attributes SET name = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, attributeId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"UpdateAttribute",
+		query,
+		repo.DB,
+		log,
+		request.Name, updatingUserId, attributeId)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]bool, len(attribute.Values))
+	for _, value := range attribute.Values {
+		existing[value.Value] = true
+	}
+	missing := make([]string, 0)
+	for _, value := range request.Values {
+		if !existing[value] {
+			existing[value] = true
+			missing = append(missing, value)
+		}
+	}
+
+	err = repo.addValues(ctx, attributeId, missing, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), attributeId)
+}
+
+func (repo *MySqlAttributeRepository) Delete(ctx context.Context, attributeId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE attribute_values SET deleted_user = ?, deleted_at = now() WHERE attribute_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, attributeId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearAttributeValues",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, attributeId)
+	if err != nil {
+		return err
+	}
+
+	query = "UPDATE attributes SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, attributeId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"DeleteAttribute",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, attributeId)
+
+	return err
+}
+
+// InUse reports whether a live variant still carries one of the attribute's values.
+func (repo *MySqlAttributeRepository) InUse(ctx context.Context, attributeId uint64) (bool, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT EXISTS (SELECT 1 FROM variant_attribute_values vav JOIN attribute_values av ON av.id = vav.attribute_value_id JOIN product_variants v ON v.id = vav.variant_id WHERE av.attribute_id = ? AND vav.deleted_at IS NULL AND v.deleted_at IS NULL)"
+	stmt, err := flows.GetReaderStatement(ctx, "AttributeInUse", query, repo.DB, log)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, attributeId)
+
+	var inUse bool
+	err = stmt.QueryRow(attributeId).Scan(&inUse)
+	if err != nil {
+		return false, utils.QueryError("AttributeInUse", log, err)
+	}
+
+	return inUse, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/utils"
+)
+
+type AuditRepository interface {
+	Query(ctx context.Context, request model.AuditLogRequest) ([]model.AuditLogResponse, int, error)
+	Shutdown()
+}
+
+type MySqlAuditRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlAuditRepository(logger logger.Logger, db mysql.DbConnection) AuditRepository {
+	return &MySqlAuditRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlAuditRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close audit repo: %s", err.Error())
+	}
+}
+
+const auditColumns = "id, actor_user, entity, entity_id, action, changes, trace_id, source_ip, created_at"
+
+func auditFilter(request model.AuditLogRequest) (string, []any) {
+	conditions := make([]string, 0, 4)
+	args := make([]any, 0, 4)
+	if request.Entity != "" {
+		conditions = append(conditions, "entity = ?")
+		args = append(args, request.Entity)
+	}
+	if request.EntityID != nil {
+		conditions = append(conditions, "entity_id = ?")
+		args = append(args, *request.EntityID)
+	}
+	if request.ActorUser != nil {
+		conditions = append(conditions, "actor_user = ?")
+		args = append(args, *request.ActorUser)
+	}
+	if request.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, request.Action)
+	}
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// Query returns one page of audit entries, newest first, and how many entries
+// match in total. Page and PerPage are expected to be filled in by the caller.
+func (repo *MySqlAuditRepository) Query(ctx context.Context, request model.AuditLogRequest) ([]model.AuditLogResponse, int, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	where, args := auditFilter(request)
+
+	countQuery := "SELECT COUNT(*) FROM audit_logs" + where
+	countStmt, err := flows.GetReaderStatement(ctx, "CountAuditLogs", countQuery, repo.DB, log)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer countStmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", countQuery, args)
+
+	var total int
+	err = countStmt.QueryRow(args...).Scan(&total)
+	if err != nil {
+		return nil, 0, utils.QueryError("CountAuditLogs", log, err)
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit_logs" + where + " ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?"
+	args = append(args, request.PerPage, (request.Page-1)*request.PerPage)
+	stmt, err := flows.GetReaderStatement(ctx, "QueryAuditLogs", query, repo.DB, log)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, 0, utils.QueryError("QueryAuditLogs", log, err)
+	}
+	defer rows.Close()
+
+	entries := make([]model.AuditLogResponse, 0)
+	for rows.Next() {
+		var entry model.AuditLogResponse
+		var changes []byte
+		err = rows.Scan(&entry.ID, &entry.ActorUser, &entry.Entity, &entry.EntityID, &entry.Action, &changes, &entry.TraceID, &entry.SourceIP, &entry.CreatedAt)
+		if err != nil {
+			return nil, 0, utils.QueryError("QueryAuditLogs", log, err)
+		}
+		entry.Changes = changes
+		entries = append(entries, entry)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, utils.QueryError("QueryAuditLogs", log, err)
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type CategoryRepository interface {
+	GetAll(ctx context.Context) ([]model.CategoryResponse, error)
+	GetByID(ctx context.Context, categoryId uint64) (*model.CategoryResponse, error)
+	GetBySlug(ctx context.Context, slug string) (*model.CategoryResponse, error)
+	Create(ctx context.Context, request model.CategoryRequest, creatingUserId uint64) (*model.CategoryResponse, error)
+	Update(ctx context.Context, categoryId uint64, request model.CategoryRequest, updatingUserId uint64) (*model.CategoryResponse, error)
+	Delete(ctx context.Context, categoryId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type MySqlCategoryRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlCategoryRepository(logger logger.Logger, db mysql.DbConnection) CategoryRepository {
+	return &MySqlCategoryRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlCategoryRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close category repo: %s", err.Error())
+	}
+}
+
+const categoryColumns = "id, parent_id, name, slug, COALESCE(description, ''), created_user, created_at, updated_user, updated_at"
+
+type categoryScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlCategoryRepository) scanCategory(row categoryScanner) (*model.CategoryResponse, error) {
+	var category model.CategoryResponse
+	err := row.Scan(&category.ID, &category.ParentID, &category.Name, &category.Slug, &category.Description, &category.CreatedUser, &category.CreatedAt, &category.UpdatedUser, &category.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for category: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal category response: %s", err.Error())
+		return nil, err
+	}
+
+	return &category, nil
+}
+
+func (repo *MySqlCategoryRepository) getOne(ctx context.Context, queryName string, query string, arg any) (*model.CategoryResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, arg)
+
+	category, err := repo.scanCategory(stmt.QueryRow(arg))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	return category, nil
+}
+
+func (repo *MySqlCategoryRepository) GetAll(ctx context.Context) ([]model.CategoryResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + categoryColumns + " FROM categories WHERE deleted_at IS NULL ORDER BY name, id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetAllCategories", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s'", query)
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return nil, utils.QueryError("GetAllCategories", log, err)
+	}
+	defer rows.Close()
+
+	categories := make([]model.CategoryResponse, 0)
+	for rows.Next() {
+		category, err := repo.scanCategory(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetAllCategories", log, err)
+		}
+		categories = append(categories, *category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetAllCategories", log, err)
+	}
+
+	return categories, nil
+}
+
+func (repo *MySqlCategoryRepository) GetByID(ctx context.Context, categoryId uint64) (*model.CategoryResponse, error) {
+	query := "SELECT " + categoryColumns + " FROM categories WHERE id = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetCategoryByID", query, categoryId)
+}
+
+func (repo *MySqlCategoryRepository) GetBySlug(ctx context.Context, slug string) (*model.CategoryResponse, error) {
+	query := "SELECT " + categoryColumns + " FROM categories WHERE slug = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetCategoryBySlug", query, slug)
+}
+
+func (repo *MySqlCategoryRepository) Create(ctx context.Context, request model.CategoryRequest, creatingUserId uint64) (*model.CategoryResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO categories (parent_id, name, slug, description, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	categoryId, err := flows.PerformEdit(
+		ctx,
+		"CreateCategory",
+		query,
+		repo.DB,
+		log,
+		request.ParentID, request.Name, request.Slug, request.Description, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(categoryId))
+}
+
+func (repo *MySqlCategoryRepository) Update(ctx context.Context, categoryId uint64, request model.CategoryRequest, updatingUserId uint64) (*model.CategoryResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE categories SET parent_id = ?, name = ?, slug = ?, description = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, categoryId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateCategory",
+		query,
+		repo.DB,
+		log,
+		request.ParentID, request.Name, request.Slug, request.Description, updatingUserId, categoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), categoryId)
+}
+
+// Delete removes the category and unlinks its products. Callers make sure it has no
+// child categories first.
+func (repo *MySqlCategoryRepository) Delete(ctx context.Context, categoryId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE product_categories SET deleted_user = ?, deleted_at = now() WHERE category_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, categoryId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearCategoryProducts",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, categoryId)
+	if err != nil {
+		return err
+	}
+
+	query = "UPDATE categories SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, categoryId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"DeleteCategory",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, categoryId)
+
+	return err
+}
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/repository/mysql"
+)
+
+// afterWrite is the context to read a row back under once it has been written: the
+// reader may not have replicated it yet, so the read goes to the writer.
+func afterWrite(ctx context.Context) context.Context {
+	return mysql.WithConsistency(ctx, mysql.Strong)
+}
@@ -0,0 +1,371 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type DiscountRepository interface {
+	GetAll(ctx context.Context) ([]model.DiscountResponse, error)
+	GetByID(ctx context.Context, discountId uint64) (*model.DiscountResponse, error)
+	GetByCode(ctx context.Context, code string) (*model.DiscountResponse, error)
+	GetActivePromotions(ctx context.Context, now time.Time) ([]model.DiscountResponse, error)
+	GetUsage(ctx context.Context, discountId uint64, userId uint64, excludeOrderId uint64) (*model.DiscountUsage, error)
+	LockUsage(ctx context.Context, discountId uint64, userId uint64, excludeOrderId uint64) (*model.DiscountUsage, error)
+	Create(ctx context.Context, request model.DiscountRequest, creatingUserId uint64) (*model.DiscountResponse, error)
+	Update(ctx context.Context, discountId uint64, request model.DiscountRequest, updatingUserId uint64) (*model.DiscountResponse, error)
+	Delete(ctx context.Context, discountId uint64, deletingUserId uint64) error
+	ReplaceOrderDiscounts(ctx context.Context, orderId uint64, discounts []model.AppliedDiscount, updatingUserId uint64) error
+	WithTx(tx *Tx) DiscountRepository
+	Shutdown()
+}
+
+type MySqlDiscountRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlDiscountRepository(logger logger.Logger, db mysql.DbConnection) DiscountRepository {
+	return &MySqlDiscountRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// WithTx returns a copy of the repository running its statements in the unit of
+// work's transaction.
+func (repo *MySqlDiscountRepository) WithTx(tx *Tx) DiscountRepository {
+	return &MySqlDiscountRepository{
+		Logger: repo.Logger,
+		DB:     tx.conn,
+	}
+}
+
+func (repo *MySqlDiscountRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close discount repo: %s", err.Error())
+	}
+}
+
+const discountColumns = "id, code, description, discount_type_id, value, minimum_spend, global_usage_limit, per_user_usage_limit, starts_at, ends_at, created_user, created_at, updated_user, updated_at"
+
+type discountScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlDiscountRepository) scanDiscount(row discountScanner) (*model.DiscountResponse, error) {
+	var discount model.DiscountResponse
+	err := row.Scan(&discount.ID, &discount.Code, &discount.Description, &discount.DiscountTypeID, &discount.Value, &discount.MinimumSpend, &discount.GlobalUsageLimit, &discount.PerUserUsageLimit, &discount.StartsAt, &discount.EndsAt, &discount.CreatedUser, &discount.CreatedAt, &discount.UpdatedUser, &discount.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for discount: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal discount response: %s", err.Error())
+		return nil, err
+	}
+
+	return &discount, nil
+}
+
+func (repo *MySqlDiscountRepository) getProductIDs(ctx context.Context, discountId uint64) ([]uint64, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT product_id FROM discount_products WHERE discount_id = ? AND deleted_at IS NULL ORDER BY product_id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetDiscountProducts", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, discountId)
+
+	rows, err := stmt.Query(discountId)
+	if err != nil {
+		return nil, utils.QueryError("GetDiscountProducts", log, err)
+	}
+	defer rows.Close()
+
+	productIds := make([]uint64, 0)
+	for rows.Next() {
+		var productId uint64
+		err = rows.Scan(&productId)
+		if err != nil {
+			return nil, utils.QueryError("GetDiscountProducts", log, err)
+		}
+		productIds = append(productIds, productId)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetDiscountProducts", log, err)
+	}
+
+	return productIds, nil
+}
+
+func (repo *MySqlDiscountRepository) getOne(ctx context.Context, queryName string, query string, arg any) (*model.DiscountResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, arg)
+
+	discount, err := repo.scanDiscount(stmt.QueryRow(arg))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	discount.ProductIDs, err = repo.getProductIDs(ctx, discount.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return discount, nil
+}
+
+func (repo *MySqlDiscountRepository) getMany(ctx context.Context, queryName string, query string, args ...any) ([]model.DiscountResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+	defer rows.Close()
+
+	discounts := make([]model.DiscountResponse, 0)
+	for rows.Next() {
+		discount, err := repo.scanDiscount(rows)
+		if err != nil {
+			return nil, utils.QueryError(queryName, log, err)
+		}
+		discounts = append(discounts, *discount)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	for i := range discounts {
+		discounts[i].ProductIDs, err = repo.getProductIDs(ctx, discounts[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return discounts, nil
+}
+
+func (repo *MySqlDiscountRepository) GetAll(ctx context.Context) ([]model.DiscountResponse, error) {
+	query := "SELECT " + discountColumns + " FROM discounts WHERE deleted_at IS NULL ORDER BY id"
+	return repo.getMany(ctx, "GetAllDiscounts", query)
+}
+
+func (repo *MySqlDiscountRepository) GetByID(ctx context.Context, discountId uint64) (*model.DiscountResponse, error) {
+	query := "SELECT " + discountColumns + " FROM discounts WHERE id = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetDiscountByID", query, discountId)
+}
+
+func (repo *MySqlDiscountRepository) GetByCode(ctx context.Context, code string) (*model.DiscountResponse, error) {
+	query := "SELECT " + discountColumns + " FROM discounts WHERE code = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetDiscountByCode", query, code)
+}
+
+func (repo *MySqlDiscountRepository) GetActivePromotions(ctx context.Context, now time.Time) ([]model.DiscountResponse, error) {
+	query := "SELECT " + discountColumns + " FROM discounts WHERE code IS NULL AND deleted_at IS NULL AND (starts_at IS NULL OR starts_at <= ?) AND (ends_at IS NULL OR ends_at > ?) ORDER BY id"
+	return repo.getMany(ctx, "GetActivePromotions", query, now, now)
+}
+
+const discountUsageQuery = "SELECT COUNT(*), COALESCE(SUM(o.created_user = ?), 0) FROM order_discounts od JOIN orders o ON o.id = od.order_id WHERE od.discount_id = ? AND od.order_id <> ? AND od.deleted_at IS NULL AND o.deleted_at IS NULL AND o.status_id <> ?"
+
+func (repo *MySqlDiscountRepository) GetUsage(ctx context.Context, discountId uint64, userId uint64, excludeOrderId uint64) (*model.DiscountUsage, error) {
+	return repo.getUsage(ctx, "GetDiscountUsage", discountUsageQuery, discountId, userId, excludeOrderId)
+}
+
+// LockUsage counts the discount's usage for a checkout about to use it, so it must
+// run in a unit of work. It first locks the discount row, so checkouts using the
+// same discount wait for each other, and then counts with a locking read that sees
+// the orders committed meanwhile, so two checkouts cannot both take its last use.
+func (repo *MySqlDiscountRepository) LockUsage(ctx context.Context, discountId uint64, userId uint64, excludeOrderId uint64) (*model.DiscountUsage, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id FROM discounts WHERE id = ? FOR UPDATE"
+	stmt, err := flows.GetReaderStatement(ctx, "LockDiscount", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, discountId)
+
+	var lockedId uint64
+	err = stmt.QueryRow(discountId).Scan(&lockedId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Debugf("No result back for discount: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		return nil, utils.QueryError("LockDiscount", log, err)
+	}
+
+	return repo.getUsage(ctx, "LockDiscountUsage", discountUsageQuery+" FOR UPDATE", discountId, userId, excludeOrderId)
+}
+
+func (repo *MySqlDiscountRepository) getUsage(ctx context.Context, queryName string, query string, discountId uint64, userId uint64, excludeOrderId uint64) (*model.DiscountUsage, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d', '%d', '%d' and '%d'", query, userId, discountId, excludeOrderId, constant.ORDER_STATUS_CANCELLED)
+
+	var usage model.DiscountUsage
+	err = stmt.QueryRow(userId, discountId, excludeOrderId, constant.ORDER_STATUS_CANCELLED).Scan(&usage.Global, &usage.PerUser)
+	if err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	return &usage, nil
+}
+
+func (repo *MySqlDiscountRepository) replaceProducts(ctx context.Context, discountId uint64, productIds []uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE discount_products SET deleted_user = ?, deleted_at = now() WHERE discount_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, discountId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearDiscountProducts",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, discountId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO discount_products (discount_id, product_id, created_user, created_at) VALUES (?, ?, ?, now()) ON DUPLICATE KEY UPDATE deleted_user = NULL, deleted_at = NULL, updated_user = VALUES(created_user), updated_at = now()"
+	for _, productId := range productIds {
+		log.Debugf("Running query '%s' with parameter '%d', '%d' and '%d'", query, discountId, productId, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddDiscountProduct",
+			query,
+			repo.DB,
+			log,
+			discountId, productId, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlDiscountRepository) Create(ctx context.Context, request model.DiscountRequest, creatingUserId uint64) (*model.DiscountResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO discounts (code, description, discount_type_id, value, minimum_spend, global_usage_limit, per_user_usage_limit, starts_at, ends_at, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	discountId, err := flows.PerformEdit(
+		ctx,
+		"CreateDiscount",
+		query,
+		repo.DB,
+		log,
+		request.Code, request.Description, request.DiscountTypeID, request.Value, request.MinimumSpend, request.GlobalUsageLimit, request.PerUserUsageLimit, request.StartsAt, request.EndsAt, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceProducts(ctx, uint64(discountId), request.ProductIDs, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(discountId))
+}
+
+func (repo *MySqlDiscountRepository) Update(ctx context.Context, discountId uint64, request model.DiscountRequest, updatingUserId uint64) (*model.DiscountResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE discounts SET code = ?, description = ?, discount_type_id = ?, value = ?, minimum_spend = ?, global_usage_limit = ?, per_user_usage_limit = ?, starts_at = ?, ends_at = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, discountId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateDiscount",
+		query,
+		repo.DB,
+		log,
+		request.Code, request.Description, request.DiscountTypeID, request.Value, request.MinimumSpend, request.GlobalUsageLimit, request.PerUserUsageLimit, request.StartsAt, request.EndsAt, updatingUserId, discountId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceProducts(ctx, discountId, request.ProductIDs, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), discountId)
+}
+
+func (repo *MySqlDiscountRepository) Delete(ctx context.Context, discountId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE discounts SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, discountId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteDiscount",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, discountId)
+
+	return err
+}
+
+func (repo *MySqlDiscountRepository) ReplaceOrderDiscounts(ctx context.Context, orderId uint64, discounts []model.AppliedDiscount, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE order_discounts SET deleted_user = ?, deleted_at = now() WHERE order_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, orderId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearOrderDiscounts",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, orderId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO order_discounts (order_id, discount_id, code, discount_type_id, value, discount_amount, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, now())"
+	for _, discount := range discounts {
+		log.Debugf("Running query '%s' with parameter '%d', '%+v' and '%d'", query, orderId, discount, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddOrderDiscount",
+			query,
+			repo.DB,
+			log,
+			orderId, discount.DiscountID, discount.Code, discount.DiscountTypeID, discount.Value, discount.DiscountAmount, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -1,20 +1,105 @@
 package flows
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
 	"tannar.moss/backend/internal/repository/mysql"
-	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/utils"
 )
 
-func GetReaderStatement(queryName string, query string, conn mysql.DbConnection, logger logger.Logger) (*sql.Stmt, error) {
-	stmt, err := conn.GetReader().Prepare(query)
+// Statement is a prepared read whose queries run under the deadline it was
+// prepared with, so a cancelled request or an expired timeout reaches the driver.
+type Statement struct {
+	queryName string
+	stmt      *sql.Stmt
+	// owned is set when stmt is not shared through a statement registry, so Close
+	// must close it.
+	owned  bool
+	ctx    context.Context
+	cancel context.CancelFunc
+	span   *tracing.Span
+}
+
+func (s *Statement) QueryRow(args ...any) *sql.Row {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), s.queryName)
+	return s.stmt.QueryRowContext(s.ctx, args...)
+}
+
+func (s *Statement) Query(args ...any) (*sql.Rows, error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), s.queryName)
+	rows, err := s.stmt.QueryContext(s.ctx, args...)
+	s.span.RecordError(err)
+	return rows, err
+}
+
+// Close releases the statement and its deadline and ends its span. Statements from
+// the registry stay prepared for the next caller.
+func (s *Statement) Close() error {
+	defer s.span.End()
+	defer s.cancel()
+	if !s.owned {
+		return nil
+	}
+	return s.stmt.Close()
+}
+
+// GetReaderStatement prepares a read through the routed pool's statement registry,
+// so the query must be one of a fixed set of texts.
+func GetReaderStatement(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger) (*Statement, error) {
+	return getReaderStatement(ctx, queryName, query, conn, logger, true)
+}
+
+// GetDynamicReaderStatement prepares a read built at runtime, such as one with an IN
+// list sized by its arguments, outside the statement registry and closes it with the
+// Statement, so the registry does not keep a statement for every variant.
+func GetDynamicReaderStatement(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger) (*Statement, error) {
+	return getReaderStatement(ctx, queryName, query, conn, logger, false)
+}
+
+func getReaderStatement(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger, registered bool) (*Statement, error) {
+	spanCtx, span := startQuerySpan(ctx, queryName, query)
+	queryCtx, cancel := conn.WithTimeout(spanCtx)
+
+	if tx := conn.Tx(); tx != nil {
+		var stmt *sql.Stmt
+		var err error
+		if registered {
+			stmt, err = conn.WriterStatements().Prepare(queryCtx, query)
+			if err == nil {
+				stmt = tx.StmtContext(queryCtx, stmt)
+			}
+		} else {
+			stmt, err = tx.PrepareContext(queryCtx, query)
+		}
+		if err != nil {
+			cancel()
+			span.RecordError(err)
+			span.End()
+			return nil, utils.PrepareError(queryName, logger, err)
+		}
+		span.SetAttribute("db.route", "transaction")
+		return &Statement{queryName: queryName, stmt: stmt, owned: true, ctx: queryCtx, cancel: cancel, span: span}, nil
+	}
+
+	registry, route := conn.RouteRead(ctx)
+	logger.Debugf("Routing '%s' to the %s", queryName, route)
+	span.SetAttribute("db.route", route)
+	prepare := registry.Prepare
+	if !registered {
+		prepare = registry.PrepareUnregistered
+	}
+	stmt, err := prepare(queryCtx, query)
 	if err != nil {
-		utils.LogPreparingError(queryName, logger, err)
-		return nil, types.NewInternalServerError()
+		cancel()
+		span.RecordError(err)
+		span.End()
+		return nil, utils.PrepareError(queryName, logger, err)
 	}
 
-	return stmt, nil
+	return &Statement{queryName: queryName, stmt: stmt, owned: !registered, ctx: queryCtx, cancel: cancel, span: span}, nil
 }
@@ -0,0 +1,137 @@
+package flows
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"tannar.moss/backend/internal/audit"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+const auditInsert = "INSERT INTO audit_logs (actor_user, entity, entity_id, action, changes, trace_id, source_ip, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, now())"
+
+// PerformAuditedEdit runs an edit of a single row of entry.Entity and, in the same
+// transaction, records the columns it changed in audit_logs. The row is read before
+// and after the edit on the writer, so the diff sees exactly what was committed.
+func PerformAuditedEdit(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger, entry audit.Entry, args ...any) (_ int64, err error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), queryName)
+	ctx, span := startQuerySpan(ctx, queryName, query)
+	defer endQuerySpan(span, &err)
+	queryCtx, cancel := conn.WithTimeout(ctx)
+	defer cancel()
+
+	tx, owned, err := beginEdit(queryCtx, conn)
+	if err != nil {
+		return -1, utils.BeginError(queryName, logger, err)
+	}
+
+	var before map[string]any
+	if entry.EntityID != 0 {
+		before, err = selectAuditedRow(queryCtx, tx, entry.Entity, entry.EntityID, true)
+		if err != nil {
+			rollbackEdit(tx, owned)
+			return -1, utils.QueryError(queryName, logger, err)
+		}
+	}
+
+	preparedStmt, err := conn.WriterStatements().Prepare(queryCtx, query)
+	if err != nil {
+		rollbackEdit(tx, owned)
+		return -1, utils.PrepareError(queryName, logger, err)
+	}
+	txStmt := tx.StmtContext(queryCtx, preparedStmt)
+	defer txStmt.Close()
+
+	result, err := txStmt.ExecContext(queryCtx, args...)
+	if err != nil {
+		rollbackEdit(tx, owned)
+		return -1, utils.QueryError(queryName, logger, err)
+	}
+
+	id, _ := result.LastInsertId()
+	entityId := entry.EntityID
+	if entityId == 0 {
+		entityId = uint64(id)
+	}
+
+	after, err := selectAuditedRow(queryCtx, tx, entry.Entity, entityId, false)
+	if err != nil {
+		rollbackEdit(tx, owned)
+		return -1, utils.QueryError(queryName, logger, err)
+	}
+
+	changes := audit.Diff(before, after)
+	if len(changes) > 0 {
+		changesJson, err := json.Marshal(changes)
+		if err != nil {
+			logger.Errorf("Unabled to marshal audit changes for '%s' '%d': %s", entry.Entity, entityId, err.Error())
+			rollbackEdit(tx, owned)
+			return -1, types.NewInternalServerError()
+		}
+
+		traceId := audit.TraceID(logger)
+		logger.Debugf("Running query '%s' with parameter '%d', '%s', '%d', '%s', '%v', '%s' and '%s'", auditInsert, entry.Source.ActorID, entry.Entity, entityId, entry.Action, audit.Columns(changes), traceId, entry.Source.SourceIP)
+		_, err = tx.ExecContext(queryCtx, auditInsert, entry.Source.ActorID, entry.Entity, entityId, entry.Action, changesJson, traceId, entry.Source.SourceIP)
+		if err != nil {
+			rollbackEdit(tx, owned)
+			return -1, utils.QueryError("CreateAuditLog", logger, err)
+		}
+	}
+
+	err = commitEdit(tx, owned)
+	if err != nil {
+		utils.LogCommitError(queryName, logger, err)
+		return -1, types.NewInternalServerError()
+	}
+	if owned {
+		conn.RecordWrite(ctx)
+	}
+
+	return id, nil
+}
+
+// selectAuditedRow reads a whole row by id into a column map, or nil when there is
+// no such row. The entity is a table name chosen by the repository, never input.
+func selectAuditedRow(ctx context.Context, tx *sql.Tx, entity string, id uint64, lock bool) (map[string]any, error) {
+	query := "SELECT * FROM " + entity + " WHERE id = ?"
+	if lock {
+		query += " FOR UPDATE"
+	}
+
+	rows, err := tx.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	err = rows.Scan(pointers...)
+	if err != nil {
+		return nil, err
+	}
+
+	row := make(map[string]any, len(columns))
+	for i, column := range columns {
+		row[column] = values[i]
+	}
+
+	return row, rows.Err()
+}
@@ -0,0 +1,57 @@
+package flows
+
+import (
+	"context"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+// PerformConditionalEdit runs an edit whose WHERE clause may match nothing and returns
+// the number of rows affected, so callers can tell whether the condition held.
+func PerformConditionalEdit(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger, args ...any) (_ int64, err error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), queryName)
+	ctx, span := startQuerySpan(ctx, queryName, query)
+	defer endQuerySpan(span, &err)
+	queryCtx, cancel := conn.WithTimeout(ctx)
+	defer cancel()
+
+	tx, owned, err := beginEdit(queryCtx, conn)
+	if err != nil {
+		return -1, utils.BeginError(queryName, logger, err)
+	}
+
+	preparedStmt, err := conn.WriterStatements().Prepare(queryCtx, query)
+	if err != nil {
+		rollbackEdit(tx, owned)
+		return -1, utils.PrepareError(queryName, logger, err)
+	}
+	txStmt := tx.StmtContext(queryCtx, preparedStmt)
+	defer txStmt.Close()
+
+	result, err := txStmt.ExecContext(queryCtx, args...)
+	if err != nil {
+		rollbackEdit(tx, owned)
+		return -1, utils.QueryError(queryName, logger, err)
+	}
+
+	err = commitEdit(tx, owned)
+	if err != nil {
+		utils.LogCommitError(queryName, logger, err)
+		return -1, types.NewInternalServerError()
+	}
+	if owned {
+		conn.RecordWrite(ctx)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return -1, utils.QueryError(queryName, logger, err)
+	}
+
+	return affected, nil
+}
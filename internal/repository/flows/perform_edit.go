@@ -1,39 +1,50 @@
 package flows
 
 import (
+	"context"
+	"time"
+
 	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
 	"tannar.moss/backend/internal/repository/mysql"
 	"tannar.moss/backend/internal/types"
 	"tannar.moss/backend/internal/utils"
 )
 
-func PerformEdit(queryName string, query string, conn mysql.DbConnection, logger logger.Logger, args ...any) (int64, error) {
-	tx, err := conn.GetWriter().Begin()
+func PerformEdit(ctx context.Context, queryName string, query string, conn mysql.DbConnection, logger logger.Logger, args ...any) (_ int64, err error) {
+	defer metrics.DBQueryDuration.ObserveSince(time.Now(), queryName)
+	ctx, span := startQuerySpan(ctx, queryName, query)
+	defer endQuerySpan(span, &err)
+	queryCtx, cancel := conn.WithTimeout(ctx)
+	defer cancel()
+
+	tx, owned, err := beginEdit(queryCtx, conn)
 	if err != nil {
-		utils.LogPreparingError(queryName, logger, err)
-		return -1, types.NewInternalServerError()
+		return -1, utils.BeginError(queryName, logger, err)
 	}
 
-	preparedStmt, err := tx.Prepare(query)
+	preparedStmt, err := conn.WriterStatements().Prepare(queryCtx, query)
 	if err != nil {
-		utils.LogPreparingError(queryName, logger, err)
-		tx.Rollback()
-		return -1, types.NewInternalServerError()
+		rollbackEdit(tx, owned)
+		return -1, utils.PrepareError(queryName, logger, err)
 	}
-	defer preparedStmt.Close()
+	txStmt := tx.StmtContext(queryCtx, preparedStmt)
+	defer txStmt.Close()
 
-	result, err := preparedStmt.Exec(args...)
+	result, err := txStmt.ExecContext(queryCtx, args...)
 	if err != nil {
-		utils.LogExecutingError(queryName, logger, err)
-		tx.Rollback()
-		return -1, types.NewInternalServerError()
+		rollbackEdit(tx, owned)
+		return -1, utils.QueryError(queryName, logger, err)
 	}
 
-	err = tx.Commit()
+	err = commitEdit(tx, owned)
 	if err != nil {
 		utils.LogCommitError(queryName, logger, err)
 		return -1, types.NewInternalServerError()
 	}
+	if owned {
+		conn.RecordWrite(ctx)
+	}
 
 	id, _ := result.LastInsertId()
 
@@ -0,0 +1,23 @@
+package flows
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/tracing"
+)
+
+// startQuerySpan runs a query in a client span named after it, carrying the SQL so a
+// slow trace shows exactly what ran.
+func startQuerySpan(ctx context.Context, queryName string, query string) (context.Context, *tracing.Span) {
+	return tracing.Start(ctx, "SQL "+queryName, tracing.WithKind(tracing.SpanKindClient), tracing.WithAttributes(map[string]any{
+		"db.system":    "mysql",
+		"db.operation": queryName,
+		"db.statement": query,
+	}))
+}
+
+// endQuerySpan ends span, failed when *err is set; defer it with a named error result.
+func endQuerySpan(span *tracing.Span, err *error) {
+	span.RecordError(*err)
+	span.End()
+}
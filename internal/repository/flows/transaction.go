@@ -0,0 +1,36 @@
+package flows
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/repository/mysql"
+)
+
+// beginEdit returns the transaction an edit runs in. A connection bound to a unit
+// of work lends its transaction, which the unit of work commits or rolls back;
+// otherwise the edit begins, and owns, a transaction of its own.
+func beginEdit(ctx context.Context, conn mysql.DbConnection) (*sql.Tx, bool, error) {
+	if tx := conn.Tx(); tx != nil {
+		return tx, false, nil
+	}
+
+	tx, err := conn.GetWriter().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	return tx, true, nil
+}
+
+func rollbackEdit(tx *sql.Tx, owned bool) {
+	if owned {
+		tx.Rollback()
+	}
+}
+
+func commitEdit(tx *sql.Tx, owned bool) error {
+	if owned {
+		return tx.Commit()
+	}
+	return nil
+}
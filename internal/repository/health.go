@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/utils"
+)
+
+type HealthRepository interface {
+	PingReader(ctx context.Context) error
+	PingWriter(ctx context.Context) error
+	GetSchemaVersion(ctx context.Context) (string, error)
+	Shutdown()
+}
+
+type MySqlHealthRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlHealthRepository(logger logger.Logger, db mysql.DbConnection) HealthRepository {
+	return &MySqlHealthRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlHealthRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close health repo: %s", err.Error())
+	}
+}
+
+func (repo *MySqlHealthRepository) PingReader(ctx context.Context) error {
+	return repo.DB.PingReader(ctx)
+}
+
+func (repo *MySqlHealthRepository) PingWriter(ctx context.Context) error {
+	return repo.DB.PingWriter(ctx)
+}
+
+// GetSchemaVersion returns the latest applied database script, read from the writer
+// so a lagging replica does not report an older version. It is empty when none is
+// recorded.
+func (repo *MySqlHealthRepository) GetSchemaVersion(ctx context.Context) (string, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT MAX(version) FROM schema_migrations"
+	stmt, err := flows.GetReaderStatement(mysql.WithConsistency(ctx, mysql.Strong), "GetSchemaVersion", query, repo.DB, log)
+	if err != nil {
+		return "", err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s'", query)
+
+	var version sql.NullString
+	err = stmt.QueryRow().Scan(&version)
+	if err != nil {
+		return "", utils.QueryError("GetSchemaVersion", log, err)
+	}
+
+	return version.String, nil
+}
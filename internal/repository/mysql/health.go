@@ -0,0 +1,102 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+)
+
+const maxRetryBackoff = 30 * time.Second
+
+// RetryBackoff is how long to wait after the given failed connection attempt,
+// counting from zero: base, doubling each attempt, capped at 30 seconds.
+func RetryBackoff(base time.Duration, attempt int) time.Duration {
+	backoff := base
+	for i := 0; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+	return backoff
+}
+
+// connectWithRetry pings db until it answers or ConnectRetries retries have failed.
+func connectWithRetry(role string, db *sql.DB, dbConfig DatabaseConfig, logger logger.Logger) error {
+	base := time.Duration(orDefault(dbConfig.RetryBackoffMillis, defaultRetryBackoffMillis)) * time.Millisecond
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = ping(db, dbConfig)
+		if err == nil {
+			return nil
+		}
+		if attempt >= dbConfig.ConnectRetries {
+			return err
+		}
+
+		backoff := RetryBackoff(base, attempt)
+		logger.Errorf("Unabled to reach database %s '%s', retrying in %s: %s", role, dbConfig.Host, backoff, err.Error())
+		time.Sleep(backoff)
+	}
+}
+
+func ping(db *sql.DB, dbConfig DatabaseConfig) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(dbConfig.ConnectionTimeout)*time.Second)
+	defer cancel()
+	return db.PingContext(ctx)
+}
+
+// readerHealth is the reader's last known health, kept current by a background ping.
+type readerHealth struct {
+	healthy atomic.Bool
+
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+func newReaderHealth() *readerHealth {
+	return &readerHealth{done: make(chan struct{})}
+}
+
+// start pings db every HealthCheckInterval until stop, logging each change of health.
+func (h *readerHealth) start(db *sql.DB, dbConfig DatabaseConfig, logger logger.Logger) {
+	interval := time.Duration(orDefault(dbConfig.HealthCheckInterval, defaultHealthCheckInterval)) * time.Second
+
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-h.done:
+				return
+			case <-ticker.C:
+				err := ping(db, dbConfig)
+				healthy := err == nil
+				if h.healthy.Swap(healthy) == healthy {
+					continue
+				}
+				if healthy {
+					logger.Infof("Reader '%s' recovered, serving reads from it again", dbConfig.Host)
+				} else {
+					logger.Errorf("Reader '%s' unhealthy, serving reads from the writer: %s", dbConfig.Host, err.Error())
+				}
+			}
+		}
+	}()
+}
+
+func (h *readerHealth) stop() {
+	h.stopOnce.Do(func() {
+		close(h.done)
+		h.wg.Wait()
+	})
+}
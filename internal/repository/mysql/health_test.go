@@ -0,0 +1,65 @@
+package mysql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository/mysql"
+)
+
+// downDriver refuses every connection, like a replica that is unreachable.
+type downDriver struct{}
+
+func (downDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestRetryBackoff_withAttempts_shouldDoubleUpToCap(t *testing.T) {
+	base := 500 * time.Millisecond
+	expected := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second}
+	for attempt, expect := range expected {
+		result := mysql.RetryBackoff(base, attempt)
+		if result != expect {
+			t.Errorf("RetryBackoff test failed for attempt %d, expected[%s], got[%s]", attempt, expect, result)
+		}
+	}
+
+	result := mysql.RetryBackoff(base, 20)
+	if result != 30*time.Second {
+		t.Errorf("RetryBackoff test failed, expected[30s], got[%s]", result)
+	}
+}
+
+func TestNewDbConnection_withReaderDown_shouldServeReadsFromWriter(t *testing.T) {
+	sql.Register("stub-writer", &stubDriver{})
+	sql.Register("down-reader", downDriver{})
+
+	writerConfig := mysql.DatabaseConfig{Dialect: "stub-writer", ConnectionTimeout: 1}
+	readerConfig := mysql.DatabaseConfig{Dialect: "down-reader", ConnectionTimeout: 1, ConnectRetries: 1, RetryBackoffMillis: 1}
+	conn, err := mysql.NewDbConnection(writerConfig, readerConfig, logger.NewSimpleLogger("ERROR", false))
+	if err != nil {
+		t.Fatalf("NewDbConnection test failed, expected to start without the reader, got[%v]", err)
+	}
+	defer conn.Close()
+
+	if conn.ReaderHealthy() {
+		t.Errorf("ReaderHealthy test failed, expected[false], got[true]")
+	}
+	if conn.GetReader() != conn.GetWriter() {
+		t.Errorf("GetReader test failed, expected the writer while the reader is down")
+	}
+}
+
+func TestNewDbConnection_withWriterDown_shouldFail(t *testing.T) {
+	sql.Register("down-writer", downDriver{})
+
+	writerConfig := mysql.DatabaseConfig{Dialect: "down-writer", ConnectionTimeout: 1, RetryBackoffMillis: 1}
+	_, err := mysql.NewDbConnection(writerConfig, writerConfig, logger.NewSimpleLogger("ERROR", false))
+	if err == nil {
+		t.Errorf("NewDbConnection test failed, expected an error")
+	}
+}
@@ -0,0 +1,60 @@
+package mysql
+
+import (
+	"database/sql"
+
+	"tannar.moss/backend/internal/metrics"
+)
+
+// RegisterMetrics reports the reader and writer pool stats and the statement
+// registry counts on registry, read at every scrape. Register one connection per
+// registry.
+func (db *DbConnection) RegisterMetrics(registry *metrics.Registry) {
+	pools := func(value func(stats sql.DBStats) float64) metrics.CollectFunc {
+		return func() []metrics.Sample {
+			return []metrics.Sample{
+				{LabelValues: []string{"reader"}, Value: value(db.readerDB.Stats())},
+				{LabelValues: []string{"writer"}, Value: value(db.writerDB.Stats())},
+			}
+		}
+	}
+	registry.NewGaugeFunc("db_max_open_connections", "Maximum open connections allowed, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.MaxOpenConnections) }), "pool")
+	registry.NewGaugeFunc("db_open_connections", "Open connections, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.OpenConnections) }), "pool")
+	registry.NewGaugeFunc("db_in_use_connections", "Connections running a query, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.InUse) }), "pool")
+	registry.NewGaugeFunc("db_idle_connections", "Idle connections, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.Idle) }), "pool")
+	registry.NewCounterFunc("db_wait_count_total", "Times a query waited for a free connection, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.WaitCount) }), "pool")
+	registry.NewCounterFunc("db_wait_duration_seconds_total", "Time spent waiting for a free connection, by pool.",
+		pools(func(stats sql.DBStats) float64 { return stats.WaitDuration.Seconds() }), "pool")
+	registry.NewCounterFunc("db_max_idle_closed_total", "Connections closed for exceeding the idle pool size, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.MaxIdleClosed) }), "pool")
+	registry.NewCounterFunc("db_max_lifetime_closed_total", "Connections closed for exceeding their lifetime, by pool.",
+		pools(func(stats sql.DBStats) float64 { return float64(stats.MaxLifetimeClosed) }), "pool")
+
+	statements := func(value func(stats StatementStats) float64) metrics.CollectFunc {
+		return func() []metrics.Sample {
+			return []metrics.Sample{
+				{LabelValues: []string{"reader"}, Value: value(db.readerStatements.Stats())},
+				{LabelValues: []string{"writer"}, Value: value(db.writerStatements.Stats())},
+			}
+		}
+	}
+	registry.NewGaugeFunc("db_prepared_statements", "Statements held by the statement registry, by pool.",
+		statements(func(stats StatementStats) float64 { return float64(stats.Prepared) }), "pool")
+	registry.NewCounterFunc("db_statement_cache_hits_total", "Statements reused from the statement registry, by pool.",
+		statements(func(stats StatementStats) float64 { return float64(stats.Hits) }), "pool")
+	registry.NewCounterFunc("db_statement_prepare_failures_total", "Statements that failed to prepare, by pool.",
+		statements(func(stats StatementStats) float64 { return float64(stats.PrepareFailures) }), "pool")
+	registry.NewGaugeFunc("db_reader_healthy", "1 while reads go to the reader, 0 while they fall back to the writer.",
+		func() []metrics.Sample {
+			healthy := 0.0
+			if db.reader.healthy.Load() {
+				healthy = 1
+			}
+			return []metrics.Sample{{Value: healthy}}
+		})
+}
@@ -1,10 +1,14 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
+	"tannar.moss/backend/internal/logger"
 )
 
 type DatabaseConfig struct {
@@ -16,8 +20,31 @@ type DatabaseConfig struct {
 	Database          string
 	Username          string
 	Password          string
+	// Pool sizing and connection lifetimes, in seconds. Zero keeps the defaults of
+	// 3 open and 1 idle connection, reused forever.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime int
+	ConnMaxIdleTime int
+	// ConnectRetries is how many more times a failed startup ping is retried,
+	// waiting RetryBackoffMillis and doubling after each attempt.
+	ConnectRetries     int
+	RetryBackoffMillis int
+	// HealthCheckInterval is how often, in seconds, the reader is pinged to decide
+	// whether reads go to it or to the writer. Defaults to 10.
+	HealthCheckInterval int
+	// SessionWindow is how long, in seconds, Session reads stay on the writer after
+	// their user writes. Defaults to 5, which should exceed the usual replica lag.
+	SessionWindow int
 }
 
+const (
+	defaultMaxOpenConns        = 3
+	defaultMaxIdleConns        = 1
+	defaultRetryBackoffMillis  = 500
+	defaultHealthCheckInterval = 10
+)
+
 type MySql interface {
 	Ping() error
 	Close() error
@@ -29,28 +56,71 @@ type MySql interface {
 type DbConnection struct {
 	readerDB *sql.DB
 	writerDB *sql.DB
+	// requestTimeout bounds every query, see WithTimeout.
+	requestTimeout time.Duration
+	// readerStatements and writerStatements are shared by every copy of the
+	// connection, see Statements.
+	readerStatements *StatementRegistry
+	writerStatements *StatementRegistry
+	// reader tracks whether reads can go to readerDB, see GetReader. It is shared by
+	// every copy of the connection.
+	reader *readerHealth
+	// sessions records recent writes per user for Session reads, see RouteRead.
+	sessions *writeSessions
+	// tx is set on copies of the connection bound to a unit of work, see Begin.
+	tx *sql.Tx
 }
 
-func NewDbConnection(writerCreds, readerCreds DatabaseConfig) (*DbConnection, error) {
-	writerDB, err := createConnection(writerCreds)
+// NewDbConnection opens the writer and reader pools, retrying a failed startup ping
+// with backoff. The writer must come up; a reader that does not is left to the
+// background health check and reads go to the writer until it recovers.
+func NewDbConnection(writerCreds, readerCreds DatabaseConfig, logger logger.Logger) (*DbConnection, error) {
+	writerDB, err := openPool(writerCreds)
 	if err != nil {
 		return nil, err
 	}
-	readerDB, err := createConnection(readerCreds)
+	err = connectWithRetry("writer", writerDB, writerCreds, logger)
 	if err != nil {
+		writerDB.Close()
 		return nil, err
 	}
-	return &DbConnection{readerDB: readerDB, writerDB: writerDB}, nil
-}
 
-func createConnection(dbConfig DatabaseConfig) (*sql.DB, error) {
+	readerDB, err := openPool(readerCreds)
+	if err != nil {
+		writerDB.Close()
+		return nil, err
+	}
+
+	conn := &DbConnection{
+		readerDB:         readerDB,
+		writerDB:         writerDB,
+		requestTimeout:   time.Duration(writerCreds.RequestTimeout) * time.Second,
+		readerStatements: NewStatementRegistry(readerDB),
+		writerStatements: NewStatementRegistry(writerDB),
+		reader:           newReaderHealth(),
+		sessions:         newWriteSessions(time.Duration(orDefault(writerCreds.SessionWindow, defaultSessionWindow)) * time.Second),
+	}
+
+	err = connectWithRetry("reader", readerDB, readerCreds, logger)
+	if err != nil {
+		logger.Errorf("Reader unavailable, serving reads from the writer: %s", err.Error())
+	}
+	conn.reader.healthy.Store(err == nil)
+	conn.reader.start(readerDB, readerCreds, logger)
 
-	connString := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true",
+	return conn, nil
+}
+
+// openPool creates the pool for dbConfig without connecting; sql.Open only dials on
+// first use.
+func openPool(dbConfig DatabaseConfig) (*sql.DB, error) {
+	connString := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&multiStatements=true&timeout=%ds",
 		dbConfig.Username,
 		dbConfig.Password,
 		dbConfig.Host,
 		dbConfig.Port,
 		dbConfig.Database,
+		dbConfig.ConnectionTimeout,
 	)
 
 	db, err := sql.Open(dbConfig.Dialect, connString)
@@ -58,32 +128,111 @@ func createConnection(dbConfig DatabaseConfig) (*sql.DB, error) {
 		return nil, err
 	}
 
-	db.SetMaxIdleConns(1)
-	db.SetMaxOpenConns(3)
-
-	err = db.Ping()
-	if err != nil {
-		return nil, err
-	}
+	db.SetMaxOpenConns(orDefault(dbConfig.MaxOpenConns, defaultMaxOpenConns))
+	db.SetMaxIdleConns(orDefault(dbConfig.MaxIdleConns, defaultMaxIdleConns))
+	db.SetConnMaxLifetime(time.Duration(dbConfig.ConnMaxLifetime) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(dbConfig.ConnMaxIdleTime) * time.Second)
 
 	return db, nil
 }
 
+func orDefault(value int, fallback int) int {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// GetReader returns the reader pool, or the writer while the reader is unhealthy.
 func (db *DbConnection) GetReader() *sql.DB {
+	if !db.reader.healthy.Load() {
+		return db.writerDB
+	}
 	return db.readerDB
 }
 
+// ReaderHealthy reports whether reads currently go to the reader.
+func (db *DbConnection) ReaderHealthy() bool {
+	return db.reader.healthy.Load()
+}
+
 func (db *DbConnection) GetWriter() *sql.DB {
 	return db.writerDB
 }
 
-func (db *DbConnection) Close() error {
-	var err = db.readerDB.Close()
+// Begin starts a transaction on the writer and returns a copy of the connection
+// bound to it. Statements run through the copy, reads included, see the writes made
+// earlier in the transaction.
+// The transaction is rolled back if ctx is cancelled before it commits.
+func (db *DbConnection) Begin(ctx context.Context) (*DbConnection, error) {
+	tx, err := db.writerDB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	bound := *db
+	bound.tx = tx
+	return &bound, nil
+}
+
+// WithTimeout derives the context a single query runs under, ending at the
+// configured RequestTimeout or at the deadline ctx already has, whichever is first.
+func (db *DbConnection) WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.requestTimeout <= 0 {
+		return context.WithCancel(ctx)
 	}
+	return context.WithTimeout(ctx, db.requestTimeout)
+}
+
+// Tx returns the transaction the connection is bound to, or nil outside a unit of
+// work.
+func (db *DbConnection) Tx() *sql.Tx {
+	return db.tx
+}
+
+// ReaderStatements returns the registry of statements prepared on the reader, or
+// the writer's while the reader is unhealthy.
+func (db *DbConnection) ReaderStatements() *StatementRegistry {
+	if !db.reader.healthy.Load() {
+		return db.writerStatements
+	}
+	return db.readerStatements
+}
+
+// WriterStatements returns the registry of statements prepared on the writer. A
+// connection bound to a unit of work runs them through its transaction with
+// tx.StmtContext.
+func (db *DbConnection) WriterStatements() *StatementRegistry {
+	return db.writerStatements
+}
+
+// StatementStats sums the reader and writer statement registry stats.
+func (db *DbConnection) StatementStats() StatementStats {
+	reader := db.readerStatements.Stats()
+	writer := db.writerStatements.Stats()
+	return StatementStats{
+		Prepared:        reader.Prepared + writer.Prepared,
+		Hits:            reader.Hits + writer.Hits,
+		Misses:          reader.Misses + writer.Misses,
+		PrepareFailures: reader.PrepareFailures + writer.PrepareFailures,
+	}
+}
+
+// Close stops the reader health check and closes the prepared statements and then
+// both pools.
+func (db *DbConnection) Close() error {
+	db.reader.stop()
+	err := errors.Join(db.readerStatements.Close(), db.writerStatements.Close())
+
+	return errors.Join(err, db.readerDB.Close(), db.writerDB.Close())
+}
+
+// PingReader pings the reader itself, even while reads are served from the writer.
+func (db *DbConnection) PingReader(ctx context.Context) error {
+	return db.readerDB.PingContext(ctx)
+}
 
-	return db.writerDB.Close()
+func (db *DbConnection) PingWriter(ctx context.Context) error {
+	return db.writerDB.PingContext(ctx)
 }
 
 func (db *DbConnection) Ping() error {
@@ -0,0 +1,125 @@
+package mysql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Consistency is how fresh a read must be, and so which pool it is routed to.
+type Consistency string
+
+const (
+	// Eventual reads go to the reader and may trail recent writes by the replica lag.
+	Eventual Consistency = "eventual"
+	// Session reads go to the writer for SessionWindow after the session's user last
+	// wrote, so they see their own writes, and to the reader otherwise.
+	Session Consistency = "session"
+	// Strong reads always go to the writer.
+	Strong Consistency = "strong"
+)
+
+const defaultSessionWindow = 5
+
+type consistencyKey struct{}
+
+type sessionKey struct{}
+
+// WithConsistency returns a context whose reads are routed for consistency.
+func WithConsistency(ctx context.Context, consistency Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, consistency)
+}
+
+// WithSession returns a context whose writes are recorded against userId and whose
+// reads default to Session consistency.
+func WithSession(ctx context.Context, userId uint64) context.Context {
+	return context.WithValue(ctx, sessionKey{}, userId)
+}
+
+func consistencyOf(ctx context.Context) (Consistency, uint64, bool) {
+	userId, hasSession := ctx.Value(sessionKey{}).(uint64)
+	consistency, ok := ctx.Value(consistencyKey{}).(Consistency)
+	if !ok {
+		consistency = Eventual
+		if hasSession {
+			consistency = Session
+		}
+	}
+	return consistency, userId, hasSession
+}
+
+// RouteRead returns the statement registry a read under ctx runs on, and why it was
+// chosen, for the debug log.
+func (db *DbConnection) RouteRead(ctx context.Context) (*StatementRegistry, string) {
+	if !db.reader.healthy.Load() {
+		return db.writerStatements, "writer, reader unhealthy"
+	}
+
+	consistency, userId, hasSession := consistencyOf(ctx)
+	switch consistency {
+	case Strong:
+		return db.writerStatements, "writer, strong consistency"
+	case Session:
+		if !hasSession {
+			return db.readerStatements, "reader, session consistency without a session"
+		}
+		if since, pinned := db.sessions.sinceWrite(userId, time.Now()); pinned {
+			return db.writerStatements, fmt.Sprintf("writer, session consistency, user '%d' wrote %s ago", userId, since.Round(time.Millisecond))
+		}
+		return db.readerStatements, fmt.Sprintf("reader, session consistency, user '%d' has no recent write", userId)
+	default:
+		return db.readerStatements, "reader, eventual consistency"
+	}
+}
+
+// RecordWrite pins the reads of the session in ctx, if any, to the writer for the
+// session window. Call it once a write is committed.
+func (db *DbConnection) RecordWrite(ctx context.Context) {
+	if userId, ok := ctx.Value(sessionKey{}).(uint64); ok {
+		db.sessions.record(userId, time.Now())
+	}
+}
+
+// writeSessions remembers when each user last wrote, forgetting writes older than
+// the window. It is kept in memory, so sessions are only pinned on the instance that
+// took the write.
+type writeSessions struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	lastWrite map[uint64]time.Time
+	lastSweep time.Time
+}
+
+func newWriteSessions(window time.Duration) *writeSessions {
+	return &writeSessions{window: window, lastWrite: map[uint64]time.Time{}}
+}
+
+func (s *writeSessions) record(userId uint64, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastWrite[userId] = now
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	for id, wrote := range s.lastWrite {
+		if now.Sub(wrote) >= s.window {
+			delete(s.lastWrite, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+func (s *writeSessions) sinceWrite(userId uint64, now time.Time) (time.Duration, bool) {
+	s.mu.Lock()
+	wrote, ok := s.lastWrite[userId]
+	s.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+
+	since := now.Sub(wrote)
+	return since, since < s.window
+}
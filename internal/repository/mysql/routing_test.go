@@ -0,0 +1,60 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository/mysql"
+)
+
+func openRouted(t *testing.T, name string) *mysql.DbConnection {
+	sql.Register(name+"-writer", &stubDriver{})
+	sql.Register(name+"-reader", &stubDriver{})
+
+	writerConfig := mysql.DatabaseConfig{Dialect: name + "-writer", ConnectionTimeout: 1}
+	readerConfig := mysql.DatabaseConfig{Dialect: name + "-reader", ConnectionTimeout: 1}
+	conn, err := mysql.NewDbConnection(writerConfig, readerConfig, logger.NewSimpleLogger("ERROR", false))
+	if err != nil {
+		t.Fatalf("NewDbConnection failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestRouteRead_withConsistency_shouldPickPool(t *testing.T) {
+	conn := openRouted(t, "stub-routing")
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected *mysql.StatementRegistry
+	}{
+		{"eventual", context.Background(), conn.ReaderStatements()},
+		{"strong", mysql.WithConsistency(context.Background(), mysql.Strong), conn.WriterStatements()},
+		{"session without a write", mysql.WithSession(context.Background(), 7), conn.ReaderStatements()},
+		{"strong within a session", mysql.WithConsistency(mysql.WithSession(context.Background(), 7), mysql.Strong), conn.WriterStatements()},
+	}
+	for _, test := range tests {
+		registry, route := conn.RouteRead(test.ctx)
+		if registry != test.expected {
+			t.Errorf("RouteRead test failed for %s, got route[%s]", test.name, route)
+		}
+	}
+}
+
+func TestRouteRead_withSessionAfterWrite_shouldPinOnlyThatUserToWriter(t *testing.T) {
+	conn := openRouted(t, "stub-session")
+	conn.RecordWrite(mysql.WithSession(context.Background(), 7))
+
+	registry, route := conn.RouteRead(mysql.WithSession(context.Background(), 7))
+	if registry != conn.WriterStatements() {
+		t.Errorf("RouteRead test failed for the writing user, got route[%s]", route)
+	}
+
+	registry, route = conn.RouteRead(mysql.WithSession(context.Background(), 8))
+	if registry != conn.ReaderStatements() {
+		t.Errorf("RouteRead test failed for another user, got route[%s]", route)
+	}
+}
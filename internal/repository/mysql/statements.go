@@ -0,0 +1,132 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+var errStatementsClosed = errors.New("statement registry is closed")
+
+// StatementStats counts how a StatementRegistry has been used since it was created.
+type StatementStats struct {
+	Prepared        int
+	Hits            uint64
+	Misses          uint64
+	PrepareFailures uint64
+}
+
+// StatementRegistry prepares each query once per connection pool and hands the same
+// *sql.Stmt to every later caller, until Close. Statements are keyed by their query
+// text, so two query names sharing a query share a statement, and are never evicted,
+// so queries whose text is built per call go through PrepareUnregistered instead.
+// The statements belong to the registry and must not be closed by callers.
+type StatementRegistry struct {
+	db *sql.DB
+
+	mu         sync.RWMutex
+	statements map[string]*sql.Stmt
+	closed     bool
+
+	hits            atomic.Uint64
+	misses          atomic.Uint64
+	prepareFailures atomic.Uint64
+}
+
+func NewStatementRegistry(db *sql.DB) *StatementRegistry {
+	return &StatementRegistry{
+		db:         db,
+		statements: map[string]*sql.Stmt{},
+	}
+}
+
+// Prepare returns the statement for query, preparing it on first use. Callers racing
+// on a new query may both prepare it; the loser's statement is closed.
+func (r *StatementRegistry) Prepare(ctx context.Context, query string) (*sql.Stmt, error) {
+	r.mu.RLock()
+	stmt, ok := r.statements[query]
+	closed := r.closed
+	r.mu.RUnlock()
+	if ok {
+		r.hits.Add(1)
+		return stmt, nil
+	}
+	if closed {
+		r.prepareFailures.Add(1)
+		return nil, errStatementsClosed
+	}
+
+	r.misses.Add(1)
+	prepared, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.prepareFailures.Add(1)
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		prepared.Close()
+		r.prepareFailures.Add(1)
+		return nil, errStatementsClosed
+	}
+	if stmt, ok := r.statements[query]; ok {
+		prepared.Close()
+		return stmt, nil
+	}
+	r.statements[query] = prepared
+	return prepared, nil
+}
+
+// PrepareUnregistered prepares query on the registry's pool without keeping it, for
+// queries such as IN lists whose text changes with their arguments and would
+// otherwise grow the registry without bound. The caller owns and must close the
+// statement.
+func (r *StatementRegistry) PrepareUnregistered(ctx context.Context, query string) (*sql.Stmt, error) {
+	r.mu.RLock()
+	closed := r.closed
+	r.mu.RUnlock()
+	if closed {
+		r.prepareFailures.Add(1)
+		return nil, errStatementsClosed
+	}
+
+	r.misses.Add(1)
+	prepared, err := r.db.PrepareContext(ctx, query)
+	if err != nil {
+		r.prepareFailures.Add(1)
+		return nil, err
+	}
+	return prepared, nil
+}
+
+func (r *StatementRegistry) Stats() StatementStats {
+	r.mu.RLock()
+	prepared := len(r.statements)
+	r.mu.RUnlock()
+
+	return StatementStats{
+		Prepared:        prepared,
+		Hits:            r.hits.Load(),
+		Misses:          r.misses.Load(),
+		PrepareFailures: r.prepareFailures.Load(),
+	}
+}
+
+// Close closes every statement and refuses to prepare new ones.
+func (r *StatementRegistry) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var errs []error
+	for query, stmt := range r.statements {
+		if err := stmt.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		delete(r.statements, query)
+	}
+	r.closed = true
+	return errors.Join(errs...)
+}
@@ -0,0 +1,128 @@
+package mysql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"tannar.moss/backend/internal/repository/mysql"
+)
+
+// stubDriver prepares any query except those containing FAIL, and counts the
+// statements it prepares and closes.
+type stubDriver struct {
+	prepared atomic.Int64
+	closed   atomic.Int64
+}
+
+type stubConn struct{ driver *stubDriver }
+
+type stubStmt struct{ driver *stubDriver }
+
+func (d *stubDriver) Open(name string) (driver.Conn, error) { return &stubConn{driver: d}, nil }
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "FAIL") {
+		return nil, errors.New("syntax error")
+	}
+	c.driver.prepared.Add(1)
+	return &stubStmt{driver: c.driver}, nil
+}
+func (c *stubConn) Close() error              { return nil }
+func (c *stubConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (s *stubStmt) Close() error  { s.driver.closed.Add(1); return nil }
+func (s *stubStmt) NumInput() int { return -1 }
+func (s *stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, errors.New("not supported")
+}
+
+func openStub(t *testing.T, name string) (*stubDriver, *sql.DB) {
+	stub := &stubDriver{}
+	sql.Register(name, stub)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatalf("Error opening stub database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return stub, db
+}
+
+func TestStatementRegistry_withRepeatedQuery_shouldPrepareOnceAndCountHits(t *testing.T) {
+	stub, db := openStub(t, "stub-repeated")
+	registry := mysql.NewStatementRegistry(db)
+
+	first, err := registry.Prepare(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatalf("Prepare failed: %v", err)
+	}
+	second, _ := registry.Prepare(context.Background(), "SELECT 1")
+	if first != second {
+		t.Errorf("Prepare test failed, expected the cached statement to be reused")
+	}
+
+	stats := registry.Stats()
+	if stats.Prepared != 1 || stats.Hits != 1 || stats.Misses != 1 || stub.prepared.Load() != 1 {
+		t.Errorf("Stats test failed, expected[1 prepared, 1 hit, 1 miss], got[%+v] with %d driver prepares", stats, stub.prepared.Load())
+	}
+}
+
+func TestStatementRegistry_withFailingQuery_shouldCountFailureAndNotCache(t *testing.T) {
+	_, db := openStub(t, "stub-failing")
+	registry := mysql.NewStatementRegistry(db)
+
+	_, err := registry.Prepare(context.Background(), "SELECT FAIL")
+	if err == nil {
+		t.Fatalf("Prepare test failed, expected an error")
+	}
+
+	stats := registry.Stats()
+	if stats.Prepared != 0 || stats.PrepareFailures != 1 {
+		t.Errorf("Stats test failed, expected[0 prepared, 1 failure], got[%+v]", stats)
+	}
+}
+
+func TestStatementRegistry_withClose_shouldCloseStatementsAndRefuseNewOnes(t *testing.T) {
+	stub, db := openStub(t, "stub-close")
+	registry := mysql.NewStatementRegistry(db)
+	registry.Prepare(context.Background(), "SELECT 1")
+	registry.Prepare(context.Background(), "SELECT 2")
+
+	err := registry.Close()
+	if err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if stub.closed.Load() != 2 {
+		t.Errorf("Close test failed, expected[2] driver statements closed, got[%d]", stub.closed.Load())
+	}
+
+	_, err = registry.Prepare(context.Background(), "SELECT 3")
+	if err == nil {
+		t.Errorf("Prepare test failed, expected an error after Close")
+	}
+}
+
+func TestStatementRegistry_withUnregisteredQuery_shouldPrepareEachTimeAndNotCache(t *testing.T) {
+	stub, db := openStub(t, "stub-unregistered")
+	registry := mysql.NewStatementRegistry(db)
+
+	for i := 0; i < 2; i++ {
+		stmt, err := registry.PrepareUnregistered(context.Background(), "SELECT 1 FROM t WHERE id IN (?, ?)")
+		if err != nil {
+			t.Fatalf("PrepareUnregistered failed: %v", err)
+		}
+		stmt.Close()
+	}
+
+	stats := registry.Stats()
+	if stats.Prepared != 0 || stats.Misses != 2 || stub.prepared.Load() != 2 || stub.closed.Load() != 2 {
+		t.Errorf("Stats test failed, expected[0 prepared, 2 misses, 2 driver prepares and closes], got[%+v] with %d prepares and %d closes", stats, stub.prepared.Load(), stub.closed.Load())
+	}
+}
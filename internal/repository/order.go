@@ -0,0 +1,438 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type OrderRepository interface {
+	GetByID(ctx context.Context, orderId uint64) (*model.OrderResponse, error)
+	Create(ctx context.Context, request model.OrderRequest, items []model.OrderItemResponse, subtotal money.Money, creatingUserId uint64) (*model.OrderResponse, error)
+	UpdateStatus(ctx context.Context, orderId uint64, fromStatusId uint64, statusId uint64, updatingUserId uint64) (*model.OrderResponse, error)
+	StreamForExport(ctx context.Context, filter model.OrderExportRequest, handle func(model.OrderExportRow) error) error
+	UpdateTotals(ctx context.Context, orderId uint64, subtotal money.Money, discountTotal money.Money, taxTotal money.Money, total money.Money, updatingUserId uint64) (*model.OrderResponse, error)
+	UpdateItemTax(ctx context.Context, itemTax model.ItemTax, updatingUserId uint64) error
+	UpdateShipping(ctx context.Context, orderId uint64, shippingRateId uint64, shippingTotal money.Money, updatingUserId uint64) (*model.OrderResponse, error)
+	GetDeliveryDetails(ctx context.Context, deliveryDetailsId uint64) (*model.DeliveryDetailsResponse, error)
+	UpdateDeliverySlot(ctx context.Context, deliveryDetailsId uint64, deliverySlotId uint64, desiredTime time.Time, updatingUserId uint64) error
+	WithTx(tx *Tx) OrderRepository
+	Shutdown()
+}
+
+type MySqlOrderRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlOrderRepository(logger logger.Logger, db mysql.DbConnection) OrderRepository {
+	return &MySqlOrderRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// WithTx returns a copy of the repository running its statements in the unit of
+// work's transaction.
+func (repo *MySqlOrderRepository) WithTx(tx *Tx) OrderRepository {
+	return &MySqlOrderRepository{
+		Logger: repo.Logger,
+		DB:     tx.conn,
+	}
+}
+
+func (repo *MySqlOrderRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close order repo: %s", err.Error())
+	}
+}
+
+func (repo *MySqlOrderRepository) mapStatementToOrder(row *sql.Row) (*model.OrderResponse, error) {
+	var order model.OrderResponse
+	if row.Err() != nil {
+		return nil, row.Err()
+	}
+	err := row.Scan(&order.ID, &order.FirstName, &order.LastName, &order.Email, &order.StatusID, &order.DeliveryDetailsID, &order.Subtotal, &order.DiscountTotal, &order.TaxTotal, &order.ShippingRateID, &order.ShippingTotal, &order.Total, &order.CreatedUser, &order.CreatedAt, &order.UpdatedUser, &order.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for order: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal order response: %s", err.Error())
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+func (repo *MySqlOrderRepository) mapRowsToOrderItems(rows *sql.Rows) ([]model.OrderItemResponse, error) {
+	items := make([]model.OrderItemResponse, 0)
+	for rows.Next() {
+		var item model.OrderItemResponse
+		var variantAttributes sql.NullString
+		err := rows.Scan(&item.ID, &item.OrderID, &item.ProductID, &item.VariantID, &item.SKU, &item.ProductTitle, &variantAttributes, &item.Price, &item.Quantity, &item.DiscountAmount, &item.TaxClassID, &item.TaxRate, &item.TaxAmount, &item.PricesIncludeTax, &item.CreatedUser, &item.CreatedAt, &item.UpdatedUser, &item.UpdatedAt)
+		if err != nil {
+			repo.Logger.Errorf("Unabled to marshal order item response: %s", err.Error())
+			return nil, err
+		}
+		item.VariantAttributes = make([]model.VariantAttribute, 0)
+		if variantAttributes.Valid {
+			err = json.Unmarshal([]byte(variantAttributes.String), &item.VariantAttributes)
+			if err != nil {
+				repo.Logger.Errorf("Unabled to unmarshal variant attributes of order item '%d': %s", item.ID, err.Error())
+				return nil, err
+			}
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+func (repo *MySqlOrderRepository) getItemsByOrderID(ctx context.Context, orderId uint64) ([]model.OrderItemResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id, order_id, product_id, variant_id, sku, product_title, variant_attributes, price, quantity, discount_amount, tax_class_id, tax_rate, tax_amount, prices_include_tax, created_user, created_at, updated_user, updated_at FROM order_items WHERE order_id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetOrderItems", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, orderId)
+
+	rows, err := stmt.Query(orderId)
+	if err != nil {
+		return nil, utils.QueryError("GetOrderItems", log, err)
+	}
+	defer rows.Close()
+
+	items, err := repo.mapRowsToOrderItems(rows)
+	if err != nil {
+		return nil, utils.QueryError("GetOrderItems", log, err)
+	}
+
+	return items, nil
+}
+
+func (repo *MySqlOrderRepository) getDiscountsByOrderID(ctx context.Context, orderId uint64) ([]model.AppliedDiscount, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT discount_id, code, discount_type_id, value, discount_amount FROM order_discounts WHERE order_id = ? AND deleted_at IS NULL ORDER BY id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetOrderDiscounts", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, orderId)
+
+	rows, err := stmt.Query(orderId)
+	if err != nil {
+		return nil, utils.QueryError("GetOrderDiscounts", log, err)
+	}
+	defer rows.Close()
+
+	discounts := make([]model.AppliedDiscount, 0)
+	for rows.Next() {
+		var discount model.AppliedDiscount
+		err = rows.Scan(&discount.DiscountID, &discount.Code, &discount.DiscountTypeID, &discount.Value, &discount.DiscountAmount)
+		if err != nil {
+			return nil, utils.QueryError("GetOrderDiscounts", log, err)
+		}
+		discounts = append(discounts, discount)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetOrderDiscounts", log, err)
+	}
+
+	return discounts, nil
+}
+
+func (repo *MySqlOrderRepository) GetByID(ctx context.Context, orderId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id, first_name, last_name, email, status_id, delivery_details_id, subtotal, discount_total, tax_total, shipping_rate_id, shipping_total, total, created_user, created_at, updated_user, updated_at FROM orders WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetOrderByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, orderId)
+
+	order, err := repo.mapStatementToOrder(stmt.QueryRow(orderId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetOrderByID", log, err)
+	}
+
+	order.Items, err = repo.getItemsByOrderID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	order.Discounts, err = repo.getDiscountsByOrderID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// Create inserts the delivery details, the order and a snapshot of each item. The
+// order starts out awaiting payment with only its subtotal filled in.
+func (repo *MySqlOrderRepository) Create(ctx context.Context, request model.OrderRequest, items []model.OrderItemResponse, subtotal money.Money, creatingUserId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	details := request.DeliveryDetails
+	query := "INSERT INTO delivery_details (street_number, street_name, complex_name, area_name, city, country, notes, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, details, creatingUserId)
+	deliveryDetailsId, err := flows.PerformEdit(
+		ctx,
+		"CreateDeliveryDetails",
+		query,
+		repo.DB,
+		log,
+		details.StreetNumber, details.StreetName, details.ComplexName, details.AreaName, details.City, details.Country, details.Notes, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	query = "INSERT INTO orders (first_name, last_name, email, status_id, delivery_details_id, subtotal, total, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%s', '%s', '%s', '%d', '%d', '%s' and '%d'", query, request.FirstName, request.LastName, request.Email, constant.ORDER_STATUS_AWAITING_PAYMENT, deliveryDetailsId, subtotal, creatingUserId)
+	orderId, err := flows.PerformEdit(
+		ctx,
+		"CreateOrder",
+		query,
+		repo.DB,
+		log,
+		request.FirstName, request.LastName, request.Email, constant.ORDER_STATUS_AWAITING_PAYMENT, deliveryDetailsId, subtotal, subtotal, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	query = "INSERT INTO order_items (order_id, product_id, variant_id, sku, product_title, variant_attributes, price, quantity, tax_class_id, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, now())"
+	for _, item := range items {
+		var variantAttributes *string
+		if len(item.VariantAttributes) > 0 {
+			encoded, err := json.Marshal(item.VariantAttributes)
+			if err != nil {
+				log.Errorf("Unabled to marshal variant attributes for order '%d': %s", orderId, err.Error())
+				return nil, types.NewInternalServerError()
+			}
+			value := string(encoded)
+			variantAttributes = &value
+		}
+
+		log.Debugf("Running query '%s' with parameter '%d', '%+v' and '%d'", query, orderId, item, creatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"CreateOrderItem",
+			query,
+			repo.DB,
+			log,
+			orderId, item.ProductID, item.VariantID, item.SKU, item.ProductTitle, variantAttributes, item.Price, item.Quantity, item.TaxClassID, creatingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(orderId))
+}
+
+// UpdateStatus moves the order from fromStatusId to statusId, failing with a bad
+// request when it has already left fromStatusId so two racing transitions cannot
+// both go through.
+func (repo *MySqlOrderRepository) UpdateStatus(ctx context.Context, orderId uint64, fromStatusId uint64, statusId uint64, updatingUserId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE orders SET status_id = ?, updated_user = ?, updated_at = now() WHERE id = ? AND status_id = ?"
+	log.Debugf("Running query '%s' with parameter '%d', '%d', '%d' and '%d'", query, statusId, updatingUserId, orderId, fromStatusId)
+	affected, err := flows.PerformConditionalEdit(
+		ctx,
+		"UpdateOrderStatus",
+		query,
+		repo.DB,
+		log,
+		statusId, updatingUserId, orderId, fromStatusId)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		log.Infof("Order '%d' is no longer in status '%d'", orderId, fromStatusId)
+		return nil, types.NewBadRequestError()
+	}
+
+	return repo.GetByID(afterWrite(ctx), orderId)
+}
+
+func (repo *MySqlOrderRepository) UpdateTotals(ctx context.Context, orderId uint64, subtotal money.Money, discountTotal money.Money, taxTotal money.Money, total money.Money, updatingUserId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE orders SET subtotal = ?, discount_total = ?, tax_total = ?, total = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%s', '%s', '%s', '%s', '%d' and '%d'", query, subtotal, discountTotal, taxTotal, total, updatingUserId, orderId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateOrderTotals",
+		query,
+		repo.DB,
+		log,
+		subtotal, discountTotal, taxTotal, total, updatingUserId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), orderId)
+}
+
+func (repo *MySqlOrderRepository) UpdateItemTax(ctx context.Context, itemTax model.ItemTax, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE order_items SET discount_amount = ?, tax_class_id = ?, tax_rate = ?, tax_amount = ?, prices_include_tax = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, itemTax, updatingUserId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateOrderItemTax",
+		query,
+		repo.DB,
+		log,
+		itemTax.DiscountAmount, itemTax.TaxClassID, itemTax.TaxRate, itemTax.TaxAmount, itemTax.PricesIncludeTax, updatingUserId, itemTax.OrderItemID)
+
+	return err
+}
+
+func (repo *MySqlOrderRepository) GetDeliveryDetails(ctx context.Context, deliveryDetailsId uint64) (*model.DeliveryDetailsResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id, street_number, street_name, complex_name, area_name, city, country, delivery_slot_id, desired_time, notes, fullfilled_time, created_user, created_at, updated_user, updated_at FROM delivery_details WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetDeliveryDetails", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, deliveryDetailsId)
+
+	var details model.DeliveryDetailsResponse
+	err = stmt.QueryRow(deliveryDetailsId).Scan(&details.ID, &details.StreetNumber, &details.StreetName, &details.ComplexName, &details.AreaName, &details.City, &details.Country, &details.DeliverySlotID, &details.DesiredTime, &details.Notes, &details.FullfilledTime, &details.CreatedUser, &details.CreatedAt, &details.UpdatedUser, &details.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Debugf("No result back for delivery details: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		return nil, utils.QueryError("GetDeliveryDetails", log, err)
+	}
+
+	return &details, nil
+}
+
+func (repo *MySqlOrderRepository) UpdateShipping(ctx context.Context, orderId uint64, shippingRateId uint64, shippingTotal money.Money, updatingUserId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	// total is assigned first so it still sees the previous shipping_total
+	query := "UPDATE orders SET total = total - shipping_total + ?, shipping_rate_id = ?, shipping_total = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%s', '%d', '%s', '%d' and '%d'", query, shippingTotal, shippingRateId, shippingTotal, updatingUserId, orderId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateOrderShipping",
+		query,
+		repo.DB,
+		log,
+		shippingTotal, shippingRateId, shippingTotal, updatingUserId, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), orderId)
+}
+
+func (repo *MySqlOrderRepository) UpdateDeliverySlot(ctx context.Context, deliveryDetailsId uint64, deliverySlotId uint64, desiredTime time.Time, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE delivery_details SET delivery_slot_id = ?, desired_time = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%d', '%s', '%d' and '%d'", query, deliverySlotId, desiredTime, updatingUserId, deliveryDetailsId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateDeliverySlot",
+		query,
+		repo.DB,
+		log,
+		deliverySlotId, desiredTime, updatingUserId, deliveryDetailsId)
+
+	return err
+}
+
+// StreamForExport hands each order line in the filter to handle as it is read, so an
+// export over a large date range never holds the full result in memory.
+func (repo *MySqlOrderRepository) StreamForExport(ctx context.Context, filter model.OrderExportRequest, handle func(model.OrderExportRow) error) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT o.id, o.created_at, o.status_id, s.name, o.first_name, o.last_name, o.email, o.subtotal, o.discount_total, o.tax_total, o.shipping_total, o.total, i.id, i.product_id, i.product_title, i.price, i.quantity, i.discount_amount, i.tax_rate, i.tax_amount, i.prices_include_tax FROM orders o JOIN order_status_types s ON s.id = o.status_id LEFT JOIN order_items i ON i.order_id = o.id AND i.deleted_at IS NULL WHERE o.deleted_at IS NULL AND o.created_at >= ? AND o.created_at < ?"
+	args := []any{filter.From, filter.To}
+	if len(filter.StatusIDs) > 0 {
+		query += " AND o.status_id IN (?" + strings.Repeat(", ?", len(filter.StatusIDs)-1) + ")"
+		for _, statusId := range filter.StatusIDs {
+			args = append(args, statusId)
+		}
+	}
+	query += " ORDER BY o.id, i.id"
+
+	stmt, err := flows.GetDynamicReaderStatement(ctx, "StreamOrdersForExport", query, repo.DB, log)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return utils.QueryError("StreamOrdersForExport", log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row model.OrderExportRow
+		var itemId, productId, quantity *uint64
+		var productTitle *string
+		var taxRate *money.Decimal
+		var pricesIncludeTax *bool
+		var price, discountAmount, taxAmount money.Money
+		err = rows.Scan(&row.Order.ID, &row.Order.CreatedAt, &row.Order.StatusID, &row.StatusName, &row.Order.FirstName, &row.Order.LastName, &row.Order.Email, &row.Order.Subtotal, &row.Order.DiscountTotal, &row.Order.TaxTotal, &row.Order.ShippingTotal, &row.Order.Total, &itemId, &productId, &productTitle, &price, &quantity, &discountAmount, &taxRate, &taxAmount, &pricesIncludeTax)
+		if err != nil {
+			return utils.QueryError("StreamOrdersForExport", log, err)
+		}
+
+		if itemId != nil {
+			row.Item = &model.OrderItemResponse{
+				ID:             *itemId,
+				OrderID:        row.Order.ID,
+				ProductID:      productId,
+				Price:          price,
+				DiscountAmount: discountAmount,
+				TaxAmount:      taxAmount,
+			}
+			if productTitle != nil {
+				row.Item.ProductTitle = *productTitle
+			}
+			if quantity != nil {
+				row.Item.Quantity = *quantity
+			}
+			if taxRate != nil {
+				row.Item.TaxRate = *taxRate
+			}
+			if pricesIncludeTax != nil {
+				row.Item.PricesIncludeTax = *pricesIncludeTax
+			}
+		}
+
+		err = handle(row)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return utils.QueryError("StreamOrdersForExport", log, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type ProductRepository interface {
+	GetAll(ctx context.Context) ([]model.ProductResponse, error)
+	GetByID(ctx context.Context, productId uint64) (*model.ProductResponse, error)
+	GetBySKU(ctx context.Context, sku string) (*model.ProductResponse, error)
+	Create(ctx context.Context, request model.ProductRequest, creatingUserId uint64) (*model.ProductResponse, error)
+	Update(ctx context.Context, productId uint64, request model.ProductRequest, updatingUserId uint64) (*model.ProductResponse, error)
+	Delete(ctx context.Context, productId uint64, deletingUserId uint64) error
+	ReserveStock(ctx context.Context, productId uint64, variantId *uint64, quantity uint64, updatingUserId uint64) error
+	RestoreStock(ctx context.Context, productId uint64, variantId *uint64, quantity uint64, updatingUserId uint64) error
+	GetVariants(ctx context.Context, productId uint64) ([]model.VariantResponse, error)
+	GetVariantByID(ctx context.Context, variantId uint64) (*model.VariantResponse, error)
+	GetVariantBySKU(ctx context.Context, sku string) (*model.VariantResponse, error)
+	CreateVariant(ctx context.Context, productId uint64, request model.VariantRequest, creatingUserId uint64) (*model.VariantResponse, error)
+	UpdateVariant(ctx context.Context, variantId uint64, request model.VariantRequest, updatingUserId uint64) (*model.VariantResponse, error)
+	DeleteVariant(ctx context.Context, variantId uint64, deletingUserId uint64) error
+	WithTx(tx *Tx) ProductRepository
+	Shutdown()
+}
+
+type MySqlProductRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlProductRepository(logger logger.Logger, db mysql.DbConnection) ProductRepository {
+	return &MySqlProductRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// WithTx returns a copy of the repository running its statements in the unit of
+// work's transaction.
+func (repo *MySqlProductRepository) WithTx(tx *Tx) ProductRepository {
+	return &MySqlProductRepository{
+		Logger: repo.Logger,
+		DB:     tx.conn,
+	}
+}
+
+func (repo *MySqlProductRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close product repo: %s", err.Error())
+	}
+}
+
+// productCategoryIDs lists the live categories of the product in the outer query.
+const productCategoryIDs = "(SELECT GROUP_CONCAT(pc.category_id ORDER BY pc.category_id) FROM product_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.product_id = products.id AND pc.deleted_at IS NULL AND c.deleted_at IS NULL)"
+
+// productRatings averages and counts the approved reviews of the product in the
+// outer query. Its review statuses are bound first, see productArgs.
+const productRatings = "(SELECT ROUND(AVG(r.rating), 2) FROM reviews r WHERE r.product_id = products.id AND r.status_id = ? AND r.deleted_at IS NULL), (SELECT COUNT(*) FROM reviews r WHERE r.product_id = products.id AND r.status_id = ? AND r.deleted_at IS NULL)"
+
+const productColumns = "id, sku, title, description, price, stock, tax_class_id, weight, " + productCategoryIDs + ", " + productRatings + ", created_user, created_at, updated_user, updated_at"
+
+// productArgs puts the parameters of productColumns ahead of the query's own.
+func productArgs(args ...any) []any {
+	return append([]any{constant.REVIEW_STATUS_APPROVED, constant.REVIEW_STATUS_APPROVED}, args...)
+}
+
+type productScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlProductRepository) mapStatementToProduct(row productScanner) (*model.ProductResponse, error) {
+	var product model.ProductResponse
+	var categoryIds sql.NullString
+	err := row.Scan(&product.ID, &product.SKU, &product.Title, &product.Description, &product.Price, &product.Stock, &product.TaxClassID, &product.Weight, &categoryIds, &product.RatingAverage, &product.RatingCount, &product.CreatedUser, &product.CreatedAt, &product.UpdatedUser, &product.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for product: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal product response: %s", err.Error())
+		return nil, err
+	}
+	product.CategoryIDs, err = utils.ParseIDList(categoryIds.String)
+	if err != nil {
+		repo.Logger.Errorf("Unabled to marshal product categories: %s", err.Error())
+		return nil, err
+	}
+	product.Pictures = make([]string, 0)
+
+	return &product, nil
+}
+
+// fillPictures loads the product level pictures, leaving out those that belong to a
+// variant, for all the given products in a single query.
+func (repo *MySqlProductRepository) fillPictures(ctx context.Context, products []model.ProductResponse) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	if len(products) == 0 {
+		return nil
+	}
+
+	byId := make(map[uint64]*model.ProductResponse, len(products))
+	args := make([]any, 0, len(products))
+	for i := range products {
+		byId[products[i].ID] = &products[i]
+		args = append(args, products[i].ID)
+	}
+
+	query := "SELECT product_id, picture_url FROM pictures WHERE variant_id IS NULL AND deleted_at IS NULL AND product_id IN (?" + strings.Repeat(", ?", len(args)-1) + ") ORDER BY id"
+	stmt, err := flows.GetDynamicReaderStatement(ctx, "GetProductPictures", query, repo.DB, log)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return utils.QueryError("GetProductPictures", log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productId uint64
+		var pictureUrl string
+		err = rows.Scan(&productId, &pictureUrl)
+		if err != nil {
+			return utils.QueryError("GetProductPictures", log, err)
+		}
+		if product, ok := byId[productId]; ok {
+			product.Pictures = append(product.Pictures, pictureUrl)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return utils.QueryError("GetProductPictures", log, err)
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) GetAll(ctx context.Context) ([]model.ProductResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + productColumns + " FROM products WHERE deleted_at IS NULL ORDER BY id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetAllProducts", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	args := productArgs()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError("GetAllProducts", log, err)
+	}
+	defer rows.Close()
+
+	products := make([]model.ProductResponse, 0)
+	for rows.Next() {
+		product, err := repo.mapStatementToProduct(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetAllProducts", log, err)
+		}
+		products = append(products, *product)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetAllProducts", log, err)
+	}
+
+	err = repo.fillPictures(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (repo *MySqlProductRepository) getOne(ctx context.Context, queryName string, query string, arg any) (*model.ProductResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, arg)
+
+	product, err := repo.mapStatementToProduct(stmt.QueryRow(productArgs(arg)...))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	products := []model.ProductResponse{*product}
+	err = repo.fillPictures(ctx, products)
+	if err != nil {
+		return nil, err
+	}
+
+	return &products[0], nil
+}
+
+func (repo *MySqlProductRepository) GetByID(ctx context.Context, productId uint64) (*model.ProductResponse, error) {
+	query := "SELECT " + productColumns + " FROM products WHERE id = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetProductByID", query, productId)
+}
+
+func (repo *MySqlProductRepository) GetBySKU(ctx context.Context, sku string) (*model.ProductResponse, error) {
+	query := "SELECT " + productColumns + " FROM products WHERE sku = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetProductBySKU", query, sku)
+}
+
+func (repo *MySqlProductRepository) replaceCategories(ctx context.Context, productId uint64, categoryIds []uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE product_categories SET deleted_user = ?, deleted_at = now() WHERE product_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, productId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearProductCategories",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, productId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO product_categories (product_id, category_id, created_user, created_at) VALUES (?, ?, ?, now()) ON DUPLICATE KEY UPDATE deleted_user = NULL, deleted_at = NULL, updated_user = VALUES(created_user), updated_at = now()"
+	for _, categoryId := range categoryIds {
+		log.Debugf("Running query '%s' with parameter '%d', '%d' and '%d'", query, productId, categoryId, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddProductCategory",
+			query,
+			repo.DB,
+			log,
+			productId, categoryId, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) replacePictures(ctx context.Context, productId uint64, pictures []string, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE pictures SET deleted_user = ?, deleted_at = now() WHERE product_id = ? AND variant_id IS NULL AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, productId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearProductPictures",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, productId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO pictures (picture_url, product_id, created_user, created_at) VALUES (?, ?, ?, now())"
+	for _, pictureUrl := range pictures {
+		log.Debugf("Running query '%s' with parameter '%s', '%d' and '%d'", query, pictureUrl, productId, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddProductPicture",
+			query,
+			repo.DB,
+			log,
+			pictureUrl, productId, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) Create(ctx context.Context, request model.ProductRequest, creatingUserId uint64) (*model.ProductResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO products (sku, title, description, price, stock, tax_class_id, weight, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	productId, err := flows.PerformEdit(
+		ctx,
+		"CreateProduct",
+		query,
+		repo.DB,
+		log,
+		request.SKU, request.Title, request.Description, request.Price, request.Stock, request.TaxClassID, request.Weight, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceCategories(ctx, uint64(productId), request.CategoryIDs, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replacePictures(ctx, uint64(productId), request.Pictures, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(productId))
+}
+
+func (repo *MySqlProductRepository) Update(ctx context.Context, productId uint64, request model.ProductRequest, updatingUserId uint64) (*model.ProductResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE products SET sku = ?, title = ?, description = ?, price = ?, stock = ?, tax_class_id = ?, weight = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, productId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateProduct",
+		query,
+		repo.DB,
+		log,
+		request.SKU, request.Title, request.Description, request.Price, request.Stock, request.TaxClassID, request.Weight, updatingUserId, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceCategories(ctx, productId, request.CategoryIDs, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replacePictures(ctx, productId, request.Pictures, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), productId)
+}
+
+func (repo *MySqlProductRepository) Delete(ctx context.Context, productId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE products SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, productId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteProduct",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, productId)
+
+	return err
+}
+
+// stockTarget picks the row that holds the stock: the variant when one is given,
+// otherwise the product itself.
+func stockTarget(productId uint64, variantId *uint64) (string, uint64) {
+	if variantId != nil {
+		return "product_variants", *variantId
+	}
+	return "products", productId
+}
+
+func (repo *MySqlProductRepository) ReserveStock(ctx context.Context, productId uint64, variantId *uint64, quantity uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	table, id := stockTarget(productId, variantId)
+	query := "UPDATE " + table + " SET stock = stock - ?, updated_user = ?, updated_at = now() WHERE id = ? AND stock >= ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d', '%d', '%d' and '%d'", query, quantity, updatingUserId, id, quantity)
+	affected, err := flows.PerformConditionalEdit(
+		ctx,
+		"ReserveStock",
+		query,
+		repo.DB,
+		log,
+		quantity, updatingUserId, id, quantity)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		log.Infof("Not enough stock on %s '%d' for quantity '%d'", table, id, quantity)
+		return types.NewBadRequestError()
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) RestoreStock(ctx context.Context, productId uint64, variantId *uint64, quantity uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	table, id := stockTarget(productId, variantId)
+	query := "UPDATE " + table + " SET stock = stock + ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%d', '%d' and '%d'", query, quantity, updatingUserId, id)
+	_, err := flows.PerformEdit(
+		ctx,
+		"RestoreStock",
+		query,
+		repo.DB,
+		log,
+		quantity, updatingUserId, id)
+
+	return err
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type ProductImportRepository interface {
+	GetByID(ctx context.Context, importId uint64) (*model.ProductImportResponse, error)
+	Create(ctx context.Context, totalRows uint64, creatingUserId uint64) (*model.ProductImportResponse, error)
+	SaveProgress(ctx context.Context, report model.ProductImportResponse, updatingUserId uint64) error
+	Shutdown()
+}
+
+type MySqlProductImportRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlProductImportRepository(logger logger.Logger, db mysql.DbConnection) ProductImportRepository {
+	return &MySqlProductImportRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlProductImportRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close product import repo: %s", err.Error())
+	}
+}
+
+func (repo *MySqlProductImportRepository) GetByID(ctx context.Context, importId uint64) (*model.ProductImportResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id, status, total_rows, processed_rows, created_count, updated_count, failed_count, errors, finished_at, created_user, created_at, updated_user, updated_at FROM product_imports WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetProductImportByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, importId)
+
+	var report model.ProductImportResponse
+	var rowErrors sql.NullString
+	err = stmt.QueryRow(importId).Scan(&report.ID, &report.Status, &report.TotalRows, &report.ProcessedRows, &report.Created, &report.Updated, &report.Failed, &rowErrors, &report.FinishedAt, &report.CreatedUser, &report.CreatedAt, &report.UpdatedUser, &report.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Debugf("No result back for product import: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		return nil, utils.QueryError("GetProductImportByID", log, err)
+	}
+
+	report.Errors = make([]model.ImportRowError, 0)
+	if rowErrors.Valid {
+		err = json.Unmarshal([]byte(rowErrors.String), &report.Errors)
+		if err != nil {
+			log.Errorf("Unabled to unmarshal errors of product import '%d': %s", importId, err.Error())
+			return nil, types.NewInternalServerError()
+		}
+	}
+
+	return &report, nil
+}
+
+func (repo *MySqlProductImportRepository) Create(ctx context.Context, totalRows uint64, creatingUserId uint64) (*model.ProductImportResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO product_imports (status, total_rows, created_user, created_at) VALUES (?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%s', '%d' and '%d'", query, constant.IMPORT_STATUS_QUEUED, totalRows, creatingUserId)
+	importId, err := flows.PerformEdit(
+		ctx,
+		"CreateProductImport",
+		query,
+		repo.DB,
+		log,
+		constant.IMPORT_STATUS_QUEUED, totalRows, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(importId))
+}
+
+// SaveProgress stores the counts and row errors of the report, stamping finished_at
+// once the import has completed or failed.
+func (repo *MySqlProductImportRepository) SaveProgress(ctx context.Context, report model.ProductImportResponse, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	rowErrors, err := json.Marshal(report.Errors)
+	if err != nil {
+		log.Errorf("Unabled to marshal errors of product import '%d': %s", report.ID, err.Error())
+		return types.NewInternalServerError()
+	}
+
+	query := "UPDATE product_imports SET status = ?, processed_rows = ?, created_count = ?, updated_count = ?, failed_count = ?, errors = ?, finished_at = IF(? IN (?, ?), now(), NULL), updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, report, updatingUserId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"SaveProductImportProgress",
+		query,
+		repo.DB,
+		log,
+		report.Status, report.ProcessedRows, report.Created, report.Updated, report.Failed, string(rowErrors), report.Status, constant.IMPORT_STATUS_COMPLETE, constant.IMPORT_STATUS_FAILED, updatingUserId, report.ID)
+
+	return err
+}
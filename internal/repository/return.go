@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type ReturnRepository interface {
+	GetByID(ctx context.Context, returnId uint64) (*model.ReturnResponse, error)
+	GetByOrderID(ctx context.Context, orderId uint64) ([]model.ReturnResponse, error)
+	GetReturnedQuantities(ctx context.Context, orderId uint64) (map[uint64]uint64, error)
+	Create(ctx context.Context, orderId uint64, statusId uint64, reason string, items []model.ReturnItemResponse, creatingUserId uint64) (*model.ReturnResponse, error)
+	UpdateStatus(ctx context.Context, returnId uint64, fromStatusId uint64, statusId uint64, paymentReference *string, updatingUserId uint64) (*model.ReturnResponse, error)
+	WithTx(tx *Tx) ReturnRepository
+	Shutdown()
+}
+
+type MySqlReturnRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlReturnRepository(logger logger.Logger, db mysql.DbConnection) ReturnRepository {
+	return &MySqlReturnRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// WithTx returns a copy of the repository running its statements in the unit of
+// work's transaction.
+func (repo *MySqlReturnRepository) WithTx(tx *Tx) ReturnRepository {
+	return &MySqlReturnRepository{
+		Logger: repo.Logger,
+		DB:     tx.conn,
+	}
+}
+
+func (repo *MySqlReturnRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close return repo: %s", err.Error())
+	}
+}
+
+const returnColumns = "id, order_id, status_id, reason, refund_amount, payment_reference, created_user, created_at, updated_user, updated_at"
+
+type returnScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlReturnRepository) scanReturn(row returnScanner) (*model.ReturnResponse, error) {
+	var rma model.ReturnResponse
+	err := row.Scan(&rma.ID, &rma.OrderID, &rma.StatusID, &rma.Reason, &rma.RefundAmount, &rma.PaymentReference, &rma.CreatedUser, &rma.CreatedAt, &rma.UpdatedUser, &rma.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for return: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal return response: %s", err.Error())
+		return nil, err
+	}
+
+	return &rma, nil
+}
+
+func (repo *MySqlReturnRepository) getItemsByReturnID(ctx context.Context, returnId uint64) ([]model.ReturnItemResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT ri.id, ri.return_id, ri.order_item_id, oi.product_id, oi.variant_id, ri.quantity, ri.refund_amount FROM return_items ri JOIN order_items oi ON oi.id = ri.order_item_id WHERE ri.return_id = ? AND ri.deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetReturnItems", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, returnId)
+
+	rows, err := stmt.Query(returnId)
+	if err != nil {
+		return nil, utils.QueryError("GetReturnItems", log, err)
+	}
+	defer rows.Close()
+
+	items := make([]model.ReturnItemResponse, 0)
+	for rows.Next() {
+		var item model.ReturnItemResponse
+		err = rows.Scan(&item.ID, &item.ReturnID, &item.OrderItemID, &item.ProductID, &item.VariantID, &item.Quantity, &item.RefundAmount)
+		if err != nil {
+			return nil, utils.QueryError("GetReturnItems", log, err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetReturnItems", log, err)
+	}
+
+	return items, nil
+}
+
+func (repo *MySqlReturnRepository) GetByID(ctx context.Context, returnId uint64) (*model.ReturnResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + returnColumns + " FROM returns WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetReturnByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, returnId)
+
+	rma, err := repo.scanReturn(stmt.QueryRow(returnId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetReturnByID", log, err)
+	}
+
+	rma.Items, err = repo.getItemsByReturnID(ctx, returnId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+func (repo *MySqlReturnRepository) GetByOrderID(ctx context.Context, orderId uint64) ([]model.ReturnResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + returnColumns + " FROM returns WHERE order_id = ? AND deleted_at IS NULL ORDER BY id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetReturnsByOrderID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, orderId)
+
+	rows, err := stmt.Query(orderId)
+	if err != nil {
+		return nil, utils.QueryError("GetReturnsByOrderID", log, err)
+	}
+	defer rows.Close()
+
+	returns := make([]model.ReturnResponse, 0)
+	for rows.Next() {
+		rma, err := repo.scanReturn(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetReturnsByOrderID", log, err)
+		}
+		returns = append(returns, *rma)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetReturnsByOrderID", log, err)
+	}
+
+	for i := range returns {
+		returns[i].Items, err = repo.getItemsByReturnID(ctx, returns[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return returns, nil
+}
+
+func (repo *MySqlReturnRepository) GetReturnedQuantities(ctx context.Context, orderId uint64) (map[uint64]uint64, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT ri.order_item_id, SUM(ri.quantity) FROM return_items ri JOIN returns r ON r.id = ri.return_id WHERE r.order_id = ? AND r.status_id <> ? AND r.deleted_at IS NULL AND ri.deleted_at IS NULL GROUP BY ri.order_item_id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetReturnedQuantities", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, orderId, constant.RETURN_STATUS_REJECTED)
+
+	rows, err := stmt.Query(orderId, constant.RETURN_STATUS_REJECTED)
+	if err != nil {
+		return nil, utils.QueryError("GetReturnedQuantities", log, err)
+	}
+	defer rows.Close()
+
+	returned := make(map[uint64]uint64)
+	for rows.Next() {
+		var orderItemId, quantity uint64
+		err = rows.Scan(&orderItemId, &quantity)
+		if err != nil {
+			return nil, utils.QueryError("GetReturnedQuantities", log, err)
+		}
+		returned[orderItemId] = quantity
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetReturnedQuantities", log, err)
+	}
+
+	return returned, nil
+}
+
+func (repo *MySqlReturnRepository) Create(ctx context.Context, orderId uint64, statusId uint64, reason string, items []model.ReturnItemResponse, creatingUserId uint64) (*model.ReturnResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	refundAmount := money.Zero(money.DefaultCurrency)
+	for _, item := range items {
+		var err error
+		refundAmount, err = refundAmount.Add(item.RefundAmount)
+		if err != nil {
+			log.Errorf("Unabled to total refund for order '%d': %s", orderId, err.Error())
+			return nil, types.NewInternalServerError()
+		}
+	}
+
+	query := "INSERT INTO returns (order_id, status_id, reason, refund_amount, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%d', '%d', '%s', '%s' and '%d'", query, orderId, statusId, reason, refundAmount, creatingUserId)
+	returnId, err := flows.PerformEdit(
+		ctx,
+		"CreateReturn",
+		query,
+		repo.DB,
+		log,
+		orderId, statusId, reason, refundAmount, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	itemQuery := "INSERT INTO return_items (return_id, order_item_id, quantity, refund_amount, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	for _, item := range items {
+		log.Debugf("Running query '%s' with parameter '%d', '%d', '%d', '%s' and '%d'", itemQuery, returnId, item.OrderItemID, item.Quantity, item.RefundAmount, creatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"CreateReturnItem",
+			itemQuery,
+			repo.DB,
+			log,
+			returnId, item.OrderItemID, item.Quantity, item.RefundAmount, creatingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(returnId))
+}
+
+// UpdateStatus moves the return from fromStatusId to statusId, failing with a bad
+// request when it has already left fromStatusId so two racing approvals cannot both
+// restore stock or refund.
+func (repo *MySqlReturnRepository) UpdateStatus(ctx context.Context, returnId uint64, fromStatusId uint64, statusId uint64, paymentReference *string, updatingUserId uint64) (*model.ReturnResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE returns SET status_id = ?, payment_reference = COALESCE(?, payment_reference), updated_user = ?, updated_at = now() WHERE id = ? AND status_id = ?"
+	log.Debugf("Running query '%s' with parameter '%d', '%v', '%d', '%d' and '%d'", query, statusId, paymentReference, updatingUserId, returnId, fromStatusId)
+	affected, err := flows.PerformConditionalEdit(
+		ctx,
+		"UpdateReturnStatus",
+		query,
+		repo.DB,
+		log,
+		statusId, paymentReference, updatingUserId, returnId, fromStatusId)
+	if err != nil {
+		return nil, err
+	}
+	if affected == 0 {
+		log.Infof("Return '%d' is no longer in status '%d'", returnId, fromStatusId)
+		return nil, types.NewBadRequestError()
+	}
+
+	return repo.GetByID(afterWrite(ctx), returnId)
+}
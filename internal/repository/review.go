@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type ReviewRepository interface {
+	GetByID(ctx context.Context, reviewId uint64) (*model.ReviewResponse, error)
+	GetByProductID(ctx context.Context, productId uint64, statusId uint64) ([]model.ReviewResponse, error)
+	GetByStatus(ctx context.Context, statusId uint64) ([]model.ReviewResponse, error)
+	GetByUserAndProduct(ctx context.Context, userId uint64, productId uint64) (*model.ReviewResponse, error)
+	HasCompletedOrder(ctx context.Context, userId uint64, productId uint64) (bool, error)
+	Create(ctx context.Context, productId uint64, request model.ReviewRequest, creatingUserId uint64) (*model.ReviewResponse, error)
+	Update(ctx context.Context, reviewId uint64, request model.ReviewRequest, updatingUserId uint64) (*model.ReviewResponse, error)
+	Moderate(ctx context.Context, reviewId uint64, request model.ReviewModerationRequest, moderatingUserId uint64) (*model.ReviewResponse, error)
+	Delete(ctx context.Context, reviewId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type MySqlReviewRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlReviewRepository(logger logger.Logger, db mysql.DbConnection) ReviewRepository {
+	return &MySqlReviewRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlReviewRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close review repo: %s", err.Error())
+	}
+}
+
+const reviewColumns = "id, product_id, status_id, rating, COALESCE(title, ''), COALESCE(body, ''), moderation_note, moderated_user, moderated_at, created_user, created_at, updated_user, updated_at"
+
+type reviewScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlReviewRepository) scanReview(row reviewScanner) (*model.ReviewResponse, error) {
+	var review model.ReviewResponse
+	err := row.Scan(&review.ID, &review.ProductID, &review.StatusID, &review.Rating, &review.Title, &review.Body, &review.ModerationNote, &review.ModeratedUser, &review.ModeratedAt, &review.CreatedUser, &review.CreatedAt, &review.UpdatedUser, &review.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for review: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal review response: %s", err.Error())
+		return nil, err
+	}
+
+	return &review, nil
+}
+
+func (repo *MySqlReviewRepository) getOne(ctx context.Context, queryName string, query string, args ...any) (*model.ReviewResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	review, err := repo.scanReview(stmt.QueryRow(args...))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	return review, nil
+}
+
+func (repo *MySqlReviewRepository) getMany(ctx context.Context, queryName string, query string, args ...any) ([]model.ReviewResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+	defer rows.Close()
+
+	reviews := make([]model.ReviewResponse, 0)
+	for rows.Next() {
+		review, err := repo.scanReview(rows)
+		if err != nil {
+			return nil, utils.QueryError(queryName, log, err)
+		}
+		reviews = append(reviews, *review)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	return reviews, nil
+}
+
+func (repo *MySqlReviewRepository) GetByID(ctx context.Context, reviewId uint64) (*model.ReviewResponse, error) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE id = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetReviewByID", query, reviewId)
+}
+
+func (repo *MySqlReviewRepository) GetByUserAndProduct(ctx context.Context, userId uint64, productId uint64) (*model.ReviewResponse, error) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE created_user = ? AND product_id = ? AND deleted_at IS NULL"
+	return repo.getOne(ctx, "GetReviewByUserAndProduct", query, userId, productId)
+}
+
+func (repo *MySqlReviewRepository) GetByProductID(ctx context.Context, productId uint64, statusId uint64) ([]model.ReviewResponse, error) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE product_id = ? AND status_id = ? AND deleted_at IS NULL ORDER BY created_at DESC, id DESC"
+	return repo.getMany(ctx, "GetReviewsByProductID", query, productId, statusId)
+}
+
+func (repo *MySqlReviewRepository) GetByStatus(ctx context.Context, statusId uint64) ([]model.ReviewResponse, error) {
+	query := "SELECT " + reviewColumns + " FROM reviews WHERE status_id = ? AND deleted_at IS NULL ORDER BY created_at, id"
+	return repo.getMany(ctx, "GetReviewsByStatus", query, statusId)
+}
+
+// HasCompletedOrder reports whether the user has a completed order containing the
+// product, which is what earns them a review.
+func (repo *MySqlReviewRepository) HasCompletedOrder(ctx context.Context, userId uint64, productId uint64) (bool, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT EXISTS (SELECT 1 FROM orders o JOIN order_items i ON i.order_id = o.id WHERE o.created_user = ? AND o.status_id = ? AND i.product_id = ? AND o.deleted_at IS NULL AND i.deleted_at IS NULL)"
+	stmt, err := flows.GetReaderStatement(ctx, "HasCompletedOrder", query, repo.DB, log)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d', '%d' and '%d'", query, userId, constant.ORDER_STATUS_COMPLETE, productId)
+
+	var completed bool
+	err = stmt.QueryRow(userId, constant.ORDER_STATUS_COMPLETE, productId).Scan(&completed)
+	if err != nil {
+		return false, utils.QueryError("HasCompletedOrder", log, err)
+	}
+
+	return completed, nil
+}
+
+func (repo *MySqlReviewRepository) Create(ctx context.Context, productId uint64, request model.ReviewRequest, creatingUserId uint64) (*model.ReviewResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO reviews (product_id, status_id, rating, title, body, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%d', '%+v' and '%d'", query, productId, request, creatingUserId)
+	reviewId, err := flows.PerformEdit(
+		ctx,
+		"CreateReview",
+		query,
+		repo.DB,
+		log,
+		productId, constant.REVIEW_STATUS_PENDING, request.Rating, request.Title, request.Body, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(reviewId))
+}
+
+// Update replaces the review text and sends it back for moderation.
+func (repo *MySqlReviewRepository) Update(ctx context.Context, reviewId uint64, request model.ReviewRequest, updatingUserId uint64) (*model.ReviewResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE reviews SET status_id = ?, rating = ?, title = ?, body = ?, moderation_note = NULL, moderated_user = NULL, moderated_at = NULL, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, reviewId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateReview",
+		query,
+		repo.DB,
+		log,
+		constant.REVIEW_STATUS_PENDING, request.Rating, request.Title, request.Body, updatingUserId, reviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), reviewId)
+}
+
+func (repo *MySqlReviewRepository) Moderate(ctx context.Context, reviewId uint64, request model.ReviewModerationRequest, moderatingUserId uint64) (*model.ReviewResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE reviews SET status_id = ?, moderation_note = ?, moderated_user = ?, moderated_at = now(), updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, moderatingUserId, reviewId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ModerateReview",
+		query,
+		repo.DB,
+		log,
+		request.StatusID, request.Note, moderatingUserId, moderatingUserId, reviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), reviewId)
+}
+
+func (repo *MySqlReviewRepository) Delete(ctx context.Context, reviewId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE reviews SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, reviewId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteReview",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, reviewId)
+
+	return err
+}
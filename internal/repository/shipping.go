@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type ShippingRepository interface {
+	GetAllZones(ctx context.Context) ([]model.ShippingZoneResponse, error)
+	GetZoneByID(ctx context.Context, zoneId uint64) (*model.ShippingZoneResponse, error)
+	GetZonesByCountry(ctx context.Context, country string) ([]model.ShippingZoneResponse, error)
+	CreateZone(ctx context.Context, request model.ShippingZoneRequest, creatingUserId uint64) (*model.ShippingZoneResponse, error)
+	UpdateZone(ctx context.Context, zoneId uint64, request model.ShippingZoneRequest, updatingUserId uint64) (*model.ShippingZoneResponse, error)
+	DeleteZone(ctx context.Context, zoneId uint64, deletingUserId uint64) error
+	GetSlots(ctx context.Context, zoneId uint64, from time.Time, to time.Time) ([]model.DeliverySlotResponse, error)
+	GetSlotByID(ctx context.Context, slotId uint64) (*model.DeliverySlotResponse, error)
+	CreateSlot(ctx context.Context, request model.DeliverySlotRequest, creatingUserId uint64) (*model.DeliverySlotResponse, error)
+	DeleteSlot(ctx context.Context, slotId uint64, deletingUserId uint64) error
+	ReserveSlot(ctx context.Context, slotId uint64, updatingUserId uint64) error
+	ReleaseSlot(ctx context.Context, slotId uint64, updatingUserId uint64) error
+	WithTx(tx *Tx) ShippingRepository
+	Shutdown()
+}
+
+type MySqlShippingRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlShippingRepository(logger logger.Logger, db mysql.DbConnection) ShippingRepository {
+	return &MySqlShippingRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+// WithTx returns a copy of the repository running its statements in the unit of
+// work's transaction.
+func (repo *MySqlShippingRepository) WithTx(tx *Tx) ShippingRepository {
+	return &MySqlShippingRepository{
+		Logger: repo.Logger,
+		DB:     tx.conn,
+	}
+}
+
+func (repo *MySqlShippingRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close shipping repo: %s", err.Error())
+	}
+}
+
+const shippingZoneColumns = "id, name, country, city, area_name, created_user, created_at, updated_user, updated_at"
+
+const deliverySlotColumns = "id, zone_id, starts_at, ends_at, capacity, reserved, created_user, created_at, updated_user, updated_at"
+
+type shippingScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlShippingRepository) scanZone(row shippingScanner) (*model.ShippingZoneResponse, error) {
+	var zone model.ShippingZoneResponse
+	err := row.Scan(&zone.ID, &zone.Name, &zone.Country, &zone.City, &zone.AreaName, &zone.CreatedUser, &zone.CreatedAt, &zone.UpdatedUser, &zone.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for shipping zone: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal shipping zone response: %s", err.Error())
+		return nil, err
+	}
+
+	return &zone, nil
+}
+
+func (repo *MySqlShippingRepository) scanSlot(row shippingScanner) (*model.DeliverySlotResponse, error) {
+	var slot model.DeliverySlotResponse
+	err := row.Scan(&slot.ID, &slot.ZoneID, &slot.StartsAt, &slot.EndsAt, &slot.Capacity, &slot.Reserved, &slot.CreatedUser, &slot.CreatedAt, &slot.UpdatedUser, &slot.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for delivery slot: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal delivery slot response: %s", err.Error())
+		return nil, err
+	}
+
+	return &slot, nil
+}
+
+func (repo *MySqlShippingRepository) getRates(ctx context.Context, zoneId uint64) ([]model.ShippingRateResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT id, zone_id, rate_basis_id, min_value, max_value, price FROM shipping_rates WHERE zone_id = ? AND deleted_at IS NULL ORDER BY rate_basis_id, min_value"
+	stmt, err := flows.GetReaderStatement(ctx, "GetShippingRates", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, zoneId)
+
+	rows, err := stmt.Query(zoneId)
+	if err != nil {
+		return nil, utils.QueryError("GetShippingRates", log, err)
+	}
+	defer rows.Close()
+
+	rates := make([]model.ShippingRateResponse, 0)
+	for rows.Next() {
+		var rate model.ShippingRateResponse
+		err = rows.Scan(&rate.ID, &rate.ZoneID, &rate.RateBasisID, &rate.MinValue, &rate.MaxValue, &rate.Price)
+		if err != nil {
+			return nil, utils.QueryError("GetShippingRates", log, err)
+		}
+		rates = append(rates, rate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetShippingRates", log, err)
+	}
+
+	return rates, nil
+}
+
+func (repo *MySqlShippingRepository) getZones(ctx context.Context, queryName string, query string, args ...any) ([]model.ShippingZoneResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+	defer rows.Close()
+
+	zones := make([]model.ShippingZoneResponse, 0)
+	for rows.Next() {
+		zone, err := repo.scanZone(rows)
+		if err != nil {
+			return nil, utils.QueryError(queryName, log, err)
+		}
+		zones = append(zones, *zone)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	for i := range zones {
+		zones[i].Rates, err = repo.getRates(ctx, zones[i].ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return zones, nil
+}
+
+func (repo *MySqlShippingRepository) GetAllZones(ctx context.Context) ([]model.ShippingZoneResponse, error) {
+	query := "SELECT " + shippingZoneColumns + " FROM shipping_zones WHERE deleted_at IS NULL ORDER BY country, city, area_name"
+	return repo.getZones(ctx, "GetAllShippingZones", query)
+}
+
+func (repo *MySqlShippingRepository) GetZonesByCountry(ctx context.Context, country string) ([]model.ShippingZoneResponse, error) {
+	query := "SELECT " + shippingZoneColumns + " FROM shipping_zones WHERE country = ? AND deleted_at IS NULL ORDER BY city, area_name"
+	return repo.getZones(ctx, "GetShippingZonesByCountry", query, country)
+}
+
+func (repo *MySqlShippingRepository) GetZoneByID(ctx context.Context, zoneId uint64) (*model.ShippingZoneResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + shippingZoneColumns + " FROM shipping_zones WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetShippingZoneByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, zoneId)
+
+	zone, err := repo.scanZone(stmt.QueryRow(zoneId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetShippingZoneByID", log, err)
+	}
+
+	zone.Rates, err = repo.getRates(ctx, zoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (repo *MySqlShippingRepository) replaceRates(ctx context.Context, zoneId uint64, rates []model.ShippingRateRequest, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE shipping_rates SET deleted_user = ?, deleted_at = now() WHERE zone_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, zoneId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearShippingRates",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, zoneId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO shipping_rates (zone_id, rate_basis_id, min_value, max_value, price, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, now())"
+	for _, rate := range rates {
+		log.Debugf("Running query '%s' with parameter '%d', '%+v' and '%d'", query, zoneId, rate, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddShippingRate",
+			query,
+			repo.DB,
+			log,
+			zoneId, rate.RateBasisID, rate.MinValue, rate.MaxValue, rate.Price, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlShippingRepository) CreateZone(ctx context.Context, request model.ShippingZoneRequest, creatingUserId uint64) (*model.ShippingZoneResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO shipping_zones (name, country, city, area_name, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	zoneId, err := flows.PerformEdit(
+		ctx,
+		"CreateShippingZone",
+		query,
+		repo.DB,
+		log,
+		request.Name, request.Country, request.City, request.AreaName, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceRates(ctx, uint64(zoneId), request.Rates, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetZoneByID(afterWrite(ctx), uint64(zoneId))
+}
+
+func (repo *MySqlShippingRepository) UpdateZone(ctx context.Context, zoneId uint64, request model.ShippingZoneRequest, updatingUserId uint64) (*model.ShippingZoneResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE shipping_zones SET name = ?, country = ?, city = ?, area_name = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, zoneId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateShippingZone",
+		query,
+		repo.DB,
+		log,
+		request.Name, request.Country, request.City, request.AreaName, updatingUserId, zoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceRates(ctx, zoneId, request.Rates, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetZoneByID(afterWrite(ctx), zoneId)
+}
+
+func (repo *MySqlShippingRepository) DeleteZone(ctx context.Context, zoneId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE shipping_zones SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, zoneId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteShippingZone",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, zoneId)
+
+	return err
+}
+
+func (repo *MySqlShippingRepository) GetSlots(ctx context.Context, zoneId uint64, from time.Time, to time.Time) ([]model.DeliverySlotResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + deliverySlotColumns + " FROM delivery_slots WHERE zone_id = ? AND starts_at >= ? AND starts_at < ? AND deleted_at IS NULL ORDER BY starts_at"
+	stmt, err := flows.GetReaderStatement(ctx, "GetDeliverySlots", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d', '%s' and '%s'", query, zoneId, from, to)
+
+	rows, err := stmt.Query(zoneId, from, to)
+	if err != nil {
+		return nil, utils.QueryError("GetDeliverySlots", log, err)
+	}
+	defer rows.Close()
+
+	slots := make([]model.DeliverySlotResponse, 0)
+	for rows.Next() {
+		slot, err := repo.scanSlot(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetDeliverySlots", log, err)
+		}
+		slots = append(slots, *slot)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetDeliverySlots", log, err)
+	}
+
+	return slots, nil
+}
+
+func (repo *MySqlShippingRepository) GetSlotByID(ctx context.Context, slotId uint64) (*model.DeliverySlotResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + deliverySlotColumns + " FROM delivery_slots WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetDeliverySlotByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, slotId)
+
+	slot, err := repo.scanSlot(stmt.QueryRow(slotId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetDeliverySlotByID", log, err)
+	}
+
+	return slot, nil
+}
+
+func (repo *MySqlShippingRepository) CreateSlot(ctx context.Context, request model.DeliverySlotRequest, creatingUserId uint64) (*model.DeliverySlotResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO delivery_slots (zone_id, starts_at, ends_at, capacity, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	slotId, err := flows.PerformEdit(
+		ctx,
+		"CreateDeliverySlot",
+		query,
+		repo.DB,
+		log,
+		request.ZoneID, request.StartsAt, request.EndsAt, request.Capacity, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetSlotByID(afterWrite(ctx), uint64(slotId))
+}
+
+func (repo *MySqlShippingRepository) DeleteSlot(ctx context.Context, slotId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE delivery_slots SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, slotId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteDeliverySlot",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, slotId)
+
+	return err
+}
+
+// ReserveSlot takes one place in the slot, failing with a bad request once it is full.
+// The capacity check and increment happen in one statement so concurrent checkouts
+// cannot overbook the slot.
+func (repo *MySqlShippingRepository) ReserveSlot(ctx context.Context, slotId uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE delivery_slots SET reserved = reserved + 1, updated_user = ?, updated_at = now() WHERE id = ? AND reserved < capacity AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, slotId)
+	affected, err := flows.PerformConditionalEdit(
+		ctx,
+		"ReserveDeliverySlot",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, slotId)
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		log.Infof("Delivery slot '%d' is full", slotId)
+		return types.NewBadRequestError()
+	}
+
+	return nil
+}
+
+func (repo *MySqlShippingRepository) ReleaseSlot(ctx context.Context, slotId uint64, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE delivery_slots SET reserved = reserved - 1, updated_user = ?, updated_at = now() WHERE id = ? AND reserved > 0"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, slotId)
+	_, err := flows.PerformConditionalEdit(
+		ctx,
+		"ReleaseDeliverySlot",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, slotId)
+
+	return err
+}
@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type TaxRepository interface {
+	GetAll(ctx context.Context) ([]model.TaxRateResponse, error)
+	GetByID(ctx context.Context, taxRateId uint64) (*model.TaxRateResponse, error)
+	GetByCountry(ctx context.Context, country string) ([]model.TaxRateResponse, error)
+	Create(ctx context.Context, request model.TaxRateRequest, creatingUserId uint64) (*model.TaxRateResponse, error)
+	Update(ctx context.Context, taxRateId uint64, request model.TaxRateRequest, updatingUserId uint64) (*model.TaxRateResponse, error)
+	Delete(ctx context.Context, taxRateId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type MySqlTaxRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlTaxRepository(logger logger.Logger, db mysql.DbConnection) TaxRepository {
+	return &MySqlTaxRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlTaxRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close tax repo: %s", err.Error())
+	}
+}
+
+const taxRateColumns = "id, country, region, tax_class_id, name, rate, prices_include_tax, created_user, created_at, updated_user, updated_at"
+
+type taxRateScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlTaxRepository) scanTaxRate(row taxRateScanner) (*model.TaxRateResponse, error) {
+	var rate model.TaxRateResponse
+	err := row.Scan(&rate.ID, &rate.Country, &rate.Region, &rate.TaxClassID, &rate.Name, &rate.Rate, &rate.PricesIncludeTax, &rate.CreatedUser, &rate.CreatedAt, &rate.UpdatedUser, &rate.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for tax rate: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal tax rate response: %s", err.Error())
+		return nil, err
+	}
+
+	return &rate, nil
+}
+
+func (repo *MySqlTaxRepository) getMany(ctx context.Context, queryName string, query string, args ...any) ([]model.TaxRateResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+	defer rows.Close()
+
+	rates := make([]model.TaxRateResponse, 0)
+	for rows.Next() {
+		rate, err := repo.scanTaxRate(rows)
+		if err != nil {
+			return nil, utils.QueryError(queryName, log, err)
+		}
+		rates = append(rates, *rate)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	return rates, nil
+}
+
+func (repo *MySqlTaxRepository) GetAll(ctx context.Context) ([]model.TaxRateResponse, error) {
+	query := "SELECT " + taxRateColumns + " FROM tax_rates WHERE deleted_at IS NULL ORDER BY country, region, tax_class_id"
+	return repo.getMany(ctx, "GetAllTaxRates", query)
+}
+
+func (repo *MySqlTaxRepository) GetByCountry(ctx context.Context, country string) ([]model.TaxRateResponse, error) {
+	query := "SELECT " + taxRateColumns + " FROM tax_rates WHERE country = ? AND deleted_at IS NULL ORDER BY region, tax_class_id"
+	return repo.getMany(ctx, "GetTaxRatesByCountry", query, country)
+}
+
+func (repo *MySqlTaxRepository) GetByID(ctx context.Context, taxRateId uint64) (*model.TaxRateResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + taxRateColumns + " FROM tax_rates WHERE id = ? AND deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "GetTaxRateByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, taxRateId)
+
+	rate, err := repo.scanTaxRate(stmt.QueryRow(taxRateId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetTaxRateByID", log, err)
+	}
+
+	return rate, nil
+}
+
+func (repo *MySqlTaxRepository) Create(ctx context.Context, request model.TaxRateRequest, creatingUserId uint64) (*model.TaxRateResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO tax_rates (country, region, tax_class_id, name, rate, prices_include_tax, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%+v' and '%d'", query, request, creatingUserId)
+	taxRateId, err := flows.PerformEdit(
+		ctx,
+		"CreateTaxRate",
+		query,
+		repo.DB,
+		log,
+		request.Country, request.Region, request.TaxClassID, request.Name, request.Rate, request.PricesIncludeTax, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(taxRateId))
+}
+
+func (repo *MySqlTaxRepository) Update(ctx context.Context, taxRateId uint64, request model.TaxRateRequest, updatingUserId uint64) (*model.TaxRateResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE tax_rates SET country = ?, region = ?, tax_class_id = ?, name = ?, rate = ?, prices_include_tax = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, taxRateId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"UpdateTaxRate",
+		query,
+		repo.DB,
+		log,
+		request.Country, request.Region, request.TaxClassID, request.Name, request.Rate, request.PricesIncludeTax, updatingUserId, taxRateId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), taxRateId)
+}
+
+func (repo *MySqlTaxRepository) Delete(ctx context.Context, taxRateId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE tax_rates SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, taxRateId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteTaxRate",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, taxRateId)
+
+	return err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+// Tx is the transaction of a unit of work. Repositories bound to it with WithTx
+// run their statements in the transaction, reads included, so a call sees the
+// writes of the calls before it.
+type Tx struct {
+	conn mysql.DbConnection
+}
+
+type UnitOfWork interface {
+	// Run calls fn in a single writer transaction, committing when it returns nil
+	// and rolling back when it returns an error or panics.
+	Run(ctx context.Context, queryName string, fn func(tx *Tx) error) error
+}
+
+type MySqlUnitOfWork struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlUnitOfWork(logger logger.Logger, db mysql.DbConnection) UnitOfWork {
+	return &MySqlUnitOfWork{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (uow *MySqlUnitOfWork) Run(ctx context.Context, queryName string, fn func(tx *Tx) error) error {
+	log := logger.FromContext(ctx, uow.Logger)
+	conn, err := uow.DB.Begin(ctx)
+	if err != nil {
+		return utils.BeginError(queryName, log, err)
+	}
+	log.Debugf("Began unit of work '%s'", queryName)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			conn.Tx().Rollback()
+			log.Errorf("Rolled back unit of work '%s' after panic: %v", queryName, recovered)
+			panic(recovered)
+		}
+	}()
+
+	err = fn(&Tx{conn: *conn})
+	if err != nil {
+		conn.Tx().Rollback()
+		log.Debugf("Rolled back unit of work '%s': %s", queryName, err.Error())
+		return err
+	}
+
+	err = conn.Tx().Commit()
+	if err != nil {
+		utils.LogCommitError(queryName, log, err)
+		return types.NewInternalServerError()
+	}
+	conn.RecordWrite(ctx)
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"time"
 
+	"tannar.moss/backend/internal/audit"
 	"tannar.moss/backend/internal/logger"
 	"tannar.moss/backend/internal/model"
 	"tannar.moss/backend/internal/repository/flows"
@@ -13,12 +15,13 @@ import (
 )
 
 type UserRepository interface {
-	GetByID(userId uint64) (*model.UserResponse, error)
-	GetByEmail(email string) (*model.UserResponse, error)
-	Register(firstName string, lastName string, email string, password string, roleId uint64) (*model.UserResponse, error)
-	Update(userId uint64, firstName string, lastName string, updatingUserId uint64) (*model.UserResponse, error)
-	ResetPassword(userId uint64, newPassword string, updatingUserId uint64) (*model.UserResponse, error)
-	ResetEmail(userId uint64, newEmail string, updatingUserId uint64) (*model.UserResponse, error)
+	GetByID(ctx context.Context, userId uint64) (*model.UserResponse, error)
+	GetByEmail(ctx context.Context, email string) (*model.UserResponse, error)
+	Register(ctx context.Context, firstName string, lastName string, email string, password string, roleId uint64, source audit.Source) (*model.UserResponse, error)
+	Update(ctx context.Context, userId uint64, firstName string, lastName string, source audit.Source) (*model.UserResponse, error)
+	ResetPassword(ctx context.Context, userId uint64, newPassword string, source audit.Source) (*model.UserResponse, error)
+	ResetEmail(ctx context.Context, userId uint64, newEmail string, source audit.Source) (*model.UserResponse, error)
+	HasPermission(ctx context.Context, userId uint64, permission string) (bool, error)
 	Shutdown()
 }
 
@@ -29,6 +32,8 @@ type MySqlUserRepository struct {
 
 const MySystemAutoID = 1
 
+const usersEntity = "users"
+
 func (repo *MySqlUserRepository) Shutdown() {
 	err := repo.DB.Close()
 	if err != nil {
@@ -46,7 +51,7 @@ func NewMySqlUserRepository(logger logger.Logger, db mysql.DbConnection) UserRep
 func (repo *MySqlUserRepository) mapStatementToUser(row *sql.Row) (*model.UserResponse, error) {
 	var user model.UserResponse
 	if row.Err() != nil {
-		return nil, types.NewInternalServerError()
+		return nil, row.Err()
 	}
 	err := row.Scan(&user.ID, &user.FirstName, &user.LastName, &user.Email, &user.HashedPassword, &user.RoleID, &user.CreatedUser, &user.CreatedAt, &user.UpdatedUser, &user.UpdatedAt, &user.DeletedUser, &user.DeletedAt)
 	if err != nil {
@@ -61,56 +66,67 @@ func (repo *MySqlUserRepository) mapStatementToUser(row *sql.Row) (*model.UserRe
 	return &user, nil
 }
 
-func (repo *MySqlUserRepository) GetByEmail(email string) (*model.UserResponse, error) {
+func (repo *MySqlUserRepository) GetByEmail(ctx context.Context, email string) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
 	query := "SELECT * FROM users WHERE email = ?"
-	stmt, err := flows.GetReaderStatement("GetByEmail", query, repo.DB, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, "GetByEmail", query, repo.DB, log)
 	if err != nil {
 		return nil, err
 	}
-	repo.Logger.Debugf("Running query '%s' with parameter '%s'", query, email)
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%s'", query, email)
 
 	result := stmt.QueryRow(email)
 	user, err := repo.mapStatementToUser(result)
 
 	if err != nil {
-		utils.LogExecutingError("GetByEmail", repo.Logger, err)
-		return nil, types.NewInternalServerError()
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetByEmail", log, err)
 	}
 
 	return user, nil
 }
 
-func (repo *MySqlUserRepository) GetByID(userId uint64) (*model.UserResponse, error) {
+func (repo *MySqlUserRepository) GetByID(ctx context.Context, userId uint64) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
 	query := "SELECT * FROM users WHERE id = ?"
-	stmt, err := flows.GetReaderStatement("GetByID", query, repo.DB, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, "GetByID", query, repo.DB, log)
 	if err != nil {
 		return nil, err
 	}
-	repo.Logger.Debugf("Running query '%s' with parameter '%d'", query, userId)
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, userId)
 	user, err := repo.mapStatementToUser(stmt.QueryRow(userId))
 	if err != nil {
-		utils.LogExecutingError("GetByID", repo.Logger, err)
-		return nil, types.NewInternalServerError()
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetByID", log, err)
 	}
 
 	return user, nil
 }
 
-func (repo *MySqlUserRepository) Register(firstName string, lastName string, email string, password string, roleId uint64) (*model.UserResponse, error) {
+func (repo *MySqlUserRepository) Register(ctx context.Context, firstName string, lastName string, email string, password string, roleId uint64, source audit.Source) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
 	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
-		repo.Logger.Errorf("Error creating hashPassword: %s", err.Error())
+		log.Errorf("Error creating hashPassword: %s", err.Error())
 		return nil, types.NewInternalServerError()
 	}
 	insertedAt := utils.GetCurrentDateFormatedForInsertingIntoDB(time.Now())
 	query := "INSERT INTO users (first_name, last_name, email, hashed_password, role_id, created_user, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)"
-	repo.Logger.Debugf("Running query '%s' with parameter '%s', '%s', '%s', '%v', '%d', '%d' and '%s'", query, firstName, lastName, email, hashedPassword, roleId, MySystemAutoID, insertedAt)
-	lastInsertedId, err := flows.PerformEdit(
+	log.Debugf("Running query '%s' with parameter '%s', '%s', '%s', '%v', '%d', '%d' and '%s'", query, firstName, lastName, email, hashedPassword, roleId, source.ActorID, insertedAt)
+	lastInsertedId, err := flows.PerformAuditedEdit(
+		ctx,
 		"Register",
 		query,
 		repo.DB,
-		repo.Logger,
-		firstName, lastName, email, hashedPassword, roleId, MySystemAutoID, insertedAt)
+		log,
+		audit.Entry{Entity: usersEntity, Action: audit.ActionCreate, Source: source},
+		firstName, lastName, email, hashedPassword, roleId, source.ActorID, insertedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -121,61 +137,89 @@ func (repo *MySqlUserRepository) Register(firstName string, lastName string, ema
 		LastName:    lastName,
 		Email:       email,
 		RoleID:      roleId,
-		CreatedUser: MySystemAutoID,
+		CreatedUser: source.ActorID,
 		CreatedAt:   insertedAt,
 	}, nil
 }
 
-func (repo *MySqlUserRepository) Update(userId uint64, firstName string, lastName string, updatingUserId uint64) (*model.UserResponse, error) {
+func (repo *MySqlUserRepository) Update(ctx context.Context, userId uint64, firstName string, lastName string, source audit.Source) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
 	query := "UPDATE users SET first_name = ?, last_name = ?, updated_user = ?, updated_at = now() WHERE id = ?"
-	repo.Logger.Debugf("Running query '%s' with parameter '%s', '%s', '%d' and '%d'", query, firstName, lastName, updatingUserId, userId)
-	_, err := flows.PerformEdit(
+	log.Debugf("Running query '%s' with parameter '%s', '%s', '%d' and '%d'", query, firstName, lastName, source.ActorID, userId)
+	_, err := flows.PerformAuditedEdit(
+		ctx,
 		"Update",
 		query,
 		repo.DB,
-		repo.Logger,
-		firstName, lastName, updatingUserId, userId)
+		log,
+		audit.Entry{Entity: usersEntity, EntityID: userId, Action: audit.ActionUpdate, Source: source},
+		firstName, lastName, source.ActorID, userId)
 	if err != nil {
 		return nil, err
 	}
 
-	return repo.GetByID(userId)
+	return repo.GetByID(afterWrite(ctx), userId)
 }
 
-func (repo *MySqlUserRepository) ResetPassword(userId uint64, newPassword string, updatingUserId uint64) (*model.UserResponse, error) {
+func (repo *MySqlUserRepository) ResetPassword(ctx context.Context, userId uint64, newPassword string, source audit.Source) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
 	hashedPassword, err := utils.HashPassword(newPassword)
 	if err != nil {
-		repo.Logger.Errorf("Error hashing password: %s", err.Error())
+		log.Errorf("Error hashing password: %s", err.Error())
 		return nil, types.NewInternalServerError()
 	}
 
-	query := "UPDATE users SET hashed_password = ?, updated_user = ? WHERE id = ?"
-	repo.Logger.Debugf("Running query '%s' with parameter '%v', '%d' and '%d'", query, hashedPassword, updatingUserId, userId)
-	_, err = flows.PerformEdit(
+	query := "UPDATE users SET hashed_password = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%v', '%d' and '%d'", query, hashedPassword, source.ActorID, userId)
+	_, err = flows.PerformAuditedEdit(
+		ctx,
 		"ResetPassword",
 		query,
 		repo.DB,
-		repo.Logger,
-		hashedPassword, updatingUserId, userId)
+		log,
+		audit.Entry{Entity: usersEntity, EntityID: userId, Action: audit.ActionUpdate, Source: source},
+		hashedPassword, source.ActorID, userId)
 	if err != nil {
 		return nil, err
 	}
 
-	return repo.GetByID(userId)
+	return repo.GetByID(afterWrite(ctx), userId)
 }
 
-func (repo *MySqlUserRepository) ResetEmail(userId uint64, newEmail string, updatingUserId uint64) (*model.UserResponse, error) {
-	query := "UPDATE users SET email = ?, updated_user = ? WHERE id = ?"
-	repo.Logger.Debugf("Running query '%s' with parameter '%s', '%d' and '%d'", query, newEmail, updatingUserId, userId)
-	_, err := flows.PerformEdit(
+func (repo *MySqlUserRepository) ResetEmail(ctx context.Context, userId uint64, newEmail string, source audit.Source) (*model.UserResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE users SET email = ?, updated_user = ?, updated_at = now() WHERE id = ?"
+	log.Debugf("Running query '%s' with parameter '%s', '%d' and '%d'", query, newEmail, source.ActorID, userId)
+	_, err := flows.PerformAuditedEdit(
+		ctx,
 		"ResetEmail",
 		query,
 		repo.DB,
-		repo.Logger,
-		newEmail, updatingUserId, userId)
+		log,
+		audit.Entry{Entity: usersEntity, EntityID: userId, Action: audit.ActionUpdate, Source: source},
+		newEmail, source.ActorID, userId)
 	if err != nil {
 		return nil, err
 	}
 
-	return repo.GetByID(userId)
+	return repo.GetByID(afterWrite(ctx), userId)
+}
+
+func (repo *MySqlUserRepository) HasPermission(ctx context.Context, userId uint64, permission string) (bool, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT COUNT(*) FROM users u JOIN role_permissions rp ON rp.role_id = u.role_id AND rp.deleted_at IS NULL JOIN permission_types p ON p.id = rp.permission_id AND p.deleted_at IS NULL WHERE u.id = ? AND p.name = ? AND u.deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "HasPermission", query, repo.DB, log)
+	if err != nil {
+		return false, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d' and '%s'", query, userId, permission)
+
+	var count int
+	err = stmt.QueryRow(userId, permission).Scan(&count)
+	if err != nil {
+		return false, utils.QueryError("HasPermission", log, err)
+	}
+
+	return count > 0, nil
 }
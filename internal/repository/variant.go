@@ -0,0 +1,310 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+const variantColumns = "id, product_id, sku, price, stock, created_user, created_at, updated_user, updated_at"
+
+type variantScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlProductRepository) scanVariant(row variantScanner) (*model.VariantResponse, error) {
+	var variant model.VariantResponse
+	err := row.Scan(&variant.ID, &variant.ProductID, &variant.SKU, &variant.Price, &variant.Stock, &variant.CreatedUser, &variant.CreatedAt, &variant.UpdatedUser, &variant.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for variant: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal variant response: %s", err.Error())
+		return nil, err
+	}
+	variant.Attributes = make([]model.VariantAttribute, 0)
+	variant.Pictures = make([]string, 0)
+
+	return &variant, nil
+}
+
+// fillVariants loads the attributes and pictures of the product's variants in one
+// query each rather than one per variant.
+func (repo *MySqlProductRepository) fillVariants(ctx context.Context, productId uint64, variants []model.VariantResponse) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	byId := make(map[uint64]*model.VariantResponse, len(variants))
+	for i := range variants {
+		byId[variants[i].ID] = &variants[i]
+	}
+
+	query := "SELECT vav.variant_id, av.id, a.name, av.value FROM variant_attribute_values vav JOIN product_variants v ON v.id = vav.variant_id JOIN attribute_values av ON av.id = vav.attribute_value_id JOIN attributes a ON a.id = av.attribute_id WHERE v.product_id = ? AND vav.deleted_at IS NULL ORDER BY a.id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetVariantAttributes", query, repo.DB, log)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, productId)
+
+	rows, err := stmt.Query(productId)
+	if err != nil {
+		return utils.QueryError("GetVariantAttributes", log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var variantId uint64
+		var attribute model.VariantAttribute
+		err = rows.Scan(&variantId, &attribute.AttributeValueID, &attribute.Name, &attribute.Value)
+		if err != nil {
+			return utils.QueryError("GetVariantAttributes", log, err)
+		}
+		if variant, ok := byId[variantId]; ok {
+			variant.Attributes = append(variant.Attributes, attribute)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return utils.QueryError("GetVariantAttributes", log, err)
+	}
+
+	query = "SELECT variant_id, picture_url FROM pictures WHERE product_id = ? AND variant_id IS NOT NULL AND deleted_at IS NULL ORDER BY id"
+	pictureStmt, err := flows.GetReaderStatement(ctx, "GetVariantPictures", query, repo.DB, log)
+	if err != nil {
+		return err
+	}
+	defer pictureStmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, productId)
+
+	pictureRows, err := pictureStmt.Query(productId)
+	if err != nil {
+		return utils.QueryError("GetVariantPictures", log, err)
+	}
+	defer pictureRows.Close()
+
+	for pictureRows.Next() {
+		var variantId uint64
+		var pictureUrl string
+		err = pictureRows.Scan(&variantId, &pictureUrl)
+		if err != nil {
+			return utils.QueryError("GetVariantPictures", log, err)
+		}
+		if variant, ok := byId[variantId]; ok {
+			variant.Pictures = append(variant.Pictures, pictureUrl)
+		}
+	}
+	if err = pictureRows.Err(); err != nil {
+		return utils.QueryError("GetVariantPictures", log, err)
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) GetVariants(ctx context.Context, productId uint64) ([]model.VariantResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "SELECT " + variantColumns + " FROM product_variants WHERE product_id = ? AND deleted_at IS NULL ORDER BY id"
+	stmt, err := flows.GetReaderStatement(ctx, "GetProductVariants", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, productId)
+
+	rows, err := stmt.Query(productId)
+	if err != nil {
+		return nil, utils.QueryError("GetProductVariants", log, err)
+	}
+	defer rows.Close()
+
+	variants := make([]model.VariantResponse, 0)
+	for rows.Next() {
+		variant, err := repo.scanVariant(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetProductVariants", log, err)
+		}
+		variants = append(variants, *variant)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetProductVariants", log, err)
+	}
+
+	if len(variants) > 0 {
+		err = repo.fillVariants(ctx, productId, variants)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return variants, nil
+}
+
+func (repo *MySqlProductRepository) getVariant(ctx context.Context, queryName string, query string, arg any) (*model.VariantResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	stmt, err := flows.GetReaderStatement(ctx, queryName, query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, arg)
+
+	variant, err := repo.scanVariant(stmt.QueryRow(arg))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError(queryName, log, err)
+	}
+
+	variants, err := repo.GetVariants(ctx, variant.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range variants {
+		if variants[i].ID == variant.ID {
+			return &variants[i], nil
+		}
+	}
+
+	return variant, nil
+}
+
+func (repo *MySqlProductRepository) GetVariantByID(ctx context.Context, variantId uint64) (*model.VariantResponse, error) {
+	query := "SELECT " + variantColumns + " FROM product_variants WHERE id = ? AND deleted_at IS NULL"
+	return repo.getVariant(ctx, "GetVariantByID", query, variantId)
+}
+
+func (repo *MySqlProductRepository) GetVariantBySKU(ctx context.Context, sku string) (*model.VariantResponse, error) {
+	query := "SELECT " + variantColumns + " FROM product_variants WHERE sku = ? AND deleted_at IS NULL"
+	return repo.getVariant(ctx, "GetVariantBySKU", query, sku)
+}
+
+func (repo *MySqlProductRepository) replaceVariantDetails(ctx context.Context, productId uint64, variantId uint64, request model.VariantRequest, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE variant_attribute_values SET deleted_user = ?, deleted_at = now() WHERE variant_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, variantId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"ClearVariantAttributes",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, variantId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO variant_attribute_values (variant_id, attribute_value_id, created_user, created_at) VALUES (?, ?, ?, now()) ON DUPLICATE KEY UPDATE deleted_user = NULL, deleted_at = NULL, updated_user = VALUES(created_user), updated_at = now()"
+	for _, attributeValueId := range request.AttributeValueIDs {
+		log.Debugf("Running query '%s' with parameter '%d', '%d' and '%d'", query, variantId, attributeValueId, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddVariantAttribute",
+			query,
+			repo.DB,
+			log,
+			variantId, attributeValueId, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	query = "UPDATE pictures SET deleted_user = ?, deleted_at = now() WHERE variant_id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, updatingUserId, variantId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"ClearVariantPictures",
+		query,
+		repo.DB,
+		log,
+		updatingUserId, variantId)
+	if err != nil {
+		return err
+	}
+
+	query = "INSERT INTO pictures (picture_url, product_id, variant_id, created_user, created_at) VALUES (?, ?, ?, ?, now())"
+	for _, pictureUrl := range request.Pictures {
+		log.Debugf("Running query '%s' with parameter '%s', '%d', '%d' and '%d'", query, pictureUrl, productId, variantId, updatingUserId)
+		_, err = flows.PerformEdit(
+			ctx,
+			"AddVariantPicture",
+			query,
+			repo.DB,
+			log,
+			pictureUrl, productId, variantId, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (repo *MySqlProductRepository) CreateVariant(ctx context.Context, productId uint64, request model.VariantRequest, creatingUserId uint64) (*model.VariantResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO product_variants (product_id, sku, price, stock, created_user, created_at) VALUES (?, ?, ?, ?, ?, now())"
+	log.Debugf("Running query '%s' with parameter '%d', '%+v' and '%d'", query, productId, request, creatingUserId)
+	variantId, err := flows.PerformEdit(
+		ctx,
+		"CreateVariant",
+		query,
+		repo.DB,
+		log,
+		productId, request.SKU, request.Price, request.Stock, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceVariantDetails(ctx, productId, uint64(variantId), request, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetVariantByID(afterWrite(ctx), uint64(variantId))
+}
+
+func (repo *MySqlProductRepository) UpdateVariant(ctx context.Context, variantId uint64, request model.VariantRequest, updatingUserId uint64) (*model.VariantResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	variant, err := repo.GetVariantByID(ctx, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "UPDATE product_variants SET sku = ?, price = ?, stock = ?, updated_user = ?, updated_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%+v', '%d' and '%d'", query, request, updatingUserId, variantId)
+	_, err = flows.PerformEdit(
+		ctx,
+		"UpdateVariant",
+		query,
+		repo.DB,
+		log,
+		request.SKU, request.Price, request.Stock, updatingUserId, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.replaceVariantDetails(ctx, variant.ProductID, variantId, request, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetVariantByID(afterWrite(ctx), variantId)
+}
+
+func (repo *MySqlProductRepository) DeleteVariant(ctx context.Context, variantId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE product_variants SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, variantId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"DeleteVariant",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, variantId)
+
+	return err
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+type WishlistRepository interface {
+	GetByID(ctx context.Context, itemId uint64) (*model.WishlistItemResponse, error)
+	GetByUserID(ctx context.Context, userId uint64) ([]model.WishlistItemResponse, error)
+	Add(ctx context.Context, request model.WishlistItemRequest, savedPrice money.Money, savedStock uint64, creatingUserId uint64) (*model.WishlistItemResponse, error)
+	Remove(ctx context.Context, itemId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type MySqlWishlistRepository struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+}
+
+func NewMySqlWishlistRepository(logger logger.Logger, db mysql.DbConnection) WishlistRepository {
+	return &MySqlWishlistRepository{
+		Logger: logger,
+		DB:     db,
+	}
+}
+
+func (repo *MySqlWishlistRepository) Shutdown() {
+	err := repo.DB.Close()
+	if err != nil {
+		repo.Logger.Errorf("Unabled to close wishlist repo: %s", err.Error())
+	}
+}
+
+// wishlistSelect reads each item with the current price and stock of its product,
+// or of its variant when one was saved, leaving out deleted products and variants.
+const wishlistSelect = "SELECT w.id, w.product_id, w.variant_id, IF(w.variant_id IS NULL, p.sku, v.sku), p.title, w.saved_price, w.saved_stock, COALESCE(v.price, p.price), IF(w.variant_id IS NULL, p.stock, v.stock), w.created_user, w.created_at, w.updated_user, w.updated_at " +
+	"FROM wishlist_items w JOIN products p ON p.id = w.product_id AND p.deleted_at IS NULL LEFT JOIN product_variants v ON v.id = w.variant_id " +
+	"WHERE w.deleted_at IS NULL AND (w.variant_id IS NULL OR v.deleted_at IS NULL)"
+
+type wishlistScanner interface {
+	Scan(dest ...any) error
+}
+
+func (repo *MySqlWishlistRepository) scanItem(row wishlistScanner) (*model.WishlistItemResponse, error) {
+	var item model.WishlistItemResponse
+	err := row.Scan(&item.ID, &item.ProductID, &item.VariantID, &item.SKU, &item.Title, &item.SavedPrice, &item.SavedStock, &item.Price, &item.Stock, &item.CreatedUser, &item.CreatedAt, &item.UpdatedUser, &item.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			repo.Logger.Debugf("No result back for wishlist item: %s", err.Error())
+			return nil, types.NewNoTFoundOrNoRecordError()
+		}
+		repo.Logger.Errorf("Unabled to marshal wishlist item response: %s", err.Error())
+		return nil, err
+	}
+
+	return &item, nil
+}
+
+func (repo *MySqlWishlistRepository) GetByID(ctx context.Context, itemId uint64) (*model.WishlistItemResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := wishlistSelect + " AND w.id = ?"
+	stmt, err := flows.GetReaderStatement(ctx, "GetWishlistItemByID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, itemId)
+
+	item, err := repo.scanItem(stmt.QueryRow(itemId))
+	if err != nil {
+		if _, ok := err.(*types.SocketError); ok {
+			return nil, err
+		}
+		return nil, utils.QueryError("GetWishlistItemByID", log, err)
+	}
+
+	return item, nil
+}
+
+func (repo *MySqlWishlistRepository) GetByUserID(ctx context.Context, userId uint64) ([]model.WishlistItemResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := wishlistSelect + " AND w.created_user = ? ORDER BY w.created_at DESC, w.id DESC"
+	stmt, err := flows.GetReaderStatement(ctx, "GetWishlistByUserID", query, repo.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%d'", query, userId)
+
+	rows, err := stmt.Query(userId)
+	if err != nil {
+		return nil, utils.QueryError("GetWishlistByUserID", log, err)
+	}
+	defer rows.Close()
+
+	items := make([]model.WishlistItemResponse, 0)
+	for rows.Next() {
+		item, err := repo.scanItem(rows)
+		if err != nil {
+			return nil, utils.QueryError("GetWishlistByUserID", log, err)
+		}
+		items = append(items, *item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("GetWishlistByUserID", log, err)
+	}
+
+	return items, nil
+}
+
+// Add saves the item, or restores it with a fresh price and stock snapshot when the
+// user has saved it before.
+func (repo *MySqlWishlistRepository) Add(ctx context.Context, request model.WishlistItemRequest, savedPrice money.Money, savedStock uint64, creatingUserId uint64) (*model.WishlistItemResponse, error) {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "INSERT INTO wishlist_items (product_id, variant_id, saved_price, saved_stock, created_user, created_at) VALUES (?, ?, ?, ?, ?, now()) " +
+		"ON DUPLICATE KEY UPDATE id = LAST_INSERT_ID(id), saved_price = VALUES(saved_price), saved_stock = VALUES(saved_stock), deleted_user = NULL, deleted_at = NULL, updated_user = VALUES(created_user), updated_at = now()"
+	log.Debugf("Running query '%s' with parameter '%+v', '%s', '%d' and '%d'", query, request, savedPrice, savedStock, creatingUserId)
+	itemId, err := flows.PerformEdit(
+		ctx,
+		"AddWishlistItem",
+		query,
+		repo.DB,
+		log,
+		request.ProductID, request.VariantID, savedPrice, savedStock, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return repo.GetByID(afterWrite(ctx), uint64(itemId))
+}
+
+func (repo *MySqlWishlistRepository) Remove(ctx context.Context, itemId uint64, deletingUserId uint64) error {
+	log := logger.FromContext(ctx, repo.Logger)
+	query := "UPDATE wishlist_items SET deleted_user = ?, deleted_at = now() WHERE id = ? AND deleted_at IS NULL"
+	log.Debugf("Running query '%s' with parameter '%d' and '%d'", query, deletingUserId, itemId)
+	_, err := flows.PerformEdit(
+		ctx,
+		"RemoveWishlistItem",
+		query,
+		repo.DB,
+		log,
+		deletingUserId, itemId)
+
+	return err
+}
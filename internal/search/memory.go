@@ -0,0 +1,194 @@
+package search
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+)
+
+const (
+	titleWeight  = 2
+	prefixWeight = 0.8
+	typoWeight   = 0.5
+)
+
+type memoryDocument struct {
+	product model.ProductResponse
+	terms   map[string]int
+}
+
+// MemoryIndex is an inverted index held in process. It is built from the products
+// table when a lambda starts, so searches do not wait on full-text queries, and is
+// kept current by indexing products as they are written.
+type MemoryIndex struct {
+	mu        sync.RWMutex
+	documents map[uint64]*memoryDocument
+	postings  map[string]map[uint64]int
+	logger    logger.Logger
+}
+
+func NewMemoryIndex(logger logger.Logger, products []model.ProductResponse) Index {
+	index := &MemoryIndex{
+		documents: make(map[uint64]*memoryDocument),
+		postings:  make(map[string]map[uint64]int),
+		logger:    logger,
+	}
+	for _, product := range products {
+		index.add(product)
+	}
+	logger.Debugf("Built in-memory product index with %d products and %d terms", len(index.documents), len(index.postings))
+	return index
+}
+
+// add expects the caller to hold the write lock.
+func (index *MemoryIndex) add(product model.ProductResponse) {
+	index.remove(product.ID)
+
+	terms := make(map[string]int)
+	for _, term := range Tokenize(product.Title) {
+		terms[term] += titleWeight
+	}
+	for _, term := range Tokenize(product.Description) {
+		terms[term]++
+	}
+
+	index.documents[product.ID] = &memoryDocument{product: product, terms: terms}
+	for term, frequency := range terms {
+		if index.postings[term] == nil {
+			index.postings[term] = make(map[uint64]int)
+		}
+		index.postings[term][product.ID] = frequency
+	}
+}
+
+// remove expects the caller to hold the write lock.
+func (index *MemoryIndex) remove(productId uint64) {
+	document, ok := index.documents[productId]
+	if !ok {
+		return
+	}
+	for term := range document.terms {
+		delete(index.postings[term], productId)
+		if len(index.postings[term]) == 0 {
+			delete(index.postings, term)
+		}
+	}
+	delete(index.documents, productId)
+}
+
+func (index *MemoryIndex) Index(ctx context.Context, product model.ProductResponse) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.add(product)
+	return nil
+}
+
+func (index *MemoryIndex) Remove(ctx context.Context, productId uint64) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.remove(productId)
+	return nil
+}
+
+type termMatch struct {
+	term   string
+	weight float64
+}
+
+// expand finds the indexed terms a query term matches: itself, terms it is a prefix
+// of (the customer may still be typing) and, failing those, terms within its typo
+// allowance.
+func (index *MemoryIndex) expand(term string) ([]termMatch, string) {
+	matches := make([]termMatch, 0)
+	if _, ok := index.postings[term]; ok {
+		matches = append(matches, termMatch{term: term, weight: 1})
+	}
+	for candidate := range index.postings {
+		if candidate != term && strings.HasPrefix(candidate, term) {
+			matches = append(matches, termMatch{term: candidate, weight: prefixWeight})
+		}
+	}
+	if len(matches) > 0 {
+		return matches, ""
+	}
+
+	frequencies := make(map[string]int, len(index.postings))
+	for candidate, documents := range index.postings {
+		if Distance(term, candidate) <= MaxTypos(term) {
+			frequencies[candidate] = len(documents)
+		}
+	}
+	for candidate := range frequencies {
+		matches = append(matches, termMatch{term: candidate, weight: typoWeight})
+	}
+	corrected, _ := closestTerm(term, frequencies)
+	return matches, corrected
+}
+
+func (index *MemoryIndex) Search(ctx context.Context, request model.ProductSearchRequest) (*model.ProductSearchResponse, error) {
+	request = Normalize(request)
+	terms := uniqueTerms(request.Query)
+
+	index.mu.RLock()
+	scores := make(map[uint64]float64)
+	corrections := make(map[string]string)
+	if len(terms) == 0 {
+		for productId := range index.documents {
+			scores[productId] = 0
+		}
+	}
+	for _, term := range terms {
+		matches, corrected := index.expand(term)
+		if corrected != "" {
+			corrections[term] = corrected
+		}
+		for _, match := range matches {
+			documents := index.postings[match.term]
+			idf := math.Log(1 + float64(len(index.documents))/float64(len(documents)))
+			for productId, frequency := range documents {
+				scores[productId] += match.weight * idf * float64(frequency)
+			}
+		}
+	}
+
+	facets := NewPriceFacets()
+	hits := make([]model.ProductSearchHit, 0)
+	for productId, score := range scores {
+		product := index.documents[productId].product
+		if !InCategories(request, product) {
+			continue
+		}
+		CountPrice(facets, product.Price)
+		if InPriceRange(request, product.Price) {
+			hits = append(hits, model.ProductSearchHit{Product: product, Score: score})
+		}
+	}
+	index.mu.RUnlock()
+
+	SortHits(hits, request.Sort, len(terms) > 0)
+	total := len(hits)
+	start := min((request.Page-1)*request.PerPage, total)
+	end := min(start+request.PerPage, total)
+
+	return &model.ProductSearchResponse{
+		Query:          request.Query,
+		CorrectedQuery: correctedQuery(terms, corrections),
+		Sort:           request.Sort,
+		Page:           request.Page,
+		PerPage:        request.PerPage,
+		Total:          uint64(total),
+		Hits:           hits[start:end],
+		PriceFacets:    facets,
+	}, nil
+}
+
+func (index *MemoryIndex) Shutdown() {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	index.documents = make(map[uint64]*memoryDocument)
+	index.postings = make(map[string]map[uint64]int)
+}
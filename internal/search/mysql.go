@@ -0,0 +1,291 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository/flows"
+	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/utils"
+)
+
+// MySqlIndex searches the products table through its FULLTEXT indexes, which MySQL
+// keeps in step with every write. FULLTEXT has no typo tolerance, so query terms are
+// first corrected against a vocabulary of indexed words loaded on first use and
+// extended as products are indexed. Words from removed products stay in the
+// vocabulary; correcting to one just finds nothing.
+type MySqlIndex struct {
+	DB     mysql.DbConnection
+	Logger logger.Logger
+
+	mu          sync.RWMutex
+	loaded      bool
+	frequencies map[string]int
+}
+
+func NewMySqlIndex(logger logger.Logger, db mysql.DbConnection) Index {
+	return &MySqlIndex{
+		DB:          db,
+		Logger:      logger,
+		frequencies: make(map[string]int),
+	}
+}
+
+func (index *MySqlIndex) Shutdown() {
+	err := index.DB.Close()
+	if err != nil {
+		index.Logger.Errorf("Unabled to close search index: %s", err.Error())
+	}
+}
+
+func (index *MySqlIndex) addTerms(text string) {
+	for _, term := range Tokenize(text) {
+		index.frequencies[term]++
+	}
+}
+
+func (index *MySqlIndex) loadVocabulary(ctx context.Context) error {
+	log := logger.FromContext(ctx, index.Logger)
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if index.loaded {
+		return nil
+	}
+
+	query := "SELECT COALESCE(title, ''), COALESCE(description, '') FROM products WHERE deleted_at IS NULL"
+	stmt, err := flows.GetReaderStatement(ctx, "LoadSearchVocabulary", query, index.DB, log)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s'", query)
+
+	rows, err := stmt.Query()
+	if err != nil {
+		return utils.QueryError("LoadSearchVocabulary", log, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var title, description string
+		err = rows.Scan(&title, &description)
+		if err != nil {
+			return utils.QueryError("LoadSearchVocabulary", log, err)
+		}
+		index.addTerms(title)
+		index.addTerms(description)
+	}
+	if err = rows.Err(); err != nil {
+		return utils.QueryError("LoadSearchVocabulary", log, err)
+	}
+
+	index.loaded = true
+	return nil
+}
+
+// correct swaps each unknown query term for its closest known word. Terms that
+// prefix a known word are left alone, since the boolean query matches prefixes.
+func (index *MySqlIndex) correct(ctx context.Context, terms []string) map[string]string {
+	log := logger.FromContext(ctx, index.Logger)
+	corrections := make(map[string]string)
+	err := index.loadVocabulary(ctx)
+	if err != nil {
+		log.Errorf("Searching without typo tolerance: %s", err.Error())
+		return corrections
+	}
+
+	index.mu.RLock()
+	defer index.mu.RUnlock()
+	for _, term := range terms {
+		if _, ok := index.frequencies[term]; ok {
+			continue
+		}
+		prefixed := false
+		for candidate := range index.frequencies {
+			if strings.HasPrefix(candidate, term) {
+				prefixed = true
+				break
+			}
+		}
+		if prefixed {
+			continue
+		}
+		if corrected, ok := closestTerm(term, index.frequencies); ok {
+			corrections[term] = corrected
+		}
+	}
+	return corrections
+}
+
+func (index *MySqlIndex) Index(ctx context.Context, product model.ProductResponse) error {
+	index.mu.Lock()
+	defer index.mu.Unlock()
+	if index.loaded {
+		index.addTerms(product.Title)
+		index.addTerms(product.Description)
+	}
+	return nil
+}
+
+func (index *MySqlIndex) Remove(ctx context.Context, productId uint64) error {
+	return nil
+}
+
+func (index *MySqlIndex) Search(ctx context.Context, request model.ProductSearchRequest) (*model.ProductSearchResponse, error) {
+	request = Normalize(request)
+	terms := uniqueTerms(request.Query)
+	corrections := index.correct(ctx, terms)
+
+	where := "deleted_at IS NULL"
+	whereArgs := make([]any, 0)
+	if len(request.CategoryIDs) > 0 {
+		where += " AND id IN (SELECT product_id FROM product_categories WHERE deleted_at IS NULL AND category_id IN (?" + strings.Repeat(", ?", len(request.CategoryIDs)-1) + "))"
+		for _, categoryId := range request.CategoryIDs {
+			whereArgs = append(whereArgs, categoryId)
+		}
+	}
+	score := "0"
+	scoreArgs := make([]any, 0)
+	if len(terms) > 0 {
+		booleanTerms := make([]string, len(terms))
+		for i, term := range terms {
+			if corrected, ok := corrections[term]; ok {
+				term = corrected
+			}
+			booleanTerms[i] = term + "*"
+		}
+		booleanQuery := strings.Join(booleanTerms, " ")
+		where += " AND MATCH(title, description) AGAINST (? IN BOOLEAN MODE)"
+		whereArgs = append(whereArgs, booleanQuery)
+		score = "MATCH(title, description) AGAINST (? IN BOOLEAN MODE) + MATCH(title) AGAINST (? IN BOOLEAN MODE)"
+		scoreArgs = append(scoreArgs, booleanQuery, booleanQuery)
+	}
+
+	priceFilter := "1 = 1"
+	priceArgs := make([]any, 0)
+	if request.MinPrice != nil {
+		priceFilter += " AND price >= ?"
+		priceArgs = append(priceArgs, *request.MinPrice)
+	}
+	if request.MaxPrice != nil {
+		priceFilter += " AND price <= ?"
+		priceArgs = append(priceArgs, *request.MaxPrice)
+	}
+
+	facets := NewPriceFacets()
+	total, err := index.countFacets(ctx, facets, where, whereArgs, priceFilter, priceArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, err := index.searchPage(ctx, request, where, whereArgs, score, scoreArgs, priceFilter, priceArgs, len(terms) > 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProductSearchResponse{
+		Query:          request.Query,
+		CorrectedQuery: correctedQuery(terms, corrections),
+		Sort:           request.Sort,
+		Page:           request.Page,
+		PerPage:        request.PerPage,
+		Total:          total,
+		Hits:           hits,
+		PriceFacets:    facets,
+	}, nil
+}
+
+// countFacets counts every match into the price facets, ignoring the requested price
+// range so customers can see what widening it would add, and returns the number of
+// matches within the range.
+func (index *MySqlIndex) countFacets(ctx context.Context, facets []model.PriceFacet, where string, whereArgs []any, priceFilter string, priceArgs []any) (uint64, error) {
+	log := logger.FromContext(ctx, index.Logger)
+	columns := []string{"COALESCE(SUM(" + priceFilter + "), 0)"}
+	args := append([]any{}, priceArgs...)
+	for _, facet := range facets {
+		if facet.Max == nil {
+			columns = append(columns, "COALESCE(SUM(price >= ?), 0)")
+			args = append(args, facet.Min)
+			continue
+		}
+		columns = append(columns, "COALESCE(SUM(price >= ? AND price < ?), 0)")
+		args = append(args, facet.Min, *facet.Max)
+	}
+	args = append(args, whereArgs...)
+
+	query := "SELECT " + strings.Join(columns, ", ") + " FROM products WHERE " + where
+	stmt, err := flows.GetDynamicReaderStatement(ctx, "CountProductSearchFacets", query, index.DB, log)
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	var total uint64
+	counts := []any{&total}
+	for i := range facets {
+		counts = append(counts, &facets[i].Count)
+	}
+	err = stmt.QueryRow(args...).Scan(counts...)
+	if err != nil {
+		return 0, utils.QueryError("CountProductSearchFacets", log, err)
+	}
+
+	return total, nil
+}
+
+func (index *MySqlIndex) searchPage(ctx context.Context, request model.ProductSearchRequest, where string, whereArgs []any, score string, scoreArgs []any, priceFilter string, priceArgs []any, hasQuery bool) ([]model.ProductSearchHit, error) {
+	log := logger.FromContext(ctx, index.Logger)
+	orderBy := "score DESC, id"
+	switch {
+	case request.Sort == constant.SEARCH_SORT_PRICE_ASC:
+		orderBy = "price, id"
+	case request.Sort == constant.SEARCH_SORT_PRICE_DESC:
+		orderBy = "price DESC, id"
+	case request.Sort == constant.SEARCH_SORT_TITLE:
+		orderBy = "title, id"
+	case request.Sort == constant.SEARCH_SORT_NEWEST || !hasQuery:
+		orderBy = "created_at DESC, id DESC"
+	}
+
+	query := "SELECT id, sku, title, description, price, stock, tax_class_id, weight, (SELECT GROUP_CONCAT(pc.category_id ORDER BY pc.category_id) FROM product_categories pc JOIN categories c ON c.id = pc.category_id WHERE pc.product_id = products.id AND pc.deleted_at IS NULL AND c.deleted_at IS NULL), (SELECT ROUND(AVG(r.rating), 2) FROM reviews r WHERE r.product_id = products.id AND r.status_id = ? AND r.deleted_at IS NULL), (SELECT COUNT(*) FROM reviews r WHERE r.product_id = products.id AND r.status_id = ? AND r.deleted_at IS NULL), created_user, created_at, updated_user, updated_at, " + score + " AS score FROM products WHERE " + where + " AND " + priceFilter + " ORDER BY " + orderBy + " LIMIT ? OFFSET ?"
+	args := append(append(append(append([]any{constant.REVIEW_STATUS_APPROVED, constant.REVIEW_STATUS_APPROVED}, scoreArgs...), whereArgs...), priceArgs...), request.PerPage, (request.Page-1)*request.PerPage)
+	stmt, err := flows.GetDynamicReaderStatement(ctx, "SearchProducts", query, index.DB, log)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+	log.Debugf("Running query '%s' with parameter '%v'", query, args)
+
+	rows, err := stmt.Query(args...)
+	if err != nil {
+		return nil, utils.QueryError("SearchProducts", log, err)
+	}
+	defer rows.Close()
+
+	hits := make([]model.ProductSearchHit, 0)
+	for rows.Next() {
+		var hit model.ProductSearchHit
+		var categoryIds sql.NullString
+		product := &hit.Product
+		err = rows.Scan(&product.ID, &product.SKU, &product.Title, &product.Description, &product.Price, &product.Stock, &product.TaxClassID, &product.Weight, &categoryIds, &product.RatingAverage, &product.RatingCount, &product.CreatedUser, &product.CreatedAt, &product.UpdatedUser, &product.UpdatedAt, &hit.Score)
+		if err != nil {
+			return nil, utils.QueryError("SearchProducts", log, err)
+		}
+		product.CategoryIDs, err = utils.ParseIDList(categoryIds.String)
+		if err != nil {
+			return nil, utils.QueryError("SearchProducts", log, err)
+		}
+		hits = append(hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, utils.QueryError("SearchProducts", log, err)
+	}
+
+	return hits, nil
+}
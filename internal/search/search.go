@@ -0,0 +1,215 @@
+package search
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+)
+
+// Index is a product search index. Implementations rank matches on title and
+// description, tolerate small typos and count price facets over every match.
+type Index interface {
+	Search(ctx context.Context, request model.ProductSearchRequest) (*model.ProductSearchResponse, error)
+	Index(ctx context.Context, product model.ProductResponse) error
+	Remove(ctx context.Context, productId uint64) error
+	Shutdown()
+}
+
+// PriceRanges are the lower bounds, in minor units, of the price facets. The last
+// facet is open ended.
+var PriceRanges = []int64{0, 10000, 25000, 50000, 100000}
+
+// Normalize fills in the defaults for sort and paging.
+func Normalize(request model.ProductSearchRequest) model.ProductSearchRequest {
+	request.Query = strings.TrimSpace(request.Query)
+	if request.Sort == "" {
+		request.Sort = constant.SEARCH_SORT_RELEVANCE
+	}
+	if request.Page < 1 {
+		request.Page = 1
+	}
+	if request.PerPage < 1 {
+		request.PerPage = constant.SEARCH_DEFAULT_PER_PAGE
+	}
+	return request
+}
+
+// Tokenize lower cases text and splits it on anything that is not a letter or digit.
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func uniqueTerms(query string) []string {
+	seen := make(map[string]bool)
+	terms := make([]string, 0)
+	for _, term := range Tokenize(query) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// MaxTypos is how many edits a query term may be from an indexed term and still
+// match. Short terms must match exactly, since one edit changes them too much.
+func MaxTypos(term string) int {
+	length := len([]rune(term))
+	switch {
+	case length < 4:
+		return 0
+	case length < 8:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Distance is the edit distance between a and b, counting an insertion, deletion,
+// substitution or swap of two neighbouring characters as one edit.
+func Distance(a string, b string) int {
+	source, target := []rune(a), []rune(b)
+	rows := make([][]int, len(source)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(target)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return rows[len(source)][len(target)]
+}
+
+// closestTerm finds the indexed term nearest to term within its typo allowance,
+// preferring the more common term on a tie.
+func closestTerm(term string, frequencies map[string]int) (string, bool) {
+	best, bestDistance, bestFrequency := "", MaxTypos(term)+1, 0
+	for candidate, frequency := range frequencies {
+		distance := Distance(term, candidate)
+		if distance < bestDistance || (distance == bestDistance && (frequency > bestFrequency || (frequency == bestFrequency && candidate < best))) {
+			best, bestDistance, bestFrequency = candidate, distance, frequency
+		}
+	}
+	return best, best != ""
+}
+
+// NewPriceFacets returns an empty facet for every price range.
+func NewPriceFacets() []model.PriceFacet {
+	facets := make([]model.PriceFacet, len(PriceRanges))
+	for i, lower := range PriceRanges {
+		facets[i].Min = money.New(lower, money.DefaultCurrency)
+		if i+1 < len(PriceRanges) {
+			upper := money.New(PriceRanges[i+1], money.DefaultCurrency)
+			facets[i].Max = &upper
+		}
+	}
+	return facets
+}
+
+// CountPrice adds price to the facet covering it.
+func CountPrice(facets []model.PriceFacet, price money.Money) {
+	for i := len(facets) - 1; i >= 0; i-- {
+		if price.Minor() >= facets[i].Min.Minor() {
+			facets[i].Count++
+			return
+		}
+	}
+}
+
+// InPriceRange reports whether price falls within the request's inclusive bounds.
+func InPriceRange(request model.ProductSearchRequest, price money.Money) bool {
+	if request.MinPrice != nil && price.Minor() < request.MinPrice.Minor() {
+		return false
+	}
+	if request.MaxPrice != nil && price.Minor() > request.MaxPrice.Minor() {
+		return false
+	}
+	return true
+}
+
+// InCategories reports whether the product is in any of the requested categories.
+// A request without categories matches every product.
+func InCategories(request model.ProductSearchRequest, product model.ProductResponse) bool {
+	if len(request.CategoryIDs) == 0 {
+		return true
+	}
+	for _, wanted := range request.CategoryIDs {
+		for _, categoryId := range product.CategoryIDs {
+			if categoryId == wanted {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SortHits orders hits for the requested sort. Relevance falls back to newest first
+// when there is no query to rank against.
+func SortHits(hits []model.ProductSearchHit, sortBy string, hasQuery bool) {
+	if sortBy == constant.SEARCH_SORT_RELEVANCE && !hasQuery {
+		sortBy = constant.SEARCH_SORT_NEWEST
+	}
+	sort.SliceStable(hits, func(i, j int) bool {
+		a, b := hits[i].Product, hits[j].Product
+		switch sortBy {
+		case constant.SEARCH_SORT_PRICE_ASC:
+			if a.Price.Minor() != b.Price.Minor() {
+				return a.Price.Minor() < b.Price.Minor()
+			}
+		case constant.SEARCH_SORT_PRICE_DESC:
+			if a.Price.Minor() != b.Price.Minor() {
+				return a.Price.Minor() > b.Price.Minor()
+			}
+		case constant.SEARCH_SORT_NEWEST:
+			if a.CreatedAt != b.CreatedAt {
+				return a.CreatedAt > b.CreatedAt
+			}
+			return a.ID > b.ID
+		case constant.SEARCH_SORT_TITLE:
+			if !strings.EqualFold(a.Title, b.Title) {
+				return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+			}
+		default:
+			if hits[i].Score != hits[j].Score {
+				return hits[i].Score > hits[j].Score
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
+func correctedQuery(terms []string, corrections map[string]string) *string {
+	if len(corrections) == 0 {
+		return nil
+	}
+	corrected := make([]string, len(terms))
+	for i, term := range terms {
+		corrected[i] = term
+		if replacement, ok := corrections[term]; ok {
+			corrected[i] = replacement
+		}
+	}
+	query := strings.Join(corrected, " ")
+	return &query
+}
@@ -0,0 +1,153 @@
+package search_test
+
+import (
+	"context"
+	"testing"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/search"
+)
+
+func zar(minor int64) money.Money {
+	return money.New(minor, money.DefaultCurrency)
+}
+
+func testProducts() []model.ProductResponse {
+	return []model.ProductResponse{
+		{ID: 1, Title: "Oak Dining Chair", Description: "Solid oak chair with a cushioned seat", Price: zar(89900), CreatedAt: "2026-10-01T10:00:00Z"},
+		{ID: 2, Title: "Garden Bench", Description: "Weatherproof bench, seats three", Price: zar(149900), CreatedAt: "2026-10-02T10:00:00Z"},
+		{ID: 3, Title: "Chair Cushion", Description: "Cushion for any dining chair", Price: zar(19900), CreatedAt: "2026-10-03T10:00:00Z"},
+		{ID: 4, Title: "Desk Lamp", Description: "Adjustable lamp for the study", Price: zar(34900), CreatedAt: "2026-10-04T10:00:00Z"},
+	}
+}
+
+func hitIds(response *model.ProductSearchResponse) []uint64 {
+	ids := make([]uint64, len(response.Hits))
+	for i, hit := range response.Hits {
+		ids[i] = hit.Product.ID
+	}
+	return ids
+}
+
+func TestDistance_withTypos_shouldCountEdits(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		expected int
+	}{
+		{"chair", "chair", 0},
+		{"chiar", "chair", 1},
+		{"chai", "chair", 1},
+		{"cushon", "cushion", 1},
+		{"lamp", "desk", 4},
+	}
+	for _, c := range cases {
+		if result := search.Distance(c.a, c.b); result != c.expected {
+			t.Errorf("Expected distance %d between '%s' and '%s' but got %d", c.expected, c.a, c.b, result)
+		}
+	}
+	if search.MaxTypos("oak") != 0 || search.MaxTypos("chair") != 1 || search.MaxTypos("adjustable") != 2 {
+		t.Error("Unexpected typo allowance")
+	}
+}
+
+func TestMemoryIndex_withTitleAndDescriptionMatches_shouldRankTitleFirst(t *testing.T) {
+	index := search.NewMemoryIndex(logger.NewSimpleLogger("ERROR", false), testProducts())
+
+	response, err := index.Search(context.Background(), model.ProductSearchRequest{Query: "chair"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	ids := hitIds(response)
+	if len(ids) != 2 || response.Total != 2 {
+		t.Fatalf("Expected two chairs but got %v", ids)
+	}
+	if response.Hits[0].Score < response.Hits[1].Score {
+		t.Errorf("Expected hits ordered by score but got %v", response.Hits)
+	}
+	if response.CorrectedQuery != nil {
+		t.Errorf("Expected no correction but got '%s'", *response.CorrectedQuery)
+	}
+}
+
+func TestMemoryIndex_withTypoAndPrefix_shouldStillMatch(t *testing.T) {
+	index := search.NewMemoryIndex(logger.NewSimpleLogger("ERROR", false), testProducts())
+
+	response, _ := index.Search(context.Background(), model.ProductSearchRequest{Query: "lmap"})
+	if ids := hitIds(response); len(ids) != 1 || ids[0] != 4 {
+		t.Errorf("Expected the lamp for a swapped letter but got %v", ids)
+	}
+	if response.CorrectedQuery == nil || *response.CorrectedQuery != "lamp" {
+		t.Errorf("Expected 'lamp' as the corrected query but got %v", response.CorrectedQuery)
+	}
+
+	response, _ = index.Search(context.Background(), model.ProductSearchRequest{Query: "gard"})
+	if ids := hitIds(response); len(ids) != 1 || ids[0] != 2 {
+		t.Errorf("Expected the bench for a prefix but got %v", ids)
+	}
+}
+
+func TestMemoryIndex_withPriceFilter_shouldFacetAllMatches(t *testing.T) {
+	index := search.NewMemoryIndex(logger.NewSimpleLogger("ERROR", false), testProducts())
+	maxPrice := zar(50000)
+
+	response, _ := index.Search(context.Background(), model.ProductSearchRequest{MaxPrice: &maxPrice, Sort: constant.SEARCH_SORT_PRICE_ASC})
+	if ids := hitIds(response); len(ids) != 2 || ids[0] != 3 || ids[1] != 4 {
+		t.Errorf("Expected the cushion then the lamp but got %v", ids)
+	}
+	expectedCounts := []uint64{0, 1, 1, 1, 1}
+	for i, facet := range response.PriceFacets {
+		if facet.Count != expectedCounts[i] {
+			t.Errorf("Expected %d products from %s but got %d", expectedCounts[i], facet.Min, facet.Count)
+		}
+	}
+	if response.PriceFacets[len(response.PriceFacets)-1].Max != nil {
+		t.Error("Expected the last facet to be open ended")
+	}
+}
+
+func TestMemoryIndex_withWrites_shouldStayInSync(t *testing.T) {
+	index := search.NewMemoryIndex(logger.NewSimpleLogger("ERROR", false), testProducts())
+
+	index.Remove(context.Background(), 4)
+	updated := testProducts()[0]
+	updated.Title = "Oak Stool"
+	updated.Description = "Solid oak stool"
+	index.Index(context.Background(), updated)
+
+	response, _ := index.Search(context.Background(), model.ProductSearchRequest{Query: "lamp"})
+	if response.Total != 0 {
+		t.Errorf("Expected the removed lamp to be gone but got %v", hitIds(response))
+	}
+	response, _ = index.Search(context.Background(), model.ProductSearchRequest{Query: "chair"})
+	if ids := hitIds(response); len(ids) != 1 || ids[0] != 3 {
+		t.Errorf("Expected only the cushion to mention chairs but got %v", ids)
+	}
+
+	response, _ = index.Search(context.Background(), model.ProductSearchRequest{PerPage: 2, Page: 2})
+	if ids := hitIds(response); response.Total != 3 || len(ids) != 1 || ids[0] != 1 {
+		t.Errorf("Expected the oldest product alone on page two but got %v of %d", ids, response.Total)
+	}
+}
+
+func TestMemoryIndex_withCategoryFilter_shouldOnlyFacetThoseCategories(t *testing.T) {
+	products := testProducts()
+	products[0].CategoryIDs = []uint64{2}
+	products[2].CategoryIDs = []uint64{3}
+	products[3].CategoryIDs = []uint64{5}
+	index := search.NewMemoryIndex(logger.NewSimpleLogger("ERROR", false), products)
+
+	response, _ := index.Search(context.Background(), model.ProductSearchRequest{CategoryIDs: []uint64{2, 3}, Sort: constant.SEARCH_SORT_TITLE})
+	if ids := hitIds(response); len(ids) != 2 || ids[0] != 3 || ids[1] != 1 {
+		t.Errorf("Expected the cushion then the dining chair but got %v", ids)
+	}
+	var faceted uint64
+	for _, facet := range response.PriceFacets {
+		faceted += facet.Count
+	}
+	if faceted != 2 {
+		t.Errorf("Expected facets over the two categorised products but got %d", faceted)
+	}
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Analytics interface {
+	Chart(ctx context.Context, request model.ChartRequest) (*model.ChartResponse, error)
+	RefreshSummaries(ctx context.Context) error
+	Shutdown()
+}
+
+type AnalyticsService struct {
+	analyticsRepo repository.AnalyticsRepository
+	logger        logger.Logger
+}
+
+func NewAnalyticsService(analyticsRepo repository.AnalyticsRepository, logger logger.Logger) Analytics {
+	return &AnalyticsService{
+		analyticsRepo: analyticsRepo,
+		logger:        logger,
+	}
+}
+
+// BucketStart returns the start of the day, ISO week (Monday) or month containing t
+// in the given location.
+func BucketStart(t time.Time, interval string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch interval {
+	case constant.CHART_INTERVAL_WEEK:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case constant.CHART_INTERVAL_MONTH:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+func nextBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case constant.CHART_INTERVAL_WEEK:
+		return start.AddDate(0, 0, 7)
+	case constant.CHART_INTERVAL_MONTH:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+func bucketLabel(start time.Time, interval string) string {
+	if interval == constant.CHART_INTERVAL_MONTH {
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
+
+// BuildChart rolls the hourly UTC summaries up into one point per interval between
+// from and to (exclusive) in loc. Buckets without sales are kept as zeros so every
+// series lines up with the labels. Zones with a non whole hour offset are
+// approximated to the hour the summaries were taken in.
+func BuildChart(hourly []model.SalesSummary, from time.Time, to time.Time, interval string, loc *time.Location) ([]string, model.ChartSeries, error) {
+	labels := make([]string, 0)
+	series := model.ChartSeries{
+		Revenue:           make([]money.Money, 0),
+		OrderCount:        make([]uint64, 0),
+		AverageOrderValue: make([]money.Money, 0),
+	}
+	index := make(map[string]int)
+	for start := BucketStart(from, interval, loc); start.Before(to); start = nextBucket(start, interval) {
+		label := bucketLabel(start, interval)
+		index[label] = len(labels)
+		labels = append(labels, label)
+		series.Revenue = append(series.Revenue, money.Zero(money.DefaultCurrency))
+		series.OrderCount = append(series.OrderCount, 0)
+	}
+
+	for _, summary := range hourly {
+		if summary.BucketStart.Before(from) || !summary.BucketStart.Before(to) {
+			continue
+		}
+		i, ok := index[bucketLabel(BucketStart(summary.BucketStart, interval, loc), interval)]
+		if !ok {
+			continue
+		}
+		revenue, err := series.Revenue[i].Add(summary.Revenue)
+		if err != nil {
+			return nil, model.ChartSeries{}, err
+		}
+		series.Revenue[i] = revenue
+		series.OrderCount[i] += summary.OrderCount
+	}
+
+	for i, revenue := range series.Revenue {
+		if series.OrderCount[i] == 0 {
+			series.AverageOrderValue = append(series.AverageOrderValue, money.Zero(revenue.Currency()))
+			continue
+		}
+		average, err := revenue.MulRatio(1, int64(series.OrderCount[i]), money.HalfUp)
+		if err != nil {
+			return nil, model.ChartSeries{}, err
+		}
+		series.AverageOrderValue = append(series.AverageOrderValue, average)
+	}
+
+	return labels, series, nil
+}
+
+func (a *AnalyticsService) parseChartRequest(ctx context.Context, request model.ChartRequest) (time.Time, time.Time, *time.Location, error) {
+	log := logger.FromContext(ctx, a.logger)
+	timezone := request.Timezone
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		log.Infof("Unknown chart timezone '%s'", timezone)
+		return time.Time{}, time.Time{}, nil, types.NewInvalidInputError()
+	}
+
+	today := BucketStart(time.Now(), constant.CHART_INTERVAL_DAY, loc)
+	to := today.AddDate(0, 0, 1)
+	if request.To != "" {
+		day, err := time.ParseInLocation("2006-01-02", request.To, loc)
+		if err != nil {
+			log.Infof("Invalid chart end date '%s'", request.To)
+			return time.Time{}, time.Time{}, nil, types.NewInvalidInputError()
+		}
+		to = day.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -constant.ANALYTICS_DEFAULT_RANGE_DAYS)
+	if request.From != "" {
+		from, err = time.ParseInLocation("2006-01-02", request.From, loc)
+		if err != nil {
+			log.Infof("Invalid chart start date '%s'", request.From)
+			return time.Time{}, time.Time{}, nil, types.NewInvalidInputError()
+		}
+	}
+
+	if !from.Before(to) || from.AddDate(0, 0, constant.ANALYTICS_MAX_RANGE_DAYS).Before(to) {
+		log.Infof("Chart range '%s' to '%s' is empty or longer than %d days", from, to, constant.ANALYTICS_MAX_RANGE_DAYS)
+		return time.Time{}, time.Time{}, nil, types.NewInvalidInputError()
+	}
+
+	return from, to, loc, nil
+}
+
+func (a *AnalyticsService) Chart(ctx context.Context, request model.ChartRequest) (*model.ChartResponse, error) {
+	ctx, span := tracing.Start(ctx, "AnalyticsService.Chart")
+	defer span.End()
+	log := logger.FromContext(ctx, a.logger)
+
+	if request.Interval == "" {
+		request.Interval = constant.CHART_INTERVAL_DAY
+	}
+	switch request.Interval {
+	case constant.CHART_INTERVAL_DAY, constant.CHART_INTERVAL_WEEK, constant.CHART_INTERVAL_MONTH:
+	default:
+		log.Infof("Unknown chart interval '%s'", request.Interval)
+		return nil, types.NewInvalidInputError()
+	}
+	if request.Limit == 0 {
+		request.Limit = constant.ANALYTICS_TOP_PRODUCTS_LIMIT
+	}
+	if request.Limit < 0 || request.Limit > constant.ANALYTICS_MAX_PRODUCTS_LIMIT {
+		log.Infof("Invalid top products limit '%d'", request.Limit)
+		return nil, types.NewInvalidInputError()
+	}
+
+	from, to, loc, err := a.parseChartRequest(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	hourly, err := a.analyticsRepo.GetSalesSummaries(ctx, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	labels, series, err := BuildChart(hourly, from, to, request.Interval, loc)
+	if err != nil {
+		log.Errorf("Unabled to build chart: %s", err.Error())
+		return nil, types.NewInternalServerError()
+	}
+	topProducts, err := a.analyticsRepo.GetTopProducts(ctx, from.UTC(), to.UTC(), request.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ChartResponse{
+		Interval:    request.Interval,
+		Timezone:    loc.String(),
+		From:        from.Format("2006-01-02"),
+		To:          to.AddDate(0, 0, -1).Format("2006-01-02"),
+		Labels:      labels,
+		Series:      series,
+		TopProducts: topProducts,
+	}, nil
+}
+
+// RefreshSummaries rebuilds the summaries for the last few days, which covers late
+// status changes such as cancellations on recent orders.
+func (a *AnalyticsService) RefreshSummaries(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "AnalyticsService.RefreshSummaries")
+	defer span.End()
+
+	now := time.Now().UTC()
+	to := now.Truncate(time.Hour).Add(time.Hour)
+	from := to.AddDate(0, 0, -constant.ANALYTICS_REFRESH_LOOKBACK)
+	return a.analyticsRepo.RefreshSummaries(ctx, from, to, now.Truncate(time.Second))
+}
+
+func (a *AnalyticsService) Shutdown() {
+	a.analyticsRepo.Shutdown()
+}
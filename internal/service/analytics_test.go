@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func TestBucketStart_withWeekInterval_shouldStartOnMonday(t *testing.T) {
+	sunday := time.Date(2026, 10, 25, 18, 30, 0, 0, time.UTC)
+
+	start := service.BucketStart(sunday, constant.CHART_INTERVAL_WEEK, time.UTC)
+	if !start.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the week to start on Monday the 19th but got %s", start)
+	}
+	start = service.BucketStart(sunday, constant.CHART_INTERVAL_MONTH, time.UTC)
+	if !start.Equal(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the first of the month but got %s", start)
+	}
+}
+
+func TestBuildChart_withTimezone_shouldShiftHourlyBucketsAndFillGaps(t *testing.T) {
+	johannesburg, err := time.LoadLocation("Africa/Johannesburg")
+	if err != nil {
+		t.Skipf("Time zone data unavailable: %s", err.Error())
+	}
+	from := time.Date(2026, 10, 19, 0, 0, 0, 0, johannesburg)
+	to := from.AddDate(0, 0, 3)
+	hourly := []model.SalesSummary{
+		// 22:00 UTC on the 18th is midnight on the 19th in Johannesburg.
+		{BucketStart: time.Date(2026, 10, 18, 22, 0, 0, 0, time.UTC), OrderCount: 2, Revenue: zar(10000)},
+		{BucketStart: time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC), OrderCount: 1, Revenue: zar(5001)},
+		{BucketStart: time.Date(2026, 10, 21, 21, 0, 0, 0, time.UTC), OrderCount: 1, Revenue: zar(7000)},
+		// Outside the range.
+		{BucketStart: time.Date(2026, 10, 21, 22, 0, 0, 0, time.UTC), OrderCount: 9, Revenue: zar(90000)},
+	}
+
+	labels, series, err := service.BuildChart(hourly, from, to, constant.CHART_INTERVAL_DAY, johannesburg)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	expectedLabels := []string{"2026-10-19", "2026-10-20", "2026-10-21"}
+	if len(labels) != len(expectedLabels) {
+		t.Fatalf("Expected labels %v but got %v", expectedLabels, labels)
+	}
+	for i := range labels {
+		if labels[i] != expectedLabels[i] {
+			t.Errorf("Expected label '%s' but got '%s'", expectedLabels[i], labels[i])
+		}
+	}
+	if series.OrderCount[0] != 3 || series.Revenue[0].Minor() != 15001 || series.AverageOrderValue[0].Minor() != 5000 {
+		t.Errorf("Unexpected first bucket: %d orders, %s revenue, %s average", series.OrderCount[0], series.Revenue[0], series.AverageOrderValue[0])
+	}
+	if series.OrderCount[1] != 0 || !series.Revenue[1].IsZero() || !series.AverageOrderValue[1].IsZero() {
+		t.Errorf("Expected an empty second bucket but got %d orders", series.OrderCount[1])
+	}
+	if series.OrderCount[2] != 1 || series.Revenue[2].Minor() != 7000 {
+		t.Errorf("Expected the last hour of the 21st in the last bucket but got %d orders", series.OrderCount[2])
+	}
+}
+
+func TestBuildChart_withMonthInterval_shouldLabelByMonth(t *testing.T) {
+	from := time.Date(2026, 9, 15, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+
+	labels, series, err := service.BuildChart(nil, from, to, constant.CHART_INTERVAL_MONTH, time.UTC)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err.Error())
+	}
+	if len(labels) != 3 || labels[0] != "2026-09" || labels[2] != "2026-11" {
+		t.Errorf("Expected September to November but got %v", labels)
+	}
+	if len(series.Revenue) != 3 || len(series.AverageOrderValue) != 3 {
+		t.Errorf("Expected a point per label but got %d", len(series.Revenue))
+	}
+}
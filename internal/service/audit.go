@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+)
+
+type Audit interface {
+	QueryAuditLog(ctx context.Context, request model.AuditLogRequest) (*model.AuditLogPageResponse, error)
+	Shutdown()
+}
+
+type AuditService struct {
+	validator Validator
+	auditRepo repository.AuditRepository
+	logger    logger.Logger
+}
+
+func NewAuditService(validator Validator, auditRepo repository.AuditRepository, logger logger.Logger) Audit {
+	return &AuditService{
+		validator: validator,
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+func (a *AuditService) QueryAuditLog(ctx context.Context, request model.AuditLogRequest) (*model.AuditLogPageResponse, error) {
+	ctx, span := tracing.Start(ctx, "AuditService.QueryAuditLog")
+	defer span.End()
+
+	err := a.validator.ValidateREQ(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Page == 0 {
+		request.Page = 1
+	}
+	if request.PerPage == 0 {
+		request.PerPage = constant.AUDIT_DEFAULT_PER_PAGE
+	}
+
+	entries, total, err := a.auditRepo.Query(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuditLogPageResponse{
+		PagingResponse: model.PagingResponse{
+			TotalRecords: total,
+			Page:         request.Page,
+			ItemsPerPage: request.PerPage,
+		},
+		Entries: entries,
+	}, nil
+}
+
+func (a *AuditService) Shutdown() {
+	a.auditRepo.Shutdown()
+}
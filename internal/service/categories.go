@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Categories interface {
+	AllCategories(ctx context.Context) ([]model.CategoryNode, error)
+	GetCategory(ctx context.Context, slug string) (*model.CategoryDetailResponse, error)
+	CreateCategory(ctx context.Context, body string, creatingUserId uint64) (*model.CategoryResponse, error)
+	UpdateCategory(ctx context.Context, categoryId uint64, body string, updatingUserId uint64) (*model.CategoryResponse, error)
+	DeleteCategory(ctx context.Context, categoryId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type CategoriesService struct {
+	validator    Validator
+	categoryRepo repository.CategoryRepository
+	logger       logger.Logger
+}
+
+func NewCategoriesService(validator Validator, categoryRepo repository.CategoryRepository, logger logger.Logger) Categories {
+	return &CategoriesService{
+		validator:    validator,
+		categoryRepo: categoryRepo,
+		logger:       logger,
+	}
+}
+
+// Slugify lower cases text and joins its words with hyphens for use in URLs.
+func Slugify(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, "-")
+}
+
+func findCategory(categories []model.CategoryResponse, categoryId uint64) (*model.CategoryResponse, bool) {
+	for i := range categories {
+		if categories[i].ID == categoryId {
+			return &categories[i], true
+		}
+	}
+	return nil, false
+}
+
+// CategoryTrail returns the breadcrumbs from the top level category down to and
+// including the given one. A parent missing from categories ends the trail.
+func CategoryTrail(categories []model.CategoryResponse, categoryId uint64) []model.Breadcrumb {
+	trail := make([]model.Breadcrumb, 0)
+	seen := make(map[uint64]bool)
+	for id := &categoryId; id != nil && !seen[*id]; {
+		seen[*id] = true
+		category, ok := findCategory(categories, *id)
+		if !ok {
+			break
+		}
+		trail = append([]model.Breadcrumb{{ID: category.ID, Name: category.Name, Slug: category.Slug}}, trail...)
+		id = category.ParentID
+	}
+	return trail
+}
+
+// DescendantIDs returns the category and every category nested beneath it.
+func DescendantIDs(categories []model.CategoryResponse, categoryId uint64) []uint64 {
+	ids := []uint64{categoryId}
+	seen := map[uint64]bool{categoryId: true}
+	for i := 0; i < len(ids); i++ {
+		for _, category := range categories {
+			if category.ParentID != nil && *category.ParentID == ids[i] && !seen[category.ID] {
+				seen[category.ID] = true
+				ids = append(ids, category.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// BuildCategoryTree nests the categories under their parents. Categories whose
+// parent is missing are treated as top level.
+func BuildCategoryTree(categories []model.CategoryResponse) []model.CategoryNode {
+	var build func(parentId *uint64) []model.CategoryNode
+	build = func(parentId *uint64) []model.CategoryNode {
+		nodes := make([]model.CategoryNode, 0)
+		for _, category := range categories {
+			isTopLevel := category.ParentID == nil
+			if category.ParentID != nil {
+				_, hasParent := findCategory(categories, *category.ParentID)
+				isTopLevel = !hasParent
+			}
+			if (parentId == nil && isTopLevel) || (parentId != nil && !isTopLevel && *category.ParentID == *parentId) {
+				id := category.ID
+				nodes = append(nodes, model.CategoryNode{CategoryResponse: category, Children: build(&id)})
+			}
+		}
+		return nodes
+	}
+	return build(nil)
+}
+
+// AttachBreadcrumbs sets a breadcrumb trail on the product for each of its categories.
+func AttachBreadcrumbs(product *model.ProductResponse, categories []model.CategoryResponse) {
+	product.Breadcrumbs = make([][]model.Breadcrumb, 0, len(product.CategoryIDs))
+	for _, categoryId := range product.CategoryIDs {
+		trail := CategoryTrail(categories, categoryId)
+		if len(trail) > 0 {
+			product.Breadcrumbs = append(product.Breadcrumbs, trail)
+		}
+	}
+}
+
+func (c *CategoriesService) validateCategoryRequest(ctx context.Context, body string, categoryId uint64, categories []model.CategoryResponse) (*model.CategoryRequest, error) {
+	log := logger.FromContext(ctx, c.logger)
+	var categoryRequest model.CategoryRequest
+	err := c.validator.MarshalAndValidateREQ(body, &categoryRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if categoryRequest.Slug == "" {
+		categoryRequest.Slug = categoryRequest.Name
+	}
+	categoryRequest.Slug = Slugify(categoryRequest.Slug)
+	if categoryRequest.Slug == "" {
+		log.Infof("Category '%s' has no usable slug", categoryRequest.Name)
+		return nil, types.NewInvalidInputError()
+	}
+	for _, category := range categories {
+		if category.Slug == categoryRequest.Slug && category.ID != categoryId {
+			log.Infof("Category slug '%s' is already used by category '%d'", categoryRequest.Slug, category.ID)
+			return nil, types.NewBadRequestError()
+		}
+	}
+
+	if categoryRequest.ParentID != nil {
+		if _, ok := findCategory(categories, *categoryRequest.ParentID); !ok {
+			log.Infof("Parent category '%d' does not exist", *categoryRequest.ParentID)
+			return nil, types.NewInvalidInputError()
+		}
+		if categoryId != 0 {
+			for _, descendantId := range DescendantIDs(categories, categoryId) {
+				if descendantId == *categoryRequest.ParentID {
+					log.Infof("Category '%d' cannot be nested under itself or its descendant '%d'", categoryId, descendantId)
+					return nil, types.NewInvalidInputError()
+				}
+			}
+		}
+	}
+
+	return &categoryRequest, nil
+}
+
+func (c *CategoriesService) AllCategories(ctx context.Context) ([]model.CategoryNode, error) {
+	ctx, span := tracing.Start(ctx, "CategoriesService.AllCategories")
+	defer span.End()
+
+	categories, err := c.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildCategoryTree(categories), nil
+}
+
+func (c *CategoriesService) GetCategory(ctx context.Context, slug string) (*model.CategoryDetailResponse, error) {
+	ctx, span := tracing.Start(ctx, "CategoriesService.GetCategory")
+	defer span.End()
+	log := logger.FromContext(ctx, c.logger)
+
+	categories, err := c.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.Slug != slug {
+			continue
+		}
+		children := make([]model.CategoryResponse, 0)
+		for _, child := range categories {
+			if child.ParentID != nil && *child.ParentID == category.ID {
+				children = append(children, child)
+			}
+		}
+		return &model.CategoryDetailResponse{
+			Category:    category,
+			Breadcrumbs: CategoryTrail(categories, category.ID),
+			Children:    children,
+		}, nil
+	}
+
+	log.Debugf("No category with slug '%s'", slug)
+	return nil, types.NewNoTFoundOrNoRecordError()
+}
+
+func (c *CategoriesService) CreateCategory(ctx context.Context, body string, creatingUserId uint64) (*model.CategoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "CategoriesService.CreateCategory")
+	defer span.End()
+
+	categories, err := c.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	categoryRequest, err := c.validateCategoryRequest(ctx, body, 0, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.categoryRepo.Create(ctx, *categoryRequest, creatingUserId)
+}
+
+func (c *CategoriesService) UpdateCategory(ctx context.Context, categoryId uint64, body string, updatingUserId uint64) (*model.CategoryResponse, error) {
+	ctx, span := tracing.Start(ctx, "CategoriesService.UpdateCategory")
+	defer span.End()
+
+	categories, err := c.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := findCategory(categories, categoryId); !ok {
+		return nil, types.NewNoTFoundOrNoRecordError()
+	}
+
+	categoryRequest, err := c.validateCategoryRequest(ctx, body, categoryId, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.categoryRepo.Update(ctx, categoryId, *categoryRequest, updatingUserId)
+}
+
+func (c *CategoriesService) DeleteCategory(ctx context.Context, categoryId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "CategoriesService.DeleteCategory")
+	defer span.End()
+	log := logger.FromContext(ctx, c.logger)
+
+	categories, err := c.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := findCategory(categories, categoryId); !ok {
+		return types.NewNoTFoundOrNoRecordError()
+	}
+	if len(DescendantIDs(categories, categoryId)) > 1 {
+		log.Infof("Category '%d' still has child categories", categoryId)
+		return types.NewBadRequestError()
+	}
+
+	return c.categoryRepo.Delete(ctx, categoryId, deletingUserId)
+}
+
+func (c *CategoriesService) Shutdown() {
+	c.categoryRepo.Shutdown()
+}
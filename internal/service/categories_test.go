@@ -0,0 +1,82 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func parent(id uint64) *uint64 {
+	return &id
+}
+
+func testCategories() []model.CategoryResponse {
+	return []model.CategoryResponse{
+		{ID: 1, Name: "Furniture", Slug: "furniture"},
+		{ID: 2, ParentID: parent(1), Name: "Chairs", Slug: "chairs"},
+		{ID: 3, ParentID: parent(2), Name: "Dining Chairs", Slug: "dining-chairs"},
+		{ID: 4, ParentID: parent(1), Name: "Tables", Slug: "tables"},
+		{ID: 5, Name: "Lighting", Slug: "lighting"},
+	}
+}
+
+func TestSlugify_withPunctuationAndCase_shouldHyphenateWords(t *testing.T) {
+	cases := map[string]string{
+		"Dining Chairs":       "dining-chairs",
+		"  Tables & Desks!  ": "tables-desks",
+		"Kids' Room -- Decor": "kids-room-decor",
+		"Café Furniture 2026": "café-furniture-2026",
+		"***":                 "",
+	}
+	for input, expected := range cases {
+		if result := service.Slugify(input); result != expected {
+			t.Errorf("Expected slug '%s' for '%s' but got '%s'", expected, input, result)
+		}
+	}
+}
+
+func TestCategoryTrail_withNestedCategory_shouldStartAtTopLevel(t *testing.T) {
+	trail := service.CategoryTrail(testCategories(), 3)
+
+	if len(trail) != 3 || trail[0].Slug != "furniture" || trail[1].Slug != "chairs" || trail[2].Slug != "dining-chairs" {
+		t.Errorf("Expected furniture > chairs > dining-chairs but got %+v", trail)
+	}
+	if len(service.CategoryTrail(testCategories(), 99)) != 0 {
+		t.Error("Expected no trail for an unknown category")
+	}
+}
+
+func TestDescendantIDs_withNestedCategories_shouldIncludeEveryLevel(t *testing.T) {
+	ids := service.DescendantIDs(testCategories(), 1)
+
+	expected := map[uint64]bool{1: true, 2: true, 3: true, 4: true}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected %d categories but got %v", len(expected), ids)
+	}
+	for _, id := range ids {
+		if !expected[id] {
+			t.Errorf("Unexpected category '%d' under furniture", id)
+		}
+	}
+}
+
+func TestBuildCategoryTree_withNestedCategories_shouldNestChildren(t *testing.T) {
+	tree := service.BuildCategoryTree(testCategories())
+
+	if len(tree) != 2 || tree[0].Slug != "furniture" || tree[1].Slug != "lighting" {
+		t.Fatalf("Expected furniture and lighting at the top but got %+v", tree)
+	}
+	if len(tree[0].Children) != 2 || len(tree[0].Children[0].Children) != 1 || tree[0].Children[0].Children[0].ID != 3 {
+		t.Errorf("Expected dining chairs nested under chairs but got %+v", tree[0].Children)
+	}
+}
+
+func TestAttachBreadcrumbs_withSeveralCategories_shouldAddTrailEach(t *testing.T) {
+	product := model.ProductResponse{ID: 1, CategoryIDs: []uint64{3, 5}}
+
+	service.AttachBreadcrumbs(&product, testCategories())
+	if len(product.Breadcrumbs) != 2 || len(product.Breadcrumbs[0]) != 3 || product.Breadcrumbs[1][0].Slug != "lighting" {
+		t.Errorf("Unexpected breadcrumbs %+v", product.Breadcrumbs)
+	}
+}
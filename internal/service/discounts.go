@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Discounts interface {
+	AllDiscounts(ctx context.Context) ([]model.DiscountResponse, error)
+	GetDiscount(ctx context.Context, discountId uint64) (*model.DiscountResponse, error)
+	CreateDiscount(ctx context.Context, body string, creatingUserId uint64) (*model.DiscountResponse, error)
+	UpdateDiscount(ctx context.Context, discountId uint64, body string, updatingUserId uint64) (*model.DiscountResponse, error)
+	DeleteDiscount(ctx context.Context, discountId uint64, deletingUserId uint64) error
+	Quote(ctx context.Context, body string, userId uint64) (*model.PriceQuoteResponse, error)
+	ApplyToOrder(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.OrderResponse, error)
+	PriceOrder(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, code *string, updatingUserId uint64) (*model.OrderResponse, error)
+	Shutdown()
+}
+
+type DiscountsService struct {
+	validator    Validator
+	uow          repository.UnitOfWork
+	discountRepo repository.DiscountRepository
+	orderRepo    repository.OrderRepository
+	productRepo  repository.ProductRepository
+	userRepo     repository.UserRepository
+	taxes        Taxes
+	shipping     Shipping
+	logger       logger.Logger
+}
+
+func NewDiscountsService(validator Validator, uow repository.UnitOfWork, discountRepo repository.DiscountRepository, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, taxes Taxes, shipping Shipping, logger logger.Logger) Discounts {
+	return &DiscountsService{
+		validator:    validator,
+		uow:          uow,
+		discountRepo: discountRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		userRepo:     userRepo,
+		taxes:        taxes,
+		shipping:     shipping,
+		logger:       logger,
+	}
+}
+
+// EvaluateDiscount works out how much a single discount takes off the given items,
+// returning an invalid input error when the discount does not apply.
+func EvaluateDiscount(discount model.DiscountResponse, items []model.PricedItem, usage model.DiscountUsage, now time.Time) (money.Money, error) {
+	if discount.StartsAt != nil && now.Before(*discount.StartsAt) {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+	if discount.EndsAt != nil && !now.Before(*discount.EndsAt) {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+	if discount.GlobalUsageLimit != nil && usage.Global >= *discount.GlobalUsageLimit {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+	if discount.PerUserUsageLimit != nil && usage.PerUser >= *discount.PerUserUsageLimit {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+
+	scoped := make(map[uint64]bool, len(discount.ProductIDs))
+	for _, productId := range discount.ProductIDs {
+		scoped[productId] = true
+	}
+
+	var subtotal, eligible money.Money
+	for _, item := range items {
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return money.Money{}, types.NewInvalidInputError()
+		}
+		subtotal, err = subtotal.Add(lineTotal)
+		if err != nil {
+			return money.Money{}, types.NewInvalidInputError()
+		}
+		if len(scoped) == 0 || (item.ProductID != nil && scoped[*item.ProductID]) {
+			eligible, err = eligible.Add(lineTotal)
+			if err != nil {
+				return money.Money{}, types.NewInvalidInputError()
+			}
+		}
+	}
+
+	belowMinimum, err := subtotal.Compare(discount.MinimumSpend)
+	if err != nil || belowMinimum < 0 || !eligible.IsPositive() {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+
+	var amount money.Money
+	switch discount.DiscountTypeID {
+	case constant.DISCOUNT_TYPE_PERCENTAGE:
+		amount, err = eligible.Percent(discount.Value, money.HalfUp)
+	case constant.DISCOUNT_TYPE_FIXED_AMOUNT:
+		amount, err = discount.Value.Money(eligible.Currency(), money.HalfUp)
+		if err == nil {
+			amount, err = amount.Min(eligible)
+		}
+	default:
+		return money.Money{}, types.NewInvalidInputError()
+	}
+	if err != nil {
+		return money.Money{}, types.NewInvalidInputError()
+	}
+
+	return amount, nil
+}
+
+// ApplyDiscounts prices the items with the coupon (if any) and every promotion that
+// qualifies. A coupon that does not apply is an error, promotions that do not are skipped.
+func ApplyDiscounts(items []model.PricedItem, coupon *model.DiscountResponse, promotions []model.DiscountResponse, usages map[uint64]model.DiscountUsage, now time.Time) (*model.PriceQuoteResponse, error) {
+	quote := model.PriceQuoteResponse{
+		Subtotal:  money.Zero(money.DefaultCurrency),
+		Discounts: make([]model.AppliedDiscount, 0),
+	}
+	for _, item := range items {
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		quote.Subtotal, err = quote.Subtotal.Add(lineTotal)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+	}
+	quote.DiscountTotal = money.Zero(quote.Subtotal.Currency())
+
+	candidates := make([]model.DiscountResponse, 0, len(promotions)+1)
+	if coupon != nil {
+		candidates = append(candidates, *coupon)
+	}
+	candidates = append(candidates, promotions...)
+
+	for i, discount := range candidates {
+		amount, err := EvaluateDiscount(discount, items, usages[discount.ID], now)
+		if err != nil {
+			if coupon != nil && i == 0 {
+				return nil, err
+			}
+			continue
+		}
+
+		remaining, err := quote.Subtotal.Sub(quote.DiscountTotal)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		amount, err = amount.Min(remaining)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		if !amount.IsPositive() {
+			continue
+		}
+
+		quote.DiscountTotal, err = quote.DiscountTotal.Add(amount)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		quote.Discounts = append(quote.Discounts, model.AppliedDiscount{
+			DiscountID:     discount.ID,
+			Code:           discount.Code,
+			DiscountTypeID: discount.DiscountTypeID,
+			Value:          discount.Value,
+			DiscountAmount: amount,
+		})
+	}
+
+	var err error
+	quote.Total, err = quote.Subtotal.Sub(quote.DiscountTotal)
+	if err != nil {
+		return nil, types.NewInvalidInputError()
+	}
+
+	return &quote, nil
+}
+
+func (d *DiscountsService) validateDiscountRequest(ctx context.Context, body string) (*model.DiscountRequest, error) {
+	log := logger.FromContext(ctx, d.logger)
+	var discountRequest model.DiscountRequest
+	err := d.validator.MarshalAndValidateREQ(body, &discountRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !discountRequest.Value.IsPositive() || discountRequest.MinimumSpend.IsNegative() {
+		log.Infof("Discount value '%s' or minimum spend '%s' is out of range", discountRequest.Value, discountRequest.MinimumSpend)
+		return nil, types.NewInvalidInputError()
+	}
+	if discountRequest.DiscountTypeID == constant.DISCOUNT_TYPE_PERCENTAGE && discountRequest.Value.Cmp(money.NewDecimal(100)) > 0 {
+		log.Infof("Percentage discount of '%s' is over 100", discountRequest.Value)
+		return nil, types.NewInvalidInputError()
+	}
+	if discountRequest.StartsAt != nil && discountRequest.EndsAt != nil && !discountRequest.StartsAt.Before(*discountRequest.EndsAt) {
+		log.Infof("Discount window '%s' to '%s' is empty", discountRequest.StartsAt, discountRequest.EndsAt)
+		return nil, types.NewInvalidInputError()
+	}
+
+	return &discountRequest, nil
+}
+
+func (d *DiscountsService) AllDiscounts(ctx context.Context) ([]model.DiscountResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.AllDiscounts")
+	defer span.End()
+
+	return d.discountRepo.GetAll(ctx)
+}
+
+func (d *DiscountsService) GetDiscount(ctx context.Context, discountId uint64) (*model.DiscountResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.GetDiscount")
+	defer span.End()
+
+	return d.discountRepo.GetByID(ctx, discountId)
+}
+
+func (d *DiscountsService) CreateDiscount(ctx context.Context, body string, creatingUserId uint64) (*model.DiscountResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.CreateDiscount")
+	defer span.End()
+
+	discountRequest, err := d.validateDiscountRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.discountRepo.Create(ctx, *discountRequest, creatingUserId)
+}
+
+func (d *DiscountsService) UpdateDiscount(ctx context.Context, discountId uint64, body string, updatingUserId uint64) (*model.DiscountResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.UpdateDiscount")
+	defer span.End()
+
+	discountRequest, err := d.validateDiscountRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = d.discountRepo.GetByID(ctx, discountId)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.discountRepo.Update(ctx, discountId, *discountRequest, updatingUserId)
+}
+
+func (d *DiscountsService) DeleteDiscount(ctx context.Context, discountId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "DiscountsService.DeleteDiscount")
+	defer span.End()
+
+	_, err := d.discountRepo.GetByID(ctx, discountId)
+	if err != nil {
+		return err
+	}
+
+	return d.discountRepo.Delete(ctx, discountId, deletingUserId)
+}
+
+// priceItems applies the active promotions and the optional coupon to the items.
+// Given a unit of work it locks each limited discount's usage until the work ends,
+// so concurrent checkouts cannot both pass a usage limit; quotes pass nil.
+func (d *DiscountsService) priceItems(ctx context.Context, tx *repository.Tx, items []model.PricedItem, code *string, userId uint64, orderId uint64) (*model.PriceQuoteResponse, error) {
+	log := logger.FromContext(ctx, d.logger)
+	now := time.Now()
+
+	var coupon *model.DiscountResponse
+	if code != nil {
+		var err error
+		coupon, err = d.discountRepo.GetByCode(ctx, *code)
+		if err != nil {
+			if socketErr, ok := err.(*types.SocketError); ok && socketErr.StatusCode() == constant.NotFoundCode {
+				log.Infof("Unknown discount code '%s'", *code)
+				return nil, types.NewInvalidInputError()
+			}
+			return nil, err
+		}
+	}
+
+	promotions, err := d.discountRepo.GetActivePromotions(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(map[uint64]model.DiscountUsage, len(promotions)+1)
+	candidates := promotions
+	if coupon != nil {
+		candidates = append([]model.DiscountResponse{*coupon}, promotions...)
+	}
+	for _, discount := range candidates {
+		if discount.GlobalUsageLimit == nil && discount.PerUserUsageLimit == nil {
+			continue
+		}
+		var usage *model.DiscountUsage
+		if tx != nil {
+			usage, err = d.discountRepo.WithTx(tx).LockUsage(ctx, discount.ID, userId, orderId)
+		} else {
+			usage, err = d.discountRepo.GetUsage(ctx, discount.ID, userId, orderId)
+		}
+		if err != nil {
+			return nil, err
+		}
+		usages[discount.ID] = *usage
+	}
+
+	return ApplyDiscounts(items, coupon, promotions, usages, now)
+}
+
+func (d *DiscountsService) Quote(ctx context.Context, body string, userId uint64) (*model.PriceQuoteResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.Quote")
+	defer span.End()
+	log := logger.FromContext(ctx, d.logger)
+
+	var quoteRequest model.QuoteRequest
+	err := d.validator.MarshalAndValidateREQ(body, &quoteRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.PricedItem, 0, len(quoteRequest.Items))
+	for _, item := range quoteRequest.Items {
+		product, err := d.productRepo.GetByID(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		variants, err := d.productRepo.GetVariants(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		_, price, err := SelectVariant(*product, variants, item.VariantID)
+		if err != nil {
+			log.Infof("Variant '%v' cannot be bought for product '%d'", item.VariantID, item.ProductID)
+			return nil, err
+		}
+		items = append(items, model.PricedItem{
+			ProductID: &product.ID,
+			Price:     price,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	return d.priceItems(ctx, nil, items, quoteRequest.Code, userId, 0)
+}
+
+// ApplyToOrder evaluates discounts against an order at checkout, snapshots the
+// result onto the order, taxes it and requotes its shipping, whose rate can depend on
+// the discounted value, so its totals stay reproducible. It is all written in one
+// unit of work so the order is never left with discounts its totals do not reflect.
+func (d *DiscountsService) ApplyToOrder(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.ApplyToOrder")
+	defer span.End()
+	log := logger.FromContext(ctx, d.logger)
+
+	var applyRequest model.ApplyDiscountRequest
+	err := d.validator.MarshalAndValidateREQ(body, &applyRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := d.orderRepo.GetByID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.CreatedUser != updatingUserId {
+		allowed, err := d.userRepo.HasPermission(ctx, updatingUserId, constant.MANAGE_DISCOUNT_PERMISSION)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			log.Infof("User '%d' denied applying discounts to order '%d'", updatingUserId, orderId)
+			return nil, types.NewForbiddenError()
+		}
+	}
+
+	if order.StatusID != constant.ORDER_STATUS_AWAITING_PAYMENT {
+		log.Infof("Order '%d' with status '%d' is past checkout", orderId, order.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	err = d.uow.Run(ctx, "ApplyOrderDiscounts", func(tx *repository.Tx) error {
+		order, err = d.PriceOrder(ctx, tx, order, applyRequest.Code, updatingUserId)
+		if err != nil {
+			return err
+		}
+
+		order, err = d.shipping.ChargeShipping(ctx, tx, order, nil, nil, updatingUserId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// PriceOrder applies the active promotions and the optional coupon code to the
+// order's items, replacing the discounts snapshotted onto it, and taxes it, all in
+// the caller's unit of work. Usage limits are counted for the customer who placed it.
+func (d *DiscountsService) PriceOrder(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, code *string, updatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "DiscountsService.PriceOrder")
+	defer span.End()
+
+	items := make([]model.PricedItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, model.PricedItem{
+			ProductID: item.ProductID,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	quote, err := d.priceItems(ctx, tx, items, code, order.CreatedUser, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.discountRepo.WithTx(tx).ReplaceOrderDiscounts(ctx, order.ID, quote.Discounts, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.taxes.ApplyToOrder(ctx, tx, order, quote.DiscountTotal, updatingUserId)
+}
+
+func (d *DiscountsService) Shutdown() {
+	d.discountRepo.Shutdown()
+}
@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/service"
+)
+
+func zar(minor int64) money.Money {
+	return money.New(minor, money.DefaultCurrency)
+}
+
+func testPricedItems() []model.PricedItem {
+	first, second := uint64(1), uint64(2)
+	return []model.PricedItem{
+		{ProductID: &first, Price: zar(2999), Quantity: 2},
+		{ProductID: &second, Price: zar(1999), Quantity: 1},
+	}
+}
+
+func TestEvaluateDiscount_withPercentage_shouldTakePercentageOfSubtotal(t *testing.T) {
+	discount := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_PERCENTAGE, Value: money.NewDecimal(10)}
+
+	amount, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if amount.Minor() != 800 {
+		t.Errorf("Expected discount of 8 but got %s", amount)
+	}
+}
+
+func TestEvaluateDiscount_withProductScope_shouldOnlyDiscountScopedProducts(t *testing.T) {
+	discount := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(50), ProductIDs: []uint64{2}}
+
+	amount, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if amount.Minor() != 1999 {
+		t.Errorf("Expected fixed discount capped at 19.99 but got %s", amount)
+	}
+}
+
+func TestEvaluateDiscount_withMinimumSpendNotMet_shouldNotApply(t *testing.T) {
+	discount := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), MinimumSpend: zar(10000)}
+
+	_, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, time.Now())
+	if err == nil {
+		t.Error("Expected error when minimum spend is not met")
+	}
+}
+
+func TestEvaluateDiscount_withUsageLimitsReached_shouldNotApply(t *testing.T) {
+	limit := uint64(1)
+	global := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), GlobalUsageLimit: &limit}
+	perUser := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), PerUserUsageLimit: &limit}
+
+	if _, err := service.EvaluateDiscount(global, testPricedItems(), model.DiscountUsage{Global: 1}, time.Now()); err == nil {
+		t.Error("Expected error when global usage limit is reached")
+	}
+	if _, err := service.EvaluateDiscount(perUser, testPricedItems(), model.DiscountUsage{Global: 5, PerUser: 1}, time.Now()); err == nil {
+		t.Error("Expected error when per user usage limit is reached")
+	}
+}
+
+func TestEvaluateDiscount_withValidityWindow_shouldOnlyApplyInsideWindow(t *testing.T) {
+	startsAt := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC)
+	discount := model.DiscountResponse{DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), StartsAt: &startsAt, EndsAt: &endsAt}
+
+	if _, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, startsAt.Add(-time.Second)); err == nil {
+		t.Error("Expected error before the window starts")
+	}
+	if _, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, startsAt); err != nil {
+		t.Errorf("Expected discount to apply at the start of the window but got %v", err)
+	}
+	if _, err := service.EvaluateDiscount(discount, testPricedItems(), model.DiscountUsage{}, endsAt); err == nil {
+		t.Error("Expected error once the window has ended")
+	}
+}
+
+func TestApplyDiscounts_withInvalidCoupon_shouldFailButSkipInvalidPromotions(t *testing.T) {
+	coupon := model.DiscountResponse{ID: 1, DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), MinimumSpend: zar(50000)}
+	promotions := []model.DiscountResponse{
+		{ID: 2, DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(5), MinimumSpend: zar(50000)},
+		{ID: 3, DiscountTypeID: constant.DISCOUNT_TYPE_PERCENTAGE, Value: money.NewDecimal(50)},
+	}
+
+	if _, err := service.ApplyDiscounts(testPricedItems(), &coupon, promotions, nil, time.Now()); err == nil {
+		t.Error("Expected error for a coupon that does not apply")
+	}
+
+	quote, err := service.ApplyDiscounts(testPricedItems(), nil, promotions, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if len(quote.Discounts) != 1 || quote.Discounts[0].DiscountID != 3 {
+		t.Errorf("Expected only promotion 3 to apply but got %+v", quote.Discounts)
+	}
+	if quote.Subtotal.Minor() != 7997 || quote.DiscountTotal.Minor() != 3999 || quote.Total.Minor() != 3998 {
+		t.Errorf("Unexpected totals %+v", quote)
+	}
+}
+
+func TestApplyDiscounts_withStackedDiscounts_shouldNeverGoBelowZero(t *testing.T) {
+	code := "BIG"
+	coupon := model.DiscountResponse{ID: 1, Code: &code, DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(70)}
+	promotions := []model.DiscountResponse{
+		{ID: 2, DiscountTypeID: constant.DISCOUNT_TYPE_FIXED_AMOUNT, Value: money.NewDecimal(70)},
+	}
+
+	quote, err := service.ApplyDiscounts(testPricedItems(), &coupon, promotions, nil, time.Now())
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if !quote.Total.IsZero() || quote.DiscountTotal != quote.Subtotal {
+		t.Errorf("Expected discounts to be capped at the subtotal but got %+v", quote)
+	}
+	if quote.Discounts[1].DiscountAmount.Minor() != 997 {
+		t.Errorf("Expected second discount to be capped to 9.97 but got %s", quote.Discounts[1].DiscountAmount)
+	}
+}
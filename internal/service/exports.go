@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/export"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Exports interface {
+	ValidateOrderExport(ctx context.Context, body string) (*model.OrderExportRequest, error)
+	StreamOrderExport(ctx context.Context, request model.OrderExportRequest, w io.Writer) error
+	Invoice(ctx context.Context, orderId uint64, userId uint64) ([]byte, error)
+	Shutdown()
+}
+
+type ExportsService struct {
+	validator Validator
+	orderRepo repository.OrderRepository
+	userRepo  repository.UserRepository
+	logger    logger.Logger
+}
+
+func NewExportsService(validator Validator, orderRepo repository.OrderRepository, userRepo repository.UserRepository, logger logger.Logger) Exports {
+	return &ExportsService{
+		validator: validator,
+		orderRepo: orderRepo,
+		userRepo:  userRepo,
+		logger:    logger,
+	}
+}
+
+var orderExportColumns = []export.Column{
+	{Name: "order_id", Numeric: true},
+	{Name: "created_at"},
+	{Name: "status"},
+	{Name: "first_name"},
+	{Name: "last_name"},
+	{Name: "email"},
+	{Name: "subtotal", Numeric: true},
+	{Name: "discount_total", Numeric: true},
+	{Name: "tax_total", Numeric: true},
+	{Name: "shipping_total", Numeric: true},
+	{Name: "total", Numeric: true},
+	{Name: "order_item_id", Numeric: true},
+	{Name: "product_id", Numeric: true},
+	{Name: "product_title"},
+	{Name: "price", Numeric: true},
+	{Name: "quantity", Numeric: true},
+	{Name: "item_discount", Numeric: true},
+	{Name: "tax_rate", Numeric: true},
+	{Name: "tax_amount", Numeric: true},
+	{Name: "prices_include_tax"},
+}
+
+// OrderExportValues flattens an export row into the cells of orderExportColumns.
+func OrderExportValues(row model.OrderExportRow) []string {
+	values := []string{
+		strconv.FormatUint(row.Order.ID, 10),
+		row.Order.CreatedAt,
+		row.StatusName,
+		row.Order.FirstName,
+		row.Order.LastName,
+		row.Order.Email,
+		row.Order.Subtotal.String(),
+		row.Order.DiscountTotal.String(),
+		row.Order.TaxTotal.String(),
+		row.Order.ShippingTotal.String(),
+		row.Order.Total.String(),
+	}
+	if row.Item == nil {
+		return append(values, make([]string, 9)...)
+	}
+
+	productId := ""
+	if row.Item.ProductID != nil {
+		productId = strconv.FormatUint(*row.Item.ProductID, 10)
+	}
+	return append(values,
+		strconv.FormatUint(row.Item.ID, 10),
+		productId,
+		row.Item.ProductTitle,
+		row.Item.Price.String(),
+		strconv.FormatUint(row.Item.Quantity, 10),
+		row.Item.DiscountAmount.String(),
+		row.Item.TaxRate.String(),
+		row.Item.TaxAmount.String(),
+		strconv.FormatBool(row.Item.PricesIncludeTax),
+	)
+}
+
+func (e *ExportsService) ValidateOrderExport(ctx context.Context, body string) (*model.OrderExportRequest, error) {
+	ctx, span := tracing.Start(ctx, "ExportsService.ValidateOrderExport")
+	defer span.End()
+	log := logger.FromContext(ctx, e.logger)
+
+	var exportRequest model.OrderExportRequest
+	err := e.validator.MarshalAndValidateREQ(body, &exportRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exportRequest.From.Before(exportRequest.To) {
+		log.Infof("Export range '%s' to '%s' is empty", exportRequest.From, exportRequest.To)
+		return nil, types.NewInvalidInputError()
+	}
+
+	return &exportRequest, nil
+}
+
+// StreamOrderExport writes the orders in the request to w as they are read from the
+// database. Once the first row is written errors can only be logged by the caller.
+func (e *ExportsService) StreamOrderExport(ctx context.Context, request model.OrderExportRequest, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "ExportsService.StreamOrderExport")
+	defer span.End()
+	log := logger.FromContext(ctx, e.logger)
+
+	var writer export.TableWriter
+	switch request.Format {
+	case export.FORMAT_XLSX:
+		writer = export.NewXLSXWriter(w)
+	default:
+		writer = export.NewCSVWriter(w)
+	}
+
+	err := writer.WriteHeader(orderExportColumns)
+	if err != nil {
+		return err
+	}
+
+	rowCount := 0
+	err = e.orderRepo.StreamForExport(ctx, request, func(row model.OrderExportRow) error {
+		rowCount++
+		return writer.WriteRow(OrderExportValues(row))
+	})
+	if err != nil {
+		log.Errorf("Order export failed after '%d' rows: %s", rowCount, err.Error())
+		return err
+	}
+	log.Infof("Exported '%d' order rows as '%s'", rowCount, request.Format)
+
+	return writer.Close()
+}
+
+// InvoiceLines lays out a plain text invoice for the order, one line per entry.
+func InvoiceLines(order model.OrderResponse, details *model.DeliveryDetailsResponse) []string {
+	lines := []string{
+		"TAX INVOICE",
+		"",
+		fmt.Sprintf("Invoice number: INV-%06d", order.ID),
+		fmt.Sprintf("Order date:     %s", order.CreatedAt),
+		"",
+		"Bill to:",
+		strings.TrimSpace(order.FirstName + " " + order.LastName),
+		order.Email,
+	}
+	if details != nil {
+		address := []*string{details.StreetNumber, details.StreetName, details.ComplexName, details.AreaName, details.City, details.Country}
+		parts := make([]string, 0, len(address))
+		for _, part := range address {
+			if part != nil && *part != "" {
+				parts = append(parts, *part)
+			}
+		}
+		if len(parts) > 0 {
+			lines = append(lines, strings.Join(parts, ", "))
+		}
+	}
+
+	lines = append(lines, "",
+		fmt.Sprintf("%-28s %5s %11s %10s %10s %11s", "Item", "Qty", "Price", "Discount", "Tax", "Line total"),
+		strings.Repeat("-", 80),
+	)
+	for _, item := range order.Items {
+		title := item.ProductTitle
+		if len(title) > 28 {
+			title = title[:25] + "..."
+		}
+		lineTotal, err := PaidLineTotal(item)
+		if err != nil {
+			lineTotal = item.Price
+		}
+		lines = append(lines, fmt.Sprintf("%-28s %5d %11s %10s %10s %11s", title, item.Quantity, item.Price, item.DiscountAmount, item.TaxAmount, lineTotal))
+	}
+
+	taxLabel := "Tax"
+	for _, item := range order.Items {
+		if item.PricesIncludeTax && item.TaxAmount.IsPositive() {
+			taxLabel = "Tax (included)"
+			break
+		}
+	}
+	lines = append(lines,
+		strings.Repeat("-", 80),
+		fmt.Sprintf("%67s %12s", "Subtotal", order.Subtotal),
+		fmt.Sprintf("%67s %12s", "Discounts", order.DiscountTotal),
+		fmt.Sprintf("%67s %12s", taxLabel, order.TaxTotal),
+		fmt.Sprintf("%67s %12s", "Shipping", order.ShippingTotal),
+		fmt.Sprintf("%67s %12s", "Total "+order.Total.Currency(), order.Total),
+	)
+
+	return lines
+}
+
+// Invoice renders a PDF invoice for the order, available to its owner and to users
+// who can export orders.
+func (e *ExportsService) Invoice(ctx context.Context, orderId uint64, userId uint64) ([]byte, error) {
+	ctx, span := tracing.Start(ctx, "ExportsService.Invoice")
+	defer span.End()
+	log := logger.FromContext(ctx, e.logger)
+
+	order, err := e.orderRepo.GetByID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.CreatedUser != userId {
+		allowed, err := e.userRepo.HasPermission(ctx, userId, constant.EXPORT_ORDER_PERMISSION)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			log.Infof("User '%d' denied invoice for order '%d'", userId, orderId)
+			return nil, types.NewForbiddenError()
+		}
+	}
+
+	details, err := e.orderRepo.GetDeliveryDetails(ctx, order.DeliveryDetailsID)
+	if err != nil {
+		return nil, err
+	}
+
+	var invoice bytes.Buffer
+	err = export.WritePDF(&invoice, fmt.Sprintf("Invoice INV-%06d", order.ID), InvoiceLines(*order, details))
+	if err != nil {
+		log.Errorf("Unabled to render invoice for order '%d': %s", orderId, err.Error())
+		return nil, types.NewInternalServerError()
+	}
+
+	return invoice.Bytes(), nil
+}
+
+func (e *ExportsService) Shutdown() {
+	e.orderRepo.Shutdown()
+}
@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func TestOrderExportValues_withAndWithoutItem_shouldKeepColumnCount(t *testing.T) {
+	productId := uint64(7)
+	order := model.OrderResponse{ID: 3, FirstName: "Jo", Subtotal: zar(5998), TaxTotal: zar(782), Total: zar(5998)}
+	withItem := service.OrderExportValues(model.OrderExportRow{
+		Order:      order,
+		StatusName: "Pending",
+		Item:       &model.OrderItemResponse{ID: 9, ProductID: &productId, ProductTitle: "Product 1", Price: zar(2999), Quantity: 2, TaxRate: rate(t, "15"), TaxAmount: zar(782), PricesIncludeTax: true},
+	})
+	withoutItem := service.OrderExportValues(model.OrderExportRow{Order: order, StatusName: "Pending"})
+
+	if len(withItem) != len(withoutItem) {
+		t.Fatalf("Expected the same column count but got %d and %d", len(withItem), len(withoutItem))
+	}
+	if withItem[8] != "7.82" || withItem[13] != "Product 1" || withItem[17] != "15" || withItem[19] != "true" {
+		t.Errorf("Unexpected export values %v", withItem)
+	}
+	if withoutItem[11] != "" {
+		t.Errorf("Expected empty item columns but got %v", withoutItem)
+	}
+}
+
+func TestInvoiceLines_withInclusiveTax_shouldLabelTaxAsIncluded(t *testing.T) {
+	country := "South Africa"
+	order := model.OrderResponse{
+		ID:        42,
+		FirstName: "Jo",
+		LastName:  "Soap",
+		Subtotal:  zar(11500),
+		TaxTotal:  zar(1500),
+		Total:     zar(11500),
+		Items: []model.OrderItemResponse{
+			{ProductTitle: "Product 1", Price: zar(11500), Quantity: 1, TaxAmount: zar(1500), PricesIncludeTax: true},
+		},
+	}
+
+	invoice := strings.Join(service.InvoiceLines(order, &model.DeliveryDetailsResponse{Country: &country}), "\n")
+	for _, expected := range []string{"INV-000042", "Jo Soap", "South Africa", "Tax (included)", "115.00"} {
+		if !strings.Contains(invoice, expected) {
+			t.Errorf("Expected invoice to contain %q but got\n%s", expected, invoice)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+)
+
+type Health interface {
+	Liveness() model.HealthResponse
+	Readiness(ctx context.Context) model.HealthResponse
+	Shutdown()
+}
+
+// HealthCheck is one dependency readiness depends on. A failing critical check makes
+// the service not ready; any other failing check only marks it degraded.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+type HealthService struct {
+	healthRepo repository.HealthRepository
+	checks     []HealthCheck
+	logger     logger.Logger
+}
+
+// NewHealthService checks the database writer, reader and schema version, followed
+// by any dependency checks passed in, such as a mailer or file storage.
+func NewHealthService(healthRepo repository.HealthRepository, logger logger.Logger, dependencyChecks ...HealthCheck) Health {
+	h := &HealthService{
+		healthRepo: healthRepo,
+		logger:     logger,
+	}
+	h.checks = append([]HealthCheck{
+		{Name: "database_writer", Critical: true, Check: healthRepo.PingWriter},
+		// reads fall back to the writer while the reader is down
+		{Name: "database_reader", Critical: false, Check: healthRepo.PingReader},
+		{Name: "schema_version", Critical: true, Check: h.checkSchemaVersion},
+	}, dependencyChecks...)
+	return h
+}
+
+func (h *HealthService) Shutdown() {
+	h.healthRepo.Shutdown()
+}
+
+// Liveness only reports that the process is serving requests; it checks nothing so a
+// dependency outage does not get the process restarted.
+func (h *HealthService) Liveness() model.HealthResponse {
+	return model.HealthResponse{Status: constant.HEALTH_STATUS_OK}
+}
+
+func (h *HealthService) Readiness(ctx context.Context) model.HealthResponse {
+	ctx, span := tracing.Start(ctx, "HealthService.Readiness")
+	defer span.End()
+	log := logger.FromContext(ctx, h.logger)
+
+	response := RunHealthChecks(ctx, log, h.checks, constant.HEALTH_CHECK_TIMEOUT*time.Second)
+	if response.Status != constant.HEALTH_STATUS_OK {
+		log.Infof("Readiness is '%s': %+v", response.Status, response.Checks)
+	}
+	return response
+}
+
+func (h *HealthService) checkSchemaVersion(ctx context.Context) error {
+	version, err := h.healthRepo.GetSchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if version < constant.SCHEMA_VERSION {
+		return fmt.Errorf("schema is at version '%s', expected '%s'", version, constant.SCHEMA_VERSION)
+	}
+	return nil
+}
+
+// RunHealthChecks runs checks concurrently, each bounded by timeout, and reports
+// every result in order with its duration. The status is fail when a critical check
+// failed, degraded when only others did, and ok otherwise. Check errors are only
+// logged, as the response is served unauthenticated.
+func RunHealthChecks(ctx context.Context, log logger.Logger, checks []HealthCheck, timeout time.Duration) model.HealthResponse {
+	results := make([]model.HealthCheckResponse, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check.Check(checkCtx)
+			results[i] = model.HealthCheckResponse{
+				Name:       check.Name,
+				Status:     constant.HEALTH_STATUS_OK,
+				Critical:   check.Critical,
+				DurationMs: float64(time.Since(started).Microseconds()) / 1000,
+			}
+			if err != nil {
+				results[i].Status = constant.HEALTH_STATUS_FAIL
+				log.Errorf("Health check '%s' failed: %s", check.Name, err.Error())
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	status := constant.HEALTH_STATUS_OK
+	for _, result := range results {
+		if result.Status == constant.HEALTH_STATUS_OK {
+			continue
+		}
+		if result.Critical {
+			status = constant.HEALTH_STATUS_FAIL
+			break
+		}
+		status = constant.HEALTH_STATUS_DEGRADED
+	}
+
+	return model.HealthResponse{Status: status, Checks: results}
+}
@@ -0,0 +1,57 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/service"
+)
+
+func passingCheck(ctx context.Context) error { return nil }
+
+func failingCheck(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestRunHealthChecks_withFailures_shouldReportStatusByCriticality(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []service.HealthCheck
+		expected string
+	}{
+		{"all passing", []service.HealthCheck{{Name: "writer", Critical: true, Check: passingCheck}, {Name: "reader", Check: passingCheck}}, constant.HEALTH_STATUS_OK},
+		{"optional failing", []service.HealthCheck{{Name: "writer", Critical: true, Check: passingCheck}, {Name: "reader", Check: failingCheck}}, constant.HEALTH_STATUS_DEGRADED},
+		{"critical failing", []service.HealthCheck{{Name: "writer", Critical: true, Check: failingCheck}, {Name: "reader", Check: failingCheck}}, constant.HEALTH_STATUS_FAIL},
+	}
+	log := logger.NewSimpleLogger("ERROR", false)
+	for _, test := range tests {
+		response := service.RunHealthChecks(context.Background(), log, test.checks, time.Second)
+		if response.Status != test.expected {
+			t.Errorf("RunHealthChecks test failed for %s, expected[%s], got[%s]", test.name, test.expected, response.Status)
+		}
+		if len(response.Checks) != len(test.checks) || response.Checks[0].Name != "writer" {
+			t.Errorf("RunHealthChecks test failed for %s, expected the checks in order, got[%+v]", test.name, response.Checks)
+		}
+		if body, _ := json.Marshal(response); strings.Contains(string(body), "connection refused") {
+			t.Errorf("RunHealthChecks test failed for %s, expected no check errors in the response, got[%s]", test.name, body)
+		}
+	}
+}
+
+func TestRunHealthChecks_withSlowCheck_shouldTimeOut(t *testing.T) {
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	log := logger.NewSimpleLogger("ERROR", false)
+	response := service.RunHealthChecks(context.Background(), log, []service.HealthCheck{{Name: "mailer", Check: slow}}, 10*time.Millisecond)
+
+	check := response.Checks[0]
+	if check.Status != constant.HEALTH_STATUS_FAIL || check.DurationMs < 10 {
+		t.Errorf("RunHealthChecks test failed, expected a timed out failure after 10ms, got[%+v]", check)
+	}
+}
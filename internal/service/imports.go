@@ -0,0 +1,414 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/export"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Imports interface {
+	StartProductImport(ctx context.Context, data []byte, creatingUserId uint64) (*model.ProductImportResponse, error)
+	ImportProducts(ctx context.Context, data []byte, creatingUserId uint64) (*model.ProductImportResponse, error)
+	GetProductImport(ctx context.Context, importId uint64) (*model.ProductImportResponse, error)
+	ExportProducts(ctx context.Context, w io.Writer) error
+	Shutdown()
+}
+
+type ImportsService struct {
+	validator   Validator
+	importRepo  repository.ProductImportRepository
+	productRepo repository.ProductRepository
+	products    Products
+	running     sync.WaitGroup
+	logger      logger.Logger
+}
+
+func NewImportsService(validator Validator, importRepo repository.ProductImportRepository, productRepo repository.ProductRepository, products Products, logger logger.Logger) Imports {
+	return &ImportsService{
+		validator:   validator,
+		importRepo:  importRepo,
+		productRepo: productRepo,
+		products:    products,
+		logger:      logger,
+	}
+}
+
+// productCSVColumns is the layout shared by imports and exports so an exported file
+// can be edited and imported again. Only title and price are required on import.
+var productCSVColumns = []export.Column{
+	{Name: "id", Numeric: true},
+	{Name: "sku"},
+	{Name: "title"},
+	{Name: "description"},
+	{Name: "price", Numeric: true},
+	{Name: "stock", Numeric: true},
+	{Name: "tax_class_id", Numeric: true},
+	{Name: "weight", Numeric: true},
+	{Name: "category_ids"},
+	{Name: "pictures"},
+}
+
+// ProductCSVValues flattens a product into the cells of productCSVColumns.
+func ProductCSVValues(product model.ProductResponse) []string {
+	sku := ""
+	if product.SKU != nil {
+		sku = *product.SKU
+	}
+	categoryIds := make([]string, 0, len(product.CategoryIDs))
+	for _, categoryId := range product.CategoryIDs {
+		categoryIds = append(categoryIds, strconv.FormatUint(categoryId, 10))
+	}
+
+	return []string{
+		strconv.FormatUint(product.ID, 10),
+		sku,
+		product.Title,
+		product.Description,
+		product.Price.String(),
+		strconv.FormatUint(product.Stock, 10),
+		strconv.FormatUint(product.TaxClassID, 10),
+		product.Weight.String(),
+		strings.Join(categoryIds, constant.IMPORT_LIST_SEPARATOR),
+		strings.Join(product.Pictures, constant.IMPORT_LIST_SEPARATOR),
+	}
+}
+
+func readProductCSVHeader(header []string) (map[string]int, error) {
+	known := make(map[string]bool, len(productCSVColumns))
+	for _, column := range productCSVColumns {
+		known[column.Name] = true
+	}
+
+	positions := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown column '%s'", name)
+		}
+		if _, ok := positions[name]; ok {
+			return nil, fmt.Errorf("column '%s' appears twice", name)
+		}
+		positions[name] = i
+	}
+	for _, name := range []string{"title", "price"} {
+		if _, ok := positions[name]; !ok {
+			return nil, fmt.Errorf("missing column '%s'", name)
+		}
+	}
+
+	return positions, nil
+}
+
+func splitList(value string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(value, constant.IMPORT_LIST_SEPARATOR) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseProductCSVRecord(positions map[string]int, record []string) (*model.ProductImportRow, error) {
+	cell := func(name string) string {
+		if i, ok := positions[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	parseUint := func(name string, fallback uint64) (uint64, error) {
+		value := cell(name)
+		if value == "" {
+			return fallback, nil
+		}
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s: '%s' is not a whole number", name, value)
+		}
+		return parsed, nil
+	}
+
+	var row model.ProductImportRow
+	var err error
+	row.ID, err = parseUint("id", 0)
+	if err != nil {
+		return nil, err
+	}
+	if sku := cell("sku"); sku != "" {
+		row.Request.SKU = &sku
+	}
+	row.Request.Title = cell("title")
+	row.Request.Description = cell("description")
+
+	row.Request.Price, err = money.Parse(cell("price"), money.DefaultCurrency)
+	if err != nil {
+		return nil, fmt.Errorf("price: '%s' is not an amount", cell("price"))
+	}
+	row.Request.Stock, err = parseUint("stock", 0)
+	if err != nil {
+		return nil, err
+	}
+	row.Request.TaxClassID, err = parseUint("tax_class_id", constant.TAX_CLASS_STANDARD)
+	if err != nil {
+		return nil, err
+	}
+	if weight := cell("weight"); weight != "" {
+		row.Request.Weight, err = money.ParseDecimal(weight)
+		if err != nil {
+			return nil, fmt.Errorf("weight: '%s' is not a number", weight)
+		}
+	}
+
+	row.Request.CategoryIDs = make([]uint64, 0)
+	for _, categoryId := range splitList(cell("category_ids")) {
+		parsed, err := strconv.ParseUint(categoryId, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("category_ids: '%s' is not a category id", categoryId)
+		}
+		row.Request.CategoryIDs = append(row.Request.CategoryIDs, parsed)
+	}
+	row.Request.Pictures = splitList(cell("pictures"))
+
+	return &row, nil
+}
+
+// ParseProductCSV reads product rows from a CSV export or spreadsheet. A row that
+// cannot be read is reported against its line and skipped; only an unreadable
+// header fails the whole file.
+func ParseProductCSV(r io.Reader) ([]model.ProductImportRow, []model.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, nil, errors.New("file is empty")
+		}
+		return nil, nil, err
+	}
+	positions, err := readProductCSVHeader(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]model.ProductImportRow, 0)
+	rowErrors := make([]model.ImportRowError, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, err
+			}
+			rowErrors = append(rowErrors, model.ImportRowError{Row: uint64(parseErr.StartLine), Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		row, err := parseProductCSVRecord(positions, record)
+		if err != nil {
+			rowErrors = append(rowErrors, model.ImportRowError{Row: uint64(line), Message: err.Error()})
+			continue
+		}
+		row.Row = uint64(line)
+		rows = append(rows, *row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// importRow validates the row and saves it, matching an existing product by id first
+// and then by SKU. It reports whether a new product was created.
+func (i *ImportsService) importRow(ctx context.Context, row model.ProductImportRow, userId uint64) (bool, error) {
+	if fields := i.validator.InvalidFields(row.Request); len(fields) > 0 {
+		return false, fmt.Errorf("invalid %s", strings.Join(fields, ", "))
+	}
+
+	productId := row.ID
+	if productId != 0 {
+		_, err := i.productRepo.GetByID(ctx, productId)
+		if err != nil {
+			return false, fmt.Errorf("product '%d': %s", productId, err.Error())
+		}
+	} else if row.Request.SKU != nil {
+		product, err := i.productRepo.GetBySKU(ctx, *row.Request.SKU)
+		if err == nil {
+			productId = product.ID
+		} else if socketErr, ok := err.(*types.SocketError); !ok || socketErr.StatusCode() != constant.NotFoundCode {
+			return false, err
+		}
+	}
+
+	_, err := i.products.SaveProduct(ctx, productId, row.Request, userId)
+	if err != nil {
+		return false, err
+	}
+
+	return productId == 0, nil
+}
+
+// runImport works through the rows, saving progress every IMPORT_PROGRESS_INTERVAL
+// rows so a client polling the import can follow along.
+func (i *ImportsService) runImport(ctx context.Context, report *model.ProductImportResponse, rows []model.ProductImportRow, userId uint64) {
+	log := logger.FromContext(ctx, i.logger)
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Errorf("Product import '%d' stopped: %v", report.ID, recovered)
+			report.Status = constant.IMPORT_STATUS_FAILED
+			i.saveProgress(ctx, report, userId)
+		}
+	}()
+
+	report.Status = constant.IMPORT_STATUS_RUNNING
+	i.saveProgress(ctx, report, userId)
+
+	for n, row := range rows {
+		created, err := i.importRow(ctx, row, userId)
+		switch {
+		case err != nil:
+			report.Failed++
+			report.Errors = append(report.Errors, model.ImportRowError{Row: row.Row, Message: err.Error()})
+		case created:
+			report.Created++
+		default:
+			report.Updated++
+		}
+		report.ProcessedRows++
+
+		if (n+1)%constant.IMPORT_PROGRESS_INTERVAL == 0 {
+			i.saveProgress(ctx, report, userId)
+		}
+	}
+
+	report.Status = constant.IMPORT_STATUS_COMPLETE
+	i.saveProgress(ctx, report, userId)
+	log.Infof("Product import '%d' finished: %d created, %d updated, %d failed", report.ID, report.Created, report.Updated, report.Failed)
+}
+
+func (i *ImportsService) saveProgress(ctx context.Context, report *model.ProductImportResponse, userId uint64) {
+	log := logger.FromContext(ctx, i.logger)
+	err := i.importRepo.SaveProgress(ctx, *report, userId)
+	if err != nil {
+		log.Errorf("Unabled to save progress of product import '%d': %s", report.ID, err.Error())
+	}
+}
+
+// prepareImport parses the file and records the import with the rows that could not
+// be read already counted as failed.
+func (i *ImportsService) prepareImport(ctx context.Context, data []byte, creatingUserId uint64) (*model.ProductImportResponse, []model.ProductImportRow, error) {
+	log := logger.FromContext(ctx, i.logger)
+	rows, rowErrors, err := ParseProductCSV(bytes.NewReader(data))
+	if err != nil {
+		log.Infof("Unabled to read product import: %s", err.Error())
+		return nil, nil, types.NewInvalidInputError()
+	}
+
+	report, err := i.importRepo.Create(ctx, uint64(len(rows)+len(rowErrors)), creatingUserId)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Errors = rowErrors
+	report.Failed = uint64(len(rowErrors))
+	report.ProcessedRows = uint64(len(rowErrors))
+
+	return report, rows, nil
+}
+
+// StartProductImport records the import and runs it in the background, returning the
+// queued report straight away.
+func (i *ImportsService) StartProductImport(ctx context.Context, data []byte, creatingUserId uint64) (*model.ProductImportResponse, error) {
+	ctx, span := tracing.Start(ctx, "ImportsService.StartProductImport")
+	defer span.End()
+
+	report, rows, err := i.prepareImport(ctx, data, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	queued := *report
+	queued.Errors = append([]model.ImportRowError(nil), report.Errors...)
+	// the import outlives the request that queued it, so it keeps the request's
+	// values but not its deadline or cancellation
+	importCtx := context.WithoutCancel(ctx)
+	i.running.Add(1)
+	go func() {
+		defer i.running.Done()
+		i.runImport(importCtx, report, rows, creatingUserId)
+	}()
+
+	return &queued, nil
+}
+
+// ImportProducts runs the import to completion before returning the final report.
+func (i *ImportsService) ImportProducts(ctx context.Context, data []byte, creatingUserId uint64) (*model.ProductImportResponse, error) {
+	ctx, span := tracing.Start(ctx, "ImportsService.ImportProducts")
+	defer span.End()
+
+	report, rows, err := i.prepareImport(ctx, data, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	i.runImport(ctx, report, rows, creatingUserId)
+
+	return i.importRepo.GetByID(ctx, report.ID)
+}
+
+func (i *ImportsService) GetProductImport(ctx context.Context, importId uint64) (*model.ProductImportResponse, error) {
+	ctx, span := tracing.Start(ctx, "ImportsService.GetProductImport")
+	defer span.End()
+
+	return i.importRepo.GetByID(ctx, importId)
+}
+
+// ExportProducts writes every product in the import layout.
+func (i *ImportsService) ExportProducts(ctx context.Context, w io.Writer) error {
+	ctx, span := tracing.Start(ctx, "ImportsService.ExportProducts")
+	defer span.End()
+
+	products, err := i.productRepo.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	writer := export.NewCSVWriter(w)
+	err = writer.WriteHeader(productCSVColumns)
+	if err != nil {
+		return err
+	}
+	for _, product := range products {
+		err = writer.WriteRow(ProductCSVValues(product))
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+// Shutdown waits for imports still running in the background before closing.
+func (i *ImportsService) Shutdown() {
+	i.running.Wait()
+	i.importRepo.Shutdown()
+}
@@ -0,0 +1,87 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/service"
+)
+
+func TestParseProductCSV_withExportedProduct_shouldRoundTrip(t *testing.T) {
+	sku := "MUG-L"
+	product := model.ProductResponse{
+		ID:          7,
+		SKU:         &sku,
+		Title:       "Mug, large",
+		Description: "Holds \"a lot\" of coffee",
+		Price:       zar(12999),
+		Stock:       4,
+		TaxClassID:  2,
+		Weight:      money.NewDecimal(1),
+		CategoryIDs: []uint64{3, 5},
+		Pictures:    []string{"https://cdn.example.com/mug.jpg", "https://cdn.example.com/mug-side.jpg"},
+	}
+
+	var file bytes.Buffer
+	writer := csv.NewWriter(&file)
+	writer.Write([]string{"id", "sku", "title", "description", "price", "stock", "tax_class_id", "weight", "category_ids", "pictures"})
+	writer.Write(service.ProductCSVValues(product))
+	writer.Flush()
+
+	rows, rowErrors, err := service.ParseProductCSV(&file)
+	if err != nil {
+		t.Fatalf("Expected no error but got '%s'", err)
+	}
+	if len(rowErrors) != 0 || len(rows) != 1 {
+		t.Fatalf("Expected one row and no errors but got '%+v' and '%+v'", rows, rowErrors)
+	}
+
+	row := rows[0]
+	request := row.Request
+	if row.Row != 2 || row.ID != 7 || *request.SKU != sku || request.Title != product.Title || request.Description != product.Description {
+		t.Errorf("Expected the product back on row 2 but got '%+v'", row)
+	}
+	if request.Price.Minor() != 12999 || request.Stock != 4 || request.TaxClassID != 2 || request.Weight.Cmp(product.Weight) != 0 {
+		t.Errorf("Expected price, stock, tax class and weight to survive but got '%+v'", request)
+	}
+	if len(request.CategoryIDs) != 2 || request.CategoryIDs[1] != 5 || len(request.Pictures) != 2 || request.Pictures[1] != product.Pictures[1] {
+		t.Errorf("Expected categories and pictures to survive but got '%v' and '%v'", request.CategoryIDs, request.Pictures)
+	}
+}
+
+func TestParseProductCSV_withBadRows_shouldReportLinesAndKeepGoing(t *testing.T) {
+	file := "title,price,stock\n" +
+		"Mug,129.99,4\n" +
+		"Plate,cheap,1\n" +
+		"Bowl,59.99,-2\n" +
+		"Cup,19.99\n"
+
+	rows, rowErrors, err := service.ParseProductCSV(strings.NewReader(file))
+	if err != nil {
+		t.Fatalf("Expected no error but got '%s'", err)
+	}
+	if len(rows) != 2 || rows[0].Row != 2 || rows[1].Row != 5 || rows[1].Request.Stock != 0 || rows[1].Request.TaxClassID != 1 {
+		t.Errorf("Expected rows 2 and 5 with defaults but got '%+v'", rows)
+	}
+	if len(rowErrors) != 2 || rowErrors[0].Row != 3 || !strings.HasPrefix(rowErrors[0].Message, "price") || rowErrors[1].Row != 4 || !strings.HasPrefix(rowErrors[1].Message, "stock") {
+		t.Errorf("Expected price error on row 3 and stock error on row 4 but got '%+v'", rowErrors)
+	}
+}
+
+func TestParseProductCSV_withBadHeader_shouldRefuseFile(t *testing.T) {
+	cases := map[string]string{
+		"empty file":     "",
+		"missing price":  "title,description\nMug,Large\n",
+		"unknown column": "title,price,colour\nMug,129.99,Red\n",
+		"twice":          "title,price,Title\nMug,129.99,Mug\n",
+	}
+	for name, file := range cases {
+		if _, _, err := service.ParseProductCSV(strings.NewReader(file)); err == nil {
+			t.Errorf("Expected %s to be refused", name)
+		}
+	}
+}
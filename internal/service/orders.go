@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Orders interface {
+	CreateOrder(ctx context.Context, body string, creatingUserId uint64) (*model.OrderResponse, error)
+	GetOrder(ctx context.Context, orderId uint64, userId uint64) (*model.OrderResponse, error)
+	Shutdown()
+}
+
+type OrdersService struct {
+	validator   Validator
+	uow         repository.UnitOfWork
+	orderRepo   repository.OrderRepository
+	productRepo repository.ProductRepository
+	userRepo    repository.UserRepository
+	discounts   Discounts
+	shipping    Shipping
+	logger      logger.Logger
+}
+
+func NewOrdersService(validator Validator, uow repository.UnitOfWork, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, discounts Discounts, shipping Shipping, logger logger.Logger) Orders {
+	return &OrdersService{
+		validator:   validator,
+		uow:         uow,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		discounts:   discounts,
+		shipping:    shipping,
+		logger:      logger,
+	}
+}
+
+// snapshotItem copies what the customer is buying onto an order item so the order
+// keeps reading the same after the product or variant is edited.
+func (o *OrdersService) snapshotItem(ctx context.Context, request model.OrderItemRequest) (*model.OrderItemResponse, error) {
+	log := logger.FromContext(ctx, o.logger)
+	product, err := o.productRepo.GetByID(ctx, request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := o.productRepo.GetVariants(ctx, request.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	variant, price, err := SelectVariant(*product, variants, request.VariantID)
+	if err != nil {
+		log.Infof("Variant '%v' cannot be bought for product '%d'", request.VariantID, request.ProductID)
+		return nil, err
+	}
+
+	item := model.OrderItemResponse{
+		ProductID:         &product.ID,
+		ProductTitle:      product.Title,
+		VariantAttributes: make([]model.VariantAttribute, 0),
+		Price:             price,
+		Quantity:          request.Quantity,
+		TaxClassID:        &product.TaxClassID,
+	}
+	if variant != nil {
+		item.VariantID = &variant.ID
+		item.SKU = &variant.SKU
+		item.VariantAttributes = variant.Attributes
+	}
+
+	return &item, nil
+}
+
+// CreateOrder reserves stock for every item and places the order awaiting payment
+// priced the way checkout prices it: promotions and the optional coupon code, tax,
+// and the shipping quote, booking the requested delivery slot if there is one. It
+// all runs in one unit of work so a failed item, slot or insert leaves no stock or
+// slot reserved and no order without its totals.
+func (o *OrdersService) CreateOrder(ctx context.Context, body string, creatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrdersService.CreateOrder")
+	defer span.End()
+	log := logger.FromContext(ctx, o.logger)
+
+	var orderRequest model.OrderRequest
+	err := o.validator.MarshalAndValidateREQ(body, &orderRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.OrderItemResponse, 0, len(orderRequest.Items))
+	for _, itemRequest := range orderRequest.Items {
+		item, err := o.snapshotItem(ctx, itemRequest)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, *item)
+	}
+
+	subtotal := money.Zero(money.DefaultCurrency)
+	for _, item := range items {
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			log.Infof("Unabled to total order line for product '%d': %s", *item.ProductID, err.Error())
+			return nil, types.NewInvalidInputError()
+		}
+		subtotal, err = subtotal.Add(lineTotal)
+		if err != nil {
+			log.Infof("Unabled to total order: %s", err.Error())
+			return nil, types.NewInvalidInputError()
+		}
+	}
+
+	var order *model.OrderResponse
+	err = o.uow.Run(ctx, "CreateOrder", func(tx *repository.Tx) error {
+		productRepo := o.productRepo.WithTx(tx)
+		for _, item := range items {
+			err := productRepo.ReserveStock(ctx, *item.ProductID, item.VariantID, item.Quantity, creatingUserId)
+			if err != nil {
+				return err
+			}
+		}
+
+		order, err = o.orderRepo.WithTx(tx).Create(ctx, orderRequest, items, subtotal, creatingUserId)
+		if err != nil {
+			return err
+		}
+
+		order, err = o.discounts.PriceOrder(ctx, tx, order, orderRequest.Code, creatingUserId)
+		if err != nil {
+			return err
+		}
+
+		order, err = o.shipping.ChargeShipping(ctx, tx, order, orderRequest.DeliverySlotID, orderRequest.DesiredTime, creatingUserId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	metrics.OrdersPlaced.Inc()
+
+	return order, nil
+}
+
+func (o *OrdersService) GetOrder(ctx context.Context, orderId uint64, userId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "OrdersService.GetOrder")
+	defer span.End()
+	log := logger.FromContext(ctx, o.logger)
+
+	order, err := o.orderRepo.GetByID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.CreatedUser != userId {
+		allowed, err := o.userRepo.HasPermission(ctx, userId, constant.VIEW_ANY_ORDER_PERMISSION)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			log.Infof("User '%d' denied access to order '%d'", userId, orderId)
+			return nil, types.NewForbiddenError()
+		}
+	}
+
+	return order, nil
+}
+
+func (o *OrdersService) Shutdown() {
+	o.orderRepo.Shutdown()
+}
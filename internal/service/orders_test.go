@@ -0,0 +1,59 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/types"
+)
+
+// placedOrderRepository finds every order as placed by user 1.
+type placedOrderRepository struct {
+	repository.OrderRepository
+}
+
+func (repo placedOrderRepository) GetByID(ctx context.Context, orderId uint64) (*model.OrderResponse, error) {
+	return &model.OrderResponse{ID: orderId, CreatedUser: 1}, nil
+}
+
+// permittedUserRepository grants only the listed permissions.
+type permittedUserRepository struct {
+	repository.UserRepository
+	permissions []string
+}
+
+func (repo permittedUserRepository) HasPermission(ctx context.Context, userId uint64, permission string) (bool, error) {
+	for _, granted := range repo.permissions {
+		if granted == permission {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func ordersFor(permissions ...string) service.Orders {
+	log := logger.NewSimpleLogger("ERROR", false)
+	return service.NewOrdersService(service.NewValidator(log, *validator.New()), nil, placedOrderRepository{}, nil, permittedUserRepository{permissions: permissions}, nil, nil, log)
+}
+
+func TestGetOrder_withOtherCustomersOrder_shouldForbidViewOrderAlone(t *testing.T) {
+	_, err := ordersFor(constant.VIEW_ORDER_PERMISSION).GetOrder(context.Background(), 7, 2)
+
+	socketErr, ok := err.(*types.SocketError)
+	if !ok || socketErr.StatusCode() != constant.ForbiddenCode {
+		t.Errorf("GetOrder test failed, expected[%d], got[%v]", constant.ForbiddenCode, err)
+	}
+}
+
+func TestGetOrder_withViewAnyOrder_shouldReturnOtherCustomersOrder(t *testing.T) {
+	order, err := ordersFor(constant.VIEW_ANY_ORDER_PERMISSION).GetOrder(context.Background(), 7, 2)
+	if err != nil || order.ID != 7 {
+		t.Errorf("GetOrder test failed, expected[order 7], got[%v, %v]", order, err)
+	}
+}
@@ -1,15 +1,19 @@
 package service
 
 import (
+	"context"
+
+	"tannar.moss/backend/internal/audit"
 	"tannar.moss/backend/internal/logger"
 	"tannar.moss/backend/internal/model"
 	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/types"
 )
 
 type Private interface {
-	UpdateUserInfo(userId uint64, body string, updatingUserId uint64) (*model.UserResponse, error)
-	UpdateUserPassword(userId uint64, body string, updatingUserId uint64) (*model.UserResponse, error)
+	UpdateUserInfo(ctx context.Context, userId uint64, body string, source audit.Source) (*model.UserResponse, error)
+	UpdateUserPassword(ctx context.Context, userId uint64, body string, source audit.Source) (*model.UserResponse, error)
 	Shutdown()
 }
 
@@ -19,14 +23,17 @@ type PrivateService struct {
 	logger    logger.Logger
 }
 
-func (p *PrivateService) UpdateUserInfo(userId uint64, body string, updatingUserId uint64) (*model.UserResponse, error) {
+func (p *PrivateService) UpdateUserInfo(ctx context.Context, userId uint64, body string, source audit.Source) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "PrivateService.UpdateUserInfo")
+	defer span.End()
+
 	var updateUserRequest model.UserUpdateRequest
 	err := p.validator.MarshalAndValidateREQ(body, &updateUserRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := p.userRepo.Update(userId, updateUserRequest.FirstName, updateUserRequest.LastName, updatingUserId)
+	user, err := p.userRepo.Update(ctx, userId, updateUserRequest.FirstName, updateUserRequest.LastName, source)
 	if err != nil {
 		return nil, err
 	}
@@ -34,7 +41,10 @@ func (p *PrivateService) UpdateUserInfo(userId uint64, body string, updatingUser
 	return user, nil
 }
 
-func (p *PrivateService) UpdateUserPassword(userId uint64, body string, updatingUserId uint64) (*model.UserResponse, error) {
+func (p *PrivateService) UpdateUserPassword(ctx context.Context, userId uint64, body string, source audit.Source) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "PrivateService.UpdateUserPassword")
+	defer span.End()
+
 	var updateUserRequest model.ChangePasswordRequest
 	err := p.validator.MarshalAndValidateREQ(body, &updateUserRequest)
 	if err != nil {
@@ -45,7 +55,7 @@ func (p *PrivateService) UpdateUserPassword(userId uint64, body string, updating
 		return nil, types.NewInvalidInputError()
 	}
 
-	user, err := p.userRepo.ResetPassword(userId, updateUserRequest.Password, updatingUserId)
+	user, err := p.userRepo.ResetPassword(ctx, userId, updateUserRequest.Password, source)
 	if err != nil {
 		return nil, err
 	}
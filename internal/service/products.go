@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/search"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Products interface {
+	Search(ctx context.Context, request model.ProductSearchRequest) (*model.ProductSearchResponse, error)
+	CategoryProducts(ctx context.Context, slug string, request model.ProductSearchRequest) (*model.CategoryProductsResponse, error)
+	GetProduct(ctx context.Context, productId uint64) (*model.ProductResponse, error)
+	CreateProduct(ctx context.Context, body string, creatingUserId uint64) (*model.ProductResponse, error)
+	UpdateProduct(ctx context.Context, productId uint64, body string, updatingUserId uint64) (*model.ProductResponse, error)
+	DeleteProduct(ctx context.Context, productId uint64, deletingUserId uint64) error
+	SaveProduct(ctx context.Context, productId uint64, productRequest model.ProductRequest, userId uint64) (*model.ProductResponse, error)
+	Shutdown()
+}
+
+type ProductsService struct {
+	validator    Validator
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	searchIndex  search.Index
+	logger       logger.Logger
+}
+
+func NewProductsService(validator Validator, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, searchIndex search.Index, logger logger.Logger) Products {
+	return &ProductsService{
+		validator:    validator,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		searchIndex:  searchIndex,
+		logger:       logger,
+	}
+}
+
+func (p *ProductsService) Search(ctx context.Context, request model.ProductSearchRequest) (*model.ProductSearchResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.Search")
+	defer span.End()
+
+	categories, err := p.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.search(ctx, request, categories)
+}
+
+func (p *ProductsService) search(ctx context.Context, request model.ProductSearchRequest, categories []model.CategoryResponse) (*model.ProductSearchResponse, error) {
+	log := logger.FromContext(ctx, p.logger)
+	err := p.validator.ValidateREQ(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if (request.MinPrice != nil && request.MinPrice.IsNegative()) || (request.MaxPrice != nil && request.MaxPrice.IsNegative()) {
+		log.Infof("Negative price filter for search '%s'", request.Query)
+		return nil, types.NewInvalidInputError()
+	}
+	if request.MinPrice != nil && request.MaxPrice != nil && request.MinPrice.Minor() > request.MaxPrice.Minor() {
+		log.Infof("Minimum price '%s' is above maximum price '%s'", request.MinPrice, request.MaxPrice)
+		return nil, types.NewInvalidInputError()
+	}
+
+	searchResponse, err := p.searchIndex.Search(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	for i := range searchResponse.Hits {
+		AttachBreadcrumbs(&searchResponse.Hits[i].Product, categories)
+	}
+
+	return searchResponse, nil
+}
+
+// CategoryProducts lists the products in the category or any category beneath it.
+func (p *ProductsService) CategoryProducts(ctx context.Context, slug string, request model.ProductSearchRequest) (*model.CategoryProductsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.CategoryProducts")
+	defer span.End()
+	log := logger.FromContext(ctx, p.logger)
+
+	categories, err := p.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var category *model.CategoryResponse
+	for i := range categories {
+		if categories[i].Slug == slug {
+			category = &categories[i]
+			break
+		}
+	}
+	if category == nil {
+		log.Debugf("No category with slug '%s'", slug)
+		return nil, types.NewNoTFoundOrNoRecordError()
+	}
+
+	request.CategoryIDs = DescendantIDs(categories, category.ID)
+	searchResponse, err := p.search(ctx, request, categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.CategoryProductsResponse{
+		Category:    *category,
+		Breadcrumbs: CategoryTrail(categories, category.ID),
+		Products:    *searchResponse,
+	}, nil
+}
+
+func (p *ProductsService) validateProductRequest(ctx context.Context, body string, productId uint64) (*model.ProductRequest, error) {
+	var productRequest model.ProductRequest
+	err := p.validator.MarshalAndValidateREQ(body, &productRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.checkProductRequest(ctx, productRequest, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &productRequest, nil
+}
+
+// checkProductRequest covers the rules the struct tags cannot: amounts must not be
+// negative, categories must exist and a SKU may only belong to one product.
+func (p *ProductsService) checkProductRequest(ctx context.Context, productRequest model.ProductRequest, productId uint64) error {
+	log := logger.FromContext(ctx, p.logger)
+	if productRequest.Price.IsNegative() || productRequest.Weight.Cmp(money.NewDecimal(0)) < 0 {
+		log.Infof("Product '%s' has a negative price or weight", productRequest.Title)
+		return types.NewInvalidInputError()
+	}
+	for _, categoryId := range productRequest.CategoryIDs {
+		_, err := p.categoryRepo.GetByID(ctx, categoryId)
+		if err != nil {
+			log.Infof("Product '%s' has unknown category '%d'", productRequest.Title, categoryId)
+			return types.NewInvalidInputError()
+		}
+	}
+
+	if productRequest.SKU != nil {
+		existing, err := p.productRepo.GetBySKU(ctx, *productRequest.SKU)
+		if err != nil {
+			if socketErr, ok := err.(*types.SocketError); !ok || socketErr.StatusCode() != constant.NotFoundCode {
+				return err
+			}
+		} else if existing.ID != productId {
+			log.Infof("SKU '%s' is already used by product '%d'", *productRequest.SKU, existing.ID)
+			return types.NewBadRequestError()
+		}
+	}
+
+	return nil
+}
+
+func (p *ProductsService) GetProduct(ctx context.Context, productId uint64) (*model.ProductResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.GetProduct")
+	defer span.End()
+
+	product, err := p.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	product.Variants, err = p.productRepo.GetVariants(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.withBreadcrumbs(ctx, product)
+}
+
+func (p *ProductsService) withBreadcrumbs(ctx context.Context, product *model.ProductResponse) (*model.ProductResponse, error) {
+	categories, err := p.categoryRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	AttachBreadcrumbs(product, categories)
+
+	return product, nil
+}
+
+// indexProduct keeps the search index in step with a product write. The write has
+// already been committed, so a failure is logged rather than returned.
+func (p *ProductsService) indexProduct(ctx context.Context, product *model.ProductResponse) {
+	log := logger.FromContext(ctx, p.logger)
+	err := p.searchIndex.Index(ctx, *product)
+	if err != nil {
+		log.Errorf("Unabled to index product '%d': %s", product.ID, err.Error())
+	}
+}
+
+func (p *ProductsService) CreateProduct(ctx context.Context, body string, creatingUserId uint64) (*model.ProductResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.CreateProduct")
+	defer span.End()
+
+	productRequest, err := p.validateProductRequest(ctx, body, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.saveProduct(ctx, 0, *productRequest, creatingUserId)
+}
+
+func (p *ProductsService) UpdateProduct(ctx context.Context, productId uint64, body string, updatingUserId uint64) (*model.ProductResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.UpdateProduct")
+	defer span.End()
+
+	_, err := p.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	productRequest, err := p.validateProductRequest(ctx, body, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.saveProduct(ctx, productId, *productRequest, updatingUserId)
+}
+
+// SaveProduct creates the product when productId is 0 and otherwise updates it. It
+// is for callers such as imports that build the request themselves and have
+// already run it through the validator.
+func (p *ProductsService) SaveProduct(ctx context.Context, productId uint64, productRequest model.ProductRequest, userId uint64) (*model.ProductResponse, error) {
+	ctx, span := tracing.Start(ctx, "ProductsService.SaveProduct")
+	defer span.End()
+
+	err := p.checkProductRequest(ctx, productRequest, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.saveProduct(ctx, productId, productRequest, userId)
+}
+
+func (p *ProductsService) saveProduct(ctx context.Context, productId uint64, productRequest model.ProductRequest, userId uint64) (*model.ProductResponse, error) {
+	var product *model.ProductResponse
+	var err error
+	if productId == 0 {
+		product, err = p.productRepo.Create(ctx, productRequest, userId)
+	} else {
+		product, err = p.productRepo.Update(ctx, productId, productRequest, userId)
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.indexProduct(ctx, product)
+
+	return p.withBreadcrumbs(ctx, product)
+}
+
+func (p *ProductsService) DeleteProduct(ctx context.Context, productId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "ProductsService.DeleteProduct")
+	defer span.End()
+	log := logger.FromContext(ctx, p.logger)
+
+	_, err := p.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return err
+	}
+
+	err = p.productRepo.Delete(ctx, productId, deletingUserId)
+	if err != nil {
+		return err
+	}
+
+	err = p.searchIndex.Remove(ctx, productId)
+	if err != nil {
+		log.Errorf("Unabled to remove product '%d' from search index: %s", productId, err.Error())
+	}
+
+	return nil
+}
+
+func (p *ProductsService) Shutdown() {
+	p.productRepo.Shutdown()
+	p.categoryRepo.Shutdown()
+	p.searchIndex.Shutdown()
+}
@@ -1,23 +1,27 @@
 package service
 
 import (
+	"context"
 	"strconv"
 
+	"tannar.moss/backend/internal/audit"
 	"tannar.moss/backend/internal/constant"
 	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
 	"tannar.moss/backend/internal/model"
 	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/types"
 	"tannar.moss/backend/internal/utils"
 )
 
 type Public interface {
-	IsAuthenticated(jwt string) error
-	IsAuthorized(jwt string, page string) error
-	User(jwt string) (*model.UserResponse, error)
-	Logout(jwt string) error
-	Register(body string) (*model.LoginResponse, error)
-	Login(body string) (*model.LoginResponse, error)
+	IsAuthenticated(ctx context.Context, jwt string) error
+	IsAuthorized(ctx context.Context, jwt string, page string) error
+	User(ctx context.Context, jwt string) (*model.UserResponse, error)
+	Logout(ctx context.Context, jwt string) error
+	Register(ctx context.Context, body string, sourceIp string) (*model.LoginResponse, error)
+	Login(ctx context.Context, body string) (*model.LoginResponse, error)
 	Shutdown()
 }
 
@@ -68,7 +72,10 @@ func (auth *PublicService) checkJwt(jwt string) (string, error) {
 	return Id, nil
 }
 
-func (auth *PublicService) IsAuthenticated(jwt string) error {
+func (auth *PublicService) IsAuthenticated(ctx context.Context, jwt string) error {
+	ctx, span := tracing.Start(ctx, "PublicService.IsAuthenticated")
+	defer span.End()
+
 	if _, err := auth.checkJwt(jwt); err != nil {
 		return err
 	}
@@ -76,48 +83,87 @@ func (auth *PublicService) IsAuthenticated(jwt string) error {
 	return nil
 }
 
-func (auth *PublicService) IsAuthorized(jwt string, page string) error {
-	_, err := auth.checkJwt(jwt)
+func (auth *PublicService) IsAuthorized(ctx context.Context, jwt string, page string) error {
+	ctx, span := tracing.Start(ctx, "PublicService.IsAuthorized")
+	defer span.End()
+	log := logger.FromContext(ctx, auth.logger)
+
+	issuer, err := auth.checkJwt(jwt)
 	if err != nil {
-		return err
+		return types.NewUnauthorizedError()
+	}
+
+	userId, err := strconv.ParseUint(issuer, 10, 64)
+	if err != nil {
+		log.Errorf("Cant parse as Uint: '%s'", issuer)
+		return types.NewInternalServerError()
 	}
 
-	// check page permission
+	allowed, err := auth.userRepo.HasPermission(ctx, userId, page)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Infof("User '%d' denied access to '%s'", userId, page)
+		return types.NewForbiddenError()
+	}
 
 	return nil
 }
 
-func (auth PublicService) Login(body string) (*model.LoginResponse, error) {
+func (auth PublicService) Login(ctx context.Context, body string) (*model.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "PublicService.Login")
+	defer span.End()
+	log := logger.FromContext(ctx, auth.logger)
+
 	var loginRequest model.LoginRequest
 	err := auth.validator.MarshalAndValidateREQ(body, &loginRequest)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := auth.userRepo.GetByEmail(loginRequest.Username)
+	user, err := auth.userRepo.GetByEmail(ctx, loginRequest.Username)
 	if err != nil {
+		if socketErr, ok := err.(*types.SocketError); ok && socketErr.StatusCode() == constant.NotFoundCode {
+			metrics.FailedLogins.Inc()
+			log.Infof("Failed login attempt for unknown '%s'", loginRequest.Username)
+		}
 		return nil, err
 	}
 
 	if !utils.ComparePassword(user.HashedPassword, loginRequest.Password) {
-		auth.logger.Infof("Failed login attempt for '%s' with '%s'", loginRequest.Username, loginRequest.Password)
+		metrics.FailedLogins.Inc()
+		log.Infof("Failed login attempt for '%s'", loginRequest.Username)
 		return nil, types.NewUnauthorizedError()
 	}
 
-	return auth.generateLoginResponseFromUser(*user)
+	loginResponse, err := auth.generateLoginResponseFromUser(*user)
+	if err != nil {
+		return nil, err
+	}
+	metrics.Logins.Inc()
+
+	return loginResponse, nil
 
 }
 
-func (auth *PublicService) Logout(jwt string) error {
+func (auth *PublicService) Logout(ctx context.Context, jwt string) error {
+	ctx, span := tracing.Start(ctx, "PublicService.Logout")
+	defer span.End()
+	log := logger.FromContext(ctx, auth.logger)
+
 	userId, err := utils.GetIssuerFromJwt(jwt, constant.PASSWORD_SECRET_HASHING_KEY)
 	if err != nil {
-		auth.logger.Errorf("Unabled to logout token: '%s'", jwt)
+		log.Errorf("Unabled to logout token: '%s'", jwt)
 	}
-	auth.logger.Debugf("Logged out user with Id = %d and token: '%s'", userId, jwt)
+	log.Debugf("Logged out user with Id = %d and token: '%s'", userId, jwt)
 	return nil
 }
 
-func (auth *PublicService) Register(body string) (*model.LoginResponse, error) {
+func (auth *PublicService) Register(ctx context.Context, body string, sourceIp string) (*model.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "PublicService.Register")
+	defer span.End()
+
 	var registerRequest model.UserRequest
 	err := auth.validator.MarshalAndValidateREQ(body, &registerRequest)
 	if err != nil {
@@ -128,28 +174,33 @@ func (auth *PublicService) Register(body string) (*model.LoginResponse, error) {
 		return nil, types.NewInvalidInputError()
 	}
 
-	user, err := auth.userRepo.Register(registerRequest.FirstName, registerRequest.LastName, registerRequest.Email, registerRequest.Password, constant.CUSTOMER_ROLE_ID)
+	user, err := auth.userRepo.Register(ctx, registerRequest.FirstName, registerRequest.LastName, registerRequest.Email, registerRequest.Password, constant.CUSTOMER_ROLE_ID, audit.Source{ActorID: repository.MySystemAutoID, SourceIP: sourceIp})
 	if err != nil {
 		return nil, err
 	}
+	metrics.Registrations.Inc()
 
 	return auth.generateLoginResponseFromUser(*user)
 }
 
-func (auth *PublicService) User(jwt string) (*model.UserResponse, error) {
+func (auth *PublicService) User(ctx context.Context, jwt string) (*model.UserResponse, error) {
+	ctx, span := tracing.Start(ctx, "PublicService.User")
+	defer span.End()
+	log := logger.FromContext(ctx, auth.logger)
+
 	issuer, err := utils.GetIssuerFromJwt(jwt, constant.PASSWORD_SECRET_HASHING_KEY)
 	if err != nil {
-		auth.logger.Errorf("Cant get issuer from jwt '%s'", issuer)
+		log.Errorf("Cant get issuer from jwt '%s'", issuer)
 		return nil, types.NewInternalServerError()
 	}
 	userId, err := strconv.ParseUint(issuer, 10, 64)
 	if err != nil {
-		auth.logger.Errorf("Cant parse as Uint: '%s'", issuer)
+		log.Errorf("Cant parse as Uint: '%s'", issuer)
 		return nil, types.NewInternalServerError()
 	}
-	user, err := auth.userRepo.GetByID(userId)
+	user, err := auth.userRepo.GetByID(ctx, userId)
 	if err != nil {
-		auth.logger.Errorf("No user from token: '%s'", jwt)
+		log.Errorf("No user from token: '%s'", jwt)
 		return nil, types.NewInternalServerError()
 	}
 	return user, nil
@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/types"
+)
+
+// unknownUserRepository finds no user by email, as the MySQL repository reports a
+// missing row.
+type unknownUserRepository struct {
+	repository.UserRepository
+}
+
+func (repo unknownUserRepository) GetByEmail(ctx context.Context, email string) (*model.UserResponse, error) {
+	return nil, types.NewNoTFoundOrNoRecordError()
+}
+
+func TestLogin_withUnknownEmail_shouldCountFailedLoginNotQueryError(t *testing.T) {
+	log := logger.NewSimpleLogger("ERROR", false)
+	public := service.NewPublicService(service.NewValidator(log, *validator.New()), unknownUserRepository{}, log)
+	failedBefore := metrics.FailedLogins.Value()
+	queryErrorsBefore := metrics.DBQueryErrors.Value("GetByEmail", metrics.QueryErrorFailed)
+
+	_, err := public.Login(context.Background(), `{"username": "nobody@example.com", "password": "secret"}`)
+
+	socketErr, ok := err.(*types.SocketError)
+	if !ok || socketErr.StatusCode() != constant.NotFoundCode {
+		t.Fatalf("Expected a not found error but got %v", err)
+	}
+	if metrics.FailedLogins.Value() != failedBefore+1 {
+		t.Errorf("Expected the failed login to be counted")
+	}
+	if metrics.DBQueryErrors.Value("GetByEmail", metrics.QueryErrorFailed) != queryErrorsBefore {
+		t.Errorf("Expected no query error to be counted")
+	}
+}
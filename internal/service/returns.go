@@ -0,0 +1,416 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/payment"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Returns interface {
+	CancelOrder(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.ReturnResponse, error)
+	CreateReturn(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.ReturnResponse, error)
+	GetReturn(ctx context.Context, returnId uint64, userId uint64) (*model.ReturnResponse, error)
+	GetOrderReturns(ctx context.Context, orderId uint64, userId uint64) ([]model.ReturnResponse, error)
+	ApproveReturn(ctx context.Context, returnId uint64, updatingUserId uint64) (*model.ReturnResponse, error)
+	RejectReturn(ctx context.Context, returnId uint64, updatingUserId uint64) (*model.ReturnResponse, error)
+	Shutdown()
+}
+
+type ReturnsService struct {
+	validator    Validator
+	uow          repository.UnitOfWork
+	orderRepo    repository.OrderRepository
+	returnRepo   repository.ReturnRepository
+	productRepo  repository.ProductRepository
+	shippingRepo repository.ShippingRepository
+	userRepo     repository.UserRepository
+	gateway      payment.Gateway
+	logger       logger.Logger
+}
+
+func NewReturnsService(validator Validator, uow repository.UnitOfWork, orderRepo repository.OrderRepository, returnRepo repository.ReturnRepository, productRepo repository.ProductRepository, shippingRepo repository.ShippingRepository, userRepo repository.UserRepository, gateway payment.Gateway, logger logger.Logger) Returns {
+	return &ReturnsService{
+		validator:    validator,
+		uow:          uow,
+		orderRepo:    orderRepo,
+		returnRepo:   returnRepo,
+		productRepo:  productRepo,
+		shippingRepo: shippingRepo,
+		userRepo:     userRepo,
+		gateway:      gateway,
+		logger:       logger,
+	}
+}
+
+// PaidLineTotal is what the customer paid for an order line: the snapshotted price
+// less its share of the order discounts, plus any tax charged on top of the price.
+func PaidLineTotal(orderItem model.OrderItemResponse) (money.Money, error) {
+	paid, err := orderItem.Price.Mul(int64(orderItem.Quantity))
+	if err != nil {
+		return money.Money{}, err
+	}
+	paid, err = paid.Sub(orderItem.DiscountAmount)
+	if err != nil {
+		return money.Money{}, err
+	}
+	if !orderItem.PricesIncludeTax {
+		paid, err = paid.Add(orderItem.TaxAmount)
+		if err != nil {
+			return money.Money{}, err
+		}
+	}
+
+	return paid, nil
+}
+
+// CalculateReturnItems checks the requested quantities against what is still
+// returnable on the order and refunds each line pro rata from what was paid for it.
+func CalculateReturnItems(orderItems []model.OrderItemResponse, requested []model.ReturnItemRequest, returned map[uint64]uint64) ([]model.ReturnItemResponse, error) {
+	byId := make(map[uint64]model.OrderItemResponse, len(orderItems))
+	for _, item := range orderItems {
+		byId[item.ID] = item
+	}
+
+	requestedQuantities := make(map[uint64]uint64, len(requested))
+	order := make([]uint64, 0, len(requested))
+	for _, request := range requested {
+		if _, ok := requestedQuantities[request.OrderItemID]; !ok {
+			order = append(order, request.OrderItemID)
+		}
+		requestedQuantities[request.OrderItemID] += request.Quantity
+	}
+
+	items := make([]model.ReturnItemResponse, 0, len(order))
+	for _, orderItemId := range order {
+		orderItem, ok := byId[orderItemId]
+		if !ok {
+			return nil, types.NewInvalidInputError()
+		}
+
+		quantity := requestedQuantities[orderItemId]
+		if quantity == 0 || returned[orderItemId]+quantity > orderItem.Quantity {
+			return nil, types.NewInvalidInputError()
+		}
+
+		paid, err := PaidLineTotal(orderItem)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		refundAmount, err := paid.MulRatio(int64(quantity), int64(orderItem.Quantity), money.HalfUp)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+
+		items = append(items, model.ReturnItemResponse{
+			OrderItemID:  orderItem.ID,
+			ProductID:    orderItem.ProductID,
+			Quantity:     quantity,
+			RefundAmount: refundAmount,
+		})
+	}
+
+	return items, nil
+}
+
+func (r *ReturnsService) checkOrderAccess(ctx context.Context, order *model.OrderResponse, userId uint64) error {
+	log := logger.FromContext(ctx, r.logger)
+	if order.CreatedUser == userId {
+		return nil
+	}
+
+	allowed, err := r.userRepo.HasPermission(ctx, userId, constant.REFUND_ORDER_PERMISSION)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.Infof("User '%d' denied access to returns for order '%d'", userId, order.ID)
+		return types.NewForbiddenError()
+	}
+
+	return nil
+}
+
+func (r *ReturnsService) getAccessibleOrder(ctx context.Context, orderId uint64, userId uint64) (*model.OrderResponse, error) {
+	order, err := r.orderRepo.GetByID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.checkOrderAccess(ctx, order, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (r *ReturnsService) restoreStock(ctx context.Context, tx *repository.Tx, rma *model.ReturnResponse, updatingUserId uint64) error {
+	log := logger.FromContext(ctx, r.logger)
+	productRepo := r.productRepo.WithTx(tx)
+	for _, item := range rma.Items {
+		if item.ProductID == nil {
+			log.Warnf("Order item '%d' has no product, skipping stock restore", item.OrderItemID)
+			continue
+		}
+		err := productRepo.RestoreStock(ctx, *item.ProductID, item.VariantID, item.Quantity, updatingUserId)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *ReturnsService) releaseDeliverySlot(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, updatingUserId uint64) error {
+	details, err := r.orderRepo.WithTx(tx).GetDeliveryDetails(ctx, order.DeliveryDetailsID)
+	if err != nil {
+		return err
+	}
+	if details.DeliverySlotID == nil {
+		return nil
+	}
+
+	return r.shippingRepo.WithTx(tx).ReleaseSlot(ctx, *details.DeliverySlotID, updatingUserId)
+}
+
+// refund pays out an approved return. The return's RMA number is the gateway's
+// idempotency key, so retrying after a refund whose reference failed to save does
+// not pay out twice.
+func (r *ReturnsService) refund(ctx context.Context, rma *model.ReturnResponse, updatingUserId uint64) (*model.ReturnResponse, error) {
+	log := logger.FromContext(ctx, r.logger)
+	if rma.PaymentReference != nil {
+		log.Infof("Return '%d' was already refunded with reference '%s'", rma.ID, *rma.PaymentReference)
+		return rma, nil
+	}
+
+	reference, err := r.gateway.Refund(rma.OrderID, rma.RefundAmount, fmt.Sprintf("RMA-%d", rma.ID))
+	if err != nil {
+		log.Errorf("Refund failed for return '%d': %s", rma.ID, err.Error())
+		return nil, err
+	}
+
+	return r.returnRepo.UpdateStatus(ctx, rma.ID, constant.RETURN_STATUS_APPROVED, constant.RETURN_STATUS_REFUNDED, &reference, updatingUserId)
+}
+
+func (r *ReturnsService) CancelOrder(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.CancelOrder")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	var cancelRequest model.CancelOrderRequest
+	err := r.validator.MarshalAndValidateREQ(body, &cancelRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := r.getAccessibleOrder(ctx, orderId, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.StatusID != constant.ORDER_STATUS_AWAITING_PAYMENT && order.StatusID != constant.ORDER_STATUS_PENDING {
+		log.Infof("Order '%d' with status '%d' can no longer be cancelled", orderId, order.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	returned, err := r.returnRepo.GetReturnedQuantities(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	outstanding := make([]model.ReturnItemRequest, 0, len(order.Items))
+	for _, item := range order.Items {
+		if item.Quantity > returned[item.ID] {
+			outstanding = append(outstanding, model.ReturnItemRequest{
+				OrderItemID: item.ID,
+				Quantity:    item.Quantity - returned[item.ID],
+			})
+		}
+	}
+
+	items, err := CalculateReturnItems(order.Items, outstanding, returned)
+	if err != nil {
+		return nil, err
+	}
+
+	// nothing was captured for an order still awaiting payment
+	if order.StatusID == constant.ORDER_STATUS_AWAITING_PAYMENT {
+		for i := range items {
+			items[i].RefundAmount = money.Zero(items[i].RefundAmount.Currency())
+		}
+	}
+
+	// the refund goes to the payment provider, so only the bookkeeping before it is
+	// rolled back together. The order is cancelled first and only from the status
+	// read above, so a racing cancel fails before restoring stock a second time.
+	var rma *model.ReturnResponse
+	err = r.uow.Run(ctx, "CancelOrder", func(tx *repository.Tx) error {
+		_, err := r.orderRepo.WithTx(tx).UpdateStatus(ctx, orderId, order.StatusID, constant.ORDER_STATUS_CANCELLED, updatingUserId)
+		if err != nil {
+			return err
+		}
+
+		rma, err = r.returnRepo.WithTx(tx).Create(ctx, orderId, constant.RETURN_STATUS_APPROVED, cancelRequest.Reason, items, updatingUserId)
+		if err != nil {
+			return err
+		}
+
+		err = r.restoreStock(ctx, tx, rma, updatingUserId)
+		if err != nil {
+			return err
+		}
+
+		return r.releaseDeliverySlot(ctx, tx, order, updatingUserId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !rma.RefundAmount.IsPositive() {
+		return rma, nil
+	}
+
+	return r.refund(ctx, rma, updatingUserId)
+}
+
+func (r *ReturnsService) CreateReturn(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.CreateReturn")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	var returnRequest model.ReturnRequest
+	err := r.validator.MarshalAndValidateREQ(body, &returnRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := r.getAccessibleOrder(ctx, orderId, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.StatusID != constant.ORDER_STATUS_COMPLETE {
+		log.Infof("Order '%d' with status '%d' is not eligible for returns", orderId, order.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	returned, err := r.returnRepo.GetReturnedQuantities(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := CalculateReturnItems(order.Items, returnRequest.Items, returned)
+	if err != nil {
+		return nil, err
+	}
+
+	var rma *model.ReturnResponse
+	err = r.uow.Run(ctx, "CreateReturn", func(tx *repository.Tx) error {
+		rma, err = r.returnRepo.WithTx(tx).Create(ctx, orderId, constant.RETURN_STATUS_REQUESTED, returnRequest.Reason, items, updatingUserId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+func (r *ReturnsService) GetReturn(ctx context.Context, returnId uint64, userId uint64) (*model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.GetReturn")
+	defer span.End()
+
+	rma, err := r.returnRepo.GetByID(ctx, returnId)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.getAccessibleOrder(ctx, rma.OrderID, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rma, nil
+}
+
+func (r *ReturnsService) GetOrderReturns(ctx context.Context, orderId uint64, userId uint64) ([]model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.GetOrderReturns")
+	defer span.End()
+
+	_, err := r.getAccessibleOrder(ctx, orderId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.returnRepo.GetByOrderID(ctx, orderId)
+}
+
+// ApproveReturn restores stock for a requested return and then refunds it. A return
+// left approved by a failed refund can be approved again to retry the refund only.
+// The return leaves requested in the same unit of work that restores its stock, so
+// of two racing approvals only one restores stock.
+func (r *ReturnsService) ApproveReturn(ctx context.Context, returnId uint64, updatingUserId uint64) (*model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.ApproveReturn")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	rma, err := r.returnRepo.GetByID(ctx, returnId)
+	if err != nil {
+		return nil, err
+	}
+
+	switch rma.StatusID {
+	case constant.RETURN_STATUS_REQUESTED:
+		err = r.uow.Run(ctx, "ApproveReturn", func(tx *repository.Tx) error {
+			approved, err := r.returnRepo.WithTx(tx).UpdateStatus(ctx, returnId, constant.RETURN_STATUS_REQUESTED, constant.RETURN_STATUS_APPROVED, nil, updatingUserId)
+			if err != nil {
+				return err
+			}
+			rma = approved
+			return r.restoreStock(ctx, tx, rma, updatingUserId)
+		})
+		if err != nil {
+			return nil, err
+		}
+	case constant.RETURN_STATUS_APPROVED:
+	default:
+		log.Infof("Return '%d' with status '%d' can not be approved", returnId, rma.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	if !rma.RefundAmount.IsPositive() {
+		return rma, nil
+	}
+
+	return r.refund(ctx, rma, updatingUserId)
+}
+
+func (r *ReturnsService) RejectReturn(ctx context.Context, returnId uint64, updatingUserId uint64) (*model.ReturnResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReturnsService.RejectReturn")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	rma, err := r.returnRepo.GetByID(ctx, returnId)
+	if err != nil {
+		return nil, err
+	}
+
+	if rma.StatusID != constant.RETURN_STATUS_REQUESTED {
+		log.Infof("Return '%d' with status '%d' can not be rejected", returnId, rma.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	return r.returnRepo.UpdateStatus(ctx, returnId, constant.RETURN_STATUS_REQUESTED, constant.RETURN_STATUS_REJECTED, nil, updatingUserId)
+}
+
+func (r *ReturnsService) Shutdown() {
+	r.returnRepo.Shutdown()
+}
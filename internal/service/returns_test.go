@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func testOrderItems() []model.OrderItemResponse {
+	productId := uint64(7)
+	return []model.OrderItemResponse{
+		{ID: 1, ProductID: &productId, ProductTitle: "Product 1", Price: zar(2999), Quantity: 3},
+		{ID: 2, ProductTitle: "Product 2", Price: zar(1999), Quantity: 1},
+	}
+}
+
+func TestCalculateReturnItems_withPartialQuantity_shouldPriceFromOrderItem(t *testing.T) {
+	items, err := service.CalculateReturnItems(testOrderItems(), []model.ReturnItemRequest{
+		{OrderItemID: 1, Quantity: 2},
+	}, map[uint64]uint64{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 return item but got %d", len(items))
+	}
+	if items[0].RefundAmount.Minor() != 5998 {
+		t.Errorf("Expected refund amount 59.98 but got %s", items[0].RefundAmount)
+	}
+	if items[0].ProductID == nil || *items[0].ProductID != 7 {
+		t.Errorf("Expected product id 7 to be carried onto the return item")
+	}
+}
+
+func TestCalculateReturnItems_withDuplicateLines_shouldMergeQuantities(t *testing.T) {
+	items, err := service.CalculateReturnItems(testOrderItems(), []model.ReturnItemRequest{
+		{OrderItemID: 1, Quantity: 1},
+		{OrderItemID: 1, Quantity: 2},
+	}, map[uint64]uint64{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if len(items) != 1 || items[0].Quantity != 3 {
+		t.Errorf("Expected a single line with quantity 3 but got %+v", items)
+	}
+}
+
+func TestCalculateReturnItems_withAlreadyReturnedQuantity_shouldRejectOverReturn(t *testing.T) {
+	_, err := service.CalculateReturnItems(testOrderItems(), []model.ReturnItemRequest{
+		{OrderItemID: 1, Quantity: 2},
+	}, map[uint64]uint64{1: 2})
+	if err == nil {
+		t.Error("Expected error when returning more than the outstanding quantity")
+	}
+}
+
+func TestCalculateReturnItems_withUnknownOrderItem_shouldReject(t *testing.T) {
+	_, err := service.CalculateReturnItems(testOrderItems(), []model.ReturnItemRequest{
+		{OrderItemID: 99, Quantity: 1},
+	}, map[uint64]uint64{})
+	if err == nil {
+		t.Error("Expected error for an order item not on the order")
+	}
+}
+
+func TestCalculateReturnItems_withDiscountAndExclusiveTax_shouldRefundWhatWasPaid(t *testing.T) {
+	orderItems := []model.OrderItemResponse{
+		{ID: 1, ProductTitle: "Product 1", Price: zar(10000), Quantity: 2, DiscountAmount: zar(2000), TaxAmount: zar(2700)},
+	}
+
+	items, err := service.CalculateReturnItems(orderItems, []model.ReturnItemRequest{
+		{OrderItemID: 1, Quantity: 1},
+	}, map[uint64]uint64{})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+
+	if items[0].RefundAmount.Minor() != 10350 {
+		t.Errorf("Expected refund amount 103.50 but got %s", items[0].RefundAmount)
+	}
+}
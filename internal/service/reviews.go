@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Reviews interface {
+	GetProductReviews(ctx context.Context, productId uint64) (*model.ProductReviewsResponse, error)
+	GetReviewQueue(ctx context.Context, statusId uint64) ([]model.ReviewResponse, error)
+	CreateReview(ctx context.Context, productId uint64, body string, creatingUserId uint64) (*model.ReviewResponse, error)
+	UpdateReview(ctx context.Context, reviewId uint64, body string, updatingUserId uint64) (*model.ReviewResponse, error)
+	ModerateReview(ctx context.Context, reviewId uint64, body string, moderatingUserId uint64) (*model.ReviewResponse, error)
+	DeleteReview(ctx context.Context, reviewId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type ReviewsService struct {
+	validator   Validator
+	reviewRepo  repository.ReviewRepository
+	productRepo repository.ProductRepository
+	userRepo    repository.UserRepository
+	logger      logger.Logger
+}
+
+func NewReviewsService(validator Validator, reviewRepo repository.ReviewRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, logger logger.Logger) Reviews {
+	return &ReviewsService{
+		validator:   validator,
+		reviewRepo:  reviewRepo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		logger:      logger,
+	}
+}
+
+// RatingSummary averages star ratings to two decimal places, rounding halves up
+// the same way the product queries do, and counts them.
+func RatingSummary(ratings []uint64) (money.Decimal, uint64) {
+	count := uint64(len(ratings))
+	if count == 0 {
+		return money.Decimal{}, 0
+	}
+
+	var sum uint64
+	for _, rating := range ratings {
+		sum += rating
+	}
+	hundredths := (sum*200 + count) / (2 * count)
+	average, _ := money.ParseDecimal(fmt.Sprintf("%d.%02d", hundredths/100, hundredths%100))
+
+	return average, count
+}
+
+func (r *ReviewsService) GetProductReviews(ctx context.Context, productId uint64) (*model.ProductReviewsResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.GetProductReviews")
+	defer span.End()
+
+	_, err := r.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := r.reviewRepo.GetByProductID(ctx, productId, constant.REVIEW_STATUS_APPROVED)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := make([]uint64, 0, len(reviews))
+	for _, review := range reviews {
+		ratings = append(ratings, review.Rating)
+	}
+	average, count := RatingSummary(ratings)
+
+	return &model.ProductReviewsResponse{
+		ProductID:     productId,
+		RatingAverage: average,
+		RatingCount:   count,
+		Reviews:       reviews,
+	}, nil
+}
+
+func (r *ReviewsService) GetReviewQueue(ctx context.Context, statusId uint64) ([]model.ReviewResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.GetReviewQueue")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	if statusId < constant.REVIEW_STATUS_PENDING || statusId > constant.REVIEW_STATUS_REJECTED {
+		log.Infof("Unknown review status '%d'", statusId)
+		return nil, types.NewInvalidInputError()
+	}
+
+	return r.reviewRepo.GetByStatus(ctx, statusId)
+}
+
+func (r *ReviewsService) CreateReview(ctx context.Context, productId uint64, body string, creatingUserId uint64) (*model.ReviewResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.CreateReview")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	var reviewRequest model.ReviewRequest
+	err := r.validator.MarshalAndValidateREQ(body, &reviewRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	purchased, err := r.reviewRepo.HasCompletedOrder(ctx, creatingUserId, productId)
+	if err != nil {
+		return nil, err
+	}
+	if !purchased {
+		log.Infof("User '%d' has no completed order for product '%d'", creatingUserId, productId)
+		return nil, types.NewForbiddenError()
+	}
+
+	// a concurrent create that slips past this check breaks the unique user and
+	// product index, which also comes back as a bad request
+	existing, err := r.reviewRepo.GetByUserAndProduct(ctx, creatingUserId, productId)
+	if err != nil {
+		if socketErr, ok := err.(*types.SocketError); !ok || socketErr.StatusCode() != constant.NotFoundCode {
+			return nil, err
+		}
+	} else {
+		log.Infof("User '%d' already reviewed product '%d' in review '%d'", creatingUserId, productId, existing.ID)
+		return nil, types.NewBadRequestError()
+	}
+
+	return r.reviewRepo.Create(ctx, productId, reviewRequest, creatingUserId)
+}
+
+func (r *ReviewsService) UpdateReview(ctx context.Context, reviewId uint64, body string, updatingUserId uint64) (*model.ReviewResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.UpdateReview")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	var reviewRequest model.ReviewRequest
+	err := r.validator.MarshalAndValidateREQ(body, &reviewRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	review, err := r.reviewRepo.GetByID(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.CreatedUser != updatingUserId {
+		log.Infof("User '%d' cannot edit review '%d'", updatingUserId, reviewId)
+		return nil, types.NewForbiddenError()
+	}
+
+	return r.reviewRepo.Update(ctx, reviewId, reviewRequest, updatingUserId)
+}
+
+func (r *ReviewsService) ModerateReview(ctx context.Context, reviewId uint64, body string, moderatingUserId uint64) (*model.ReviewResponse, error) {
+	ctx, span := tracing.Start(ctx, "ReviewsService.ModerateReview")
+	defer span.End()
+
+	var moderationRequest model.ReviewModerationRequest
+	err := r.validator.MarshalAndValidateREQ(body, &moderationRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.reviewRepo.GetByID(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.reviewRepo.Moderate(ctx, reviewId, moderationRequest, moderatingUserId)
+}
+
+func (r *ReviewsService) DeleteReview(ctx context.Context, reviewId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "ReviewsService.DeleteReview")
+	defer span.End()
+	log := logger.FromContext(ctx, r.logger)
+
+	review, err := r.reviewRepo.GetByID(ctx, reviewId)
+	if err != nil {
+		return err
+	}
+
+	if review.CreatedUser != deletingUserId {
+		allowed, err := r.userRepo.HasPermission(ctx, deletingUserId, constant.MODERATE_REVIEW_PERMISSION)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			log.Infof("User '%d' cannot delete review '%d'", deletingUserId, reviewId)
+			return types.NewForbiddenError()
+		}
+	}
+
+	return r.reviewRepo.Delete(ctx, reviewId, deletingUserId)
+}
+
+func (r *ReviewsService) Shutdown() {
+	r.reviewRepo.Shutdown()
+}
@@ -0,0 +1,34 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/service"
+)
+
+func TestRatingSummary_withRatings_shouldRoundAverageHalfUp(t *testing.T) {
+	cases := map[string]struct {
+		ratings []uint64
+		average string
+	}{
+		"whole":        {[]uint64{4, 4}, "4"},
+		"repeating":    {[]uint64{5, 4, 4}, "4.33"},
+		"half up":      {[]uint64{5, 5, 5, 4, 4, 4, 4, 4}, "4.38"},
+		"single star":  {[]uint64{1}, "1"},
+		"two thirds":   {[]uint64{1, 2, 2}, "1.67"},
+		"mixed scores": {[]uint64{1, 5}, "3"},
+	}
+	for name, c := range cases {
+		average, count := service.RatingSummary(c.ratings)
+		if average.String() != c.average || count != uint64(len(c.ratings)) {
+			t.Errorf("Expected %s ratings '%v' to average '%s' over %d but got '%s' over %d", name, c.ratings, c.average, len(c.ratings), average, count)
+		}
+	}
+}
+
+func TestRatingSummary_withoutRatings_shouldBeZero(t *testing.T) {
+	average, count := service.RatingSummary(nil)
+	if !average.IsZero() || count != 0 {
+		t.Errorf("Expected no ratings to summarise as zero but got '%s' over %d", average, count)
+	}
+}
@@ -0,0 +1,492 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Shipping interface {
+	AllZones(ctx context.Context) ([]model.ShippingZoneResponse, error)
+	GetZone(ctx context.Context, zoneId uint64) (*model.ShippingZoneResponse, error)
+	CreateZone(ctx context.Context, body string, creatingUserId uint64) (*model.ShippingZoneResponse, error)
+	UpdateZone(ctx context.Context, zoneId uint64, body string, updatingUserId uint64) (*model.ShippingZoneResponse, error)
+	DeleteZone(ctx context.Context, zoneId uint64, deletingUserId uint64) error
+	GetSlots(ctx context.Context, zoneId uint64, from time.Time, to time.Time) ([]model.DeliverySlotResponse, error)
+	CreateSlot(ctx context.Context, body string, creatingUserId uint64) (*model.DeliverySlotResponse, error)
+	DeleteSlot(ctx context.Context, slotId uint64, deletingUserId uint64) error
+	Quote(ctx context.Context, orderId uint64, userId uint64) (*model.ShippingQuoteResponse, error)
+	ReserveSlot(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.OrderResponse, error)
+	ChargeShipping(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, deliverySlotId *uint64, desiredTime *time.Time, updatingUserId uint64) (*model.OrderResponse, error)
+	Shutdown()
+}
+
+type ShippingService struct {
+	validator    Validator
+	uow          repository.UnitOfWork
+	shippingRepo repository.ShippingRepository
+	orderRepo    repository.OrderRepository
+	productRepo  repository.ProductRepository
+	userRepo     repository.UserRepository
+	logger       logger.Logger
+}
+
+func NewShippingService(validator Validator, uow repository.UnitOfWork, shippingRepo repository.ShippingRepository, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, userRepo repository.UserRepository, logger logger.Logger) Shipping {
+	return &ShippingService{
+		validator:    validator,
+		uow:          uow,
+		shippingRepo: shippingRepo,
+		orderRepo:    orderRepo,
+		productRepo:  productRepo,
+		userRepo:     userRepo,
+		logger:       logger,
+	}
+}
+
+// FindShippingZone picks the most specific zone for the address: an area zone beats
+// a city zone, which beats the country wide zone.
+func FindShippingZone(zones []model.ShippingZoneResponse, address model.DeliveryAddress) (*model.ShippingZoneResponse, bool) {
+	var best *model.ShippingZoneResponse
+	bestScore := -1
+	for i := range zones {
+		zone := &zones[i]
+		if !strings.EqualFold(zone.Country, address.Country) {
+			continue
+		}
+		if zone.City != nil && !strings.EqualFold(*zone.City, address.City) {
+			continue
+		}
+		if zone.AreaName != nil && !strings.EqualFold(*zone.AreaName, address.AreaName) {
+			continue
+		}
+
+		score := 0
+		if zone.City != nil {
+			score++
+		}
+		if zone.AreaName != nil {
+			score += 2
+		}
+		if score > bestScore {
+			best, bestScore = zone, score
+		}
+	}
+
+	return best, best != nil
+}
+
+// SelectShippingRate picks the cheapest band the order falls into, weight bands are
+// matched on the order weight and price bands on the order value.
+func SelectShippingRate(rates []model.ShippingRateResponse, weight money.Decimal, value money.Money) (*model.ShippingRateResponse, bool) {
+	var cheapest *model.ShippingRateResponse
+	for i := range rates {
+		rate := &rates[i]
+
+		var inBand bool
+		switch rate.RateBasisID {
+		case constant.SHIPPING_RATE_BASIS_WEIGHT:
+			inBand = weight.Cmp(rate.MinValue) >= 0 && (rate.MaxValue == nil || weight.Cmp(*rate.MaxValue) < 0)
+		case constant.SHIPPING_RATE_BASIS_PRICE:
+			inBand = inPriceBand(value, rate.MinValue, rate.MaxValue)
+		}
+		if !inBand {
+			continue
+		}
+
+		if cheapest == nil {
+			cheapest = rate
+			continue
+		}
+		if cmp, err := rate.Price.Compare(cheapest.Price); err == nil && cmp < 0 {
+			cheapest = rate
+		}
+	}
+
+	return cheapest, cheapest != nil
+}
+
+func inPriceBand(value money.Money, minValue money.Decimal, maxValue *money.Decimal) bool {
+	lower, err := minValue.Money(value.Currency(), money.HalfUp)
+	if err != nil {
+		return false
+	}
+	if cmp, err := value.Compare(lower); err != nil || cmp < 0 {
+		return false
+	}
+	if maxValue == nil {
+		return true
+	}
+
+	upper, err := maxValue.Money(value.Currency(), money.HalfUp)
+	if err != nil {
+		return false
+	}
+	cmp, err := value.Compare(upper)
+	return err == nil && cmp < 0
+}
+
+// CheckDesiredTime makes sure the desired delivery time falls inside the slot, the
+// slot start is included and its end is not.
+func CheckDesiredTime(slot model.DeliverySlotResponse, desiredTime time.Time) error {
+	if desiredTime.Before(slot.StartsAt) || !desiredTime.Before(slot.EndsAt) {
+		return types.NewInvalidInputError()
+	}
+	return nil
+}
+
+func (s *ShippingService) validateZoneRequest(ctx context.Context, body string) (*model.ShippingZoneRequest, error) {
+	log := logger.FromContext(ctx, s.logger)
+	var zoneRequest model.ShippingZoneRequest
+	err := s.validator.MarshalAndValidateREQ(body, &zoneRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rate := range zoneRequest.Rates {
+		if rate.MaxValue != nil && rate.MaxValue.Cmp(rate.MinValue) <= 0 {
+			log.Infof("Shipping rate band '%s' to '%s' is empty", rate.MinValue, rate.MaxValue)
+			return nil, types.NewInvalidInputError()
+		}
+		if rate.Price.IsNegative() {
+			log.Infof("Shipping rate price '%s' is negative", rate.Price)
+			return nil, types.NewInvalidInputError()
+		}
+	}
+
+	return &zoneRequest, nil
+}
+
+func (s *ShippingService) AllZones(ctx context.Context) ([]model.ShippingZoneResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.AllZones")
+	defer span.End()
+
+	return s.shippingRepo.GetAllZones(ctx)
+}
+
+func (s *ShippingService) GetZone(ctx context.Context, zoneId uint64) (*model.ShippingZoneResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.GetZone")
+	defer span.End()
+
+	return s.shippingRepo.GetZoneByID(ctx, zoneId)
+}
+
+func (s *ShippingService) CreateZone(ctx context.Context, body string, creatingUserId uint64) (*model.ShippingZoneResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.CreateZone")
+	defer span.End()
+
+	zoneRequest, err := s.validateZoneRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.shippingRepo.CreateZone(ctx, *zoneRequest, creatingUserId)
+}
+
+func (s *ShippingService) UpdateZone(ctx context.Context, zoneId uint64, body string, updatingUserId uint64) (*model.ShippingZoneResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.UpdateZone")
+	defer span.End()
+
+	zoneRequest, err := s.validateZoneRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.shippingRepo.GetZoneByID(ctx, zoneId)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.shippingRepo.UpdateZone(ctx, zoneId, *zoneRequest, updatingUserId)
+}
+
+func (s *ShippingService) DeleteZone(ctx context.Context, zoneId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "ShippingService.DeleteZone")
+	defer span.End()
+
+	_, err := s.shippingRepo.GetZoneByID(ctx, zoneId)
+	if err != nil {
+		return err
+	}
+
+	return s.shippingRepo.DeleteZone(ctx, zoneId, deletingUserId)
+}
+
+func (s *ShippingService) GetSlots(ctx context.Context, zoneId uint64, from time.Time, to time.Time) ([]model.DeliverySlotResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.GetSlots")
+	defer span.End()
+	log := logger.FromContext(ctx, s.logger)
+
+	if !from.Before(to) {
+		log.Infof("Delivery slot range '%s' to '%s' is empty", from, to)
+		return nil, types.NewInvalidInputError()
+	}
+
+	return s.shippingRepo.GetSlots(ctx, zoneId, from, to)
+}
+
+func (s *ShippingService) CreateSlot(ctx context.Context, body string, creatingUserId uint64) (*model.DeliverySlotResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.CreateSlot")
+	defer span.End()
+	log := logger.FromContext(ctx, s.logger)
+
+	var slotRequest model.DeliverySlotRequest
+	err := s.validator.MarshalAndValidateREQ(body, &slotRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !slotRequest.StartsAt.Before(slotRequest.EndsAt) {
+		log.Infof("Delivery slot '%s' to '%s' is empty", slotRequest.StartsAt, slotRequest.EndsAt)
+		return nil, types.NewInvalidInputError()
+	}
+
+	_, err = s.shippingRepo.GetZoneByID(ctx, slotRequest.ZoneID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.shippingRepo.CreateSlot(ctx, slotRequest, creatingUserId)
+}
+
+func (s *ShippingService) DeleteSlot(ctx context.Context, slotId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "ShippingService.DeleteSlot")
+	defer span.End()
+
+	_, err := s.shippingRepo.GetSlotByID(ctx, slotId)
+	if err != nil {
+		return err
+	}
+
+	return s.shippingRepo.DeleteSlot(ctx, slotId, deletingUserId)
+}
+
+func (s *ShippingService) getAccessibleOrder(ctx context.Context, orderId uint64, userId uint64) (*model.OrderResponse, error) {
+	log := logger.FromContext(ctx, s.logger)
+	order, err := s.orderRepo.GetByID(ctx, orderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.CreatedUser != userId {
+		allowed, err := s.userRepo.HasPermission(ctx, userId, constant.MANAGE_SHIPPING_PERMISSION)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			log.Infof("User '%d' denied shipping for order '%d'", userId, orderId)
+			return nil, types.NewForbiddenError()
+		}
+	}
+
+	return order, nil
+}
+
+func (s *ShippingService) orderWeight(ctx context.Context, order *model.OrderResponse) (money.Decimal, error) {
+	log := logger.FromContext(ctx, s.logger)
+	var weight money.Decimal
+	for _, item := range order.Items {
+		if item.ProductID == nil {
+			continue
+		}
+		product, err := s.productRepo.GetByID(ctx, *item.ProductID)
+		if err != nil {
+			return money.Decimal{}, err
+		}
+		lineWeight, err := product.Weight.Mul(int64(item.Quantity))
+		if err == nil {
+			weight, err = weight.Add(lineWeight)
+		}
+		if err != nil {
+			log.Errorf("Unabled to weigh order '%d': %s", order.ID, err.Error())
+			return money.Decimal{}, types.NewInternalServerError()
+		}
+	}
+
+	return weight, nil
+}
+
+func (s *ShippingService) quote(ctx context.Context, order *model.OrderResponse, details *model.DeliveryDetailsResponse) (*model.ShippingQuoteResponse, error) {
+	log := logger.FromContext(ctx, s.logger)
+	address := details.Address()
+	zones, err := s.shippingRepo.GetZonesByCountry(ctx, address.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, ok := FindShippingZone(zones, address)
+	if !ok {
+		log.Infof("No shipping zone covers order '%d' to '%s'", order.ID, address.Country)
+		return nil, types.NewBadRequestError()
+	}
+
+	weight, err := s.orderWeight(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	value, err := order.Subtotal.Sub(order.DiscountTotal)
+	if err != nil {
+		log.Errorf("Unabled to value order '%d': %s", order.ID, err.Error())
+		return nil, types.NewInternalServerError()
+	}
+
+	rate, ok := SelectShippingRate(zone.Rates, weight, value)
+	if !ok {
+		log.Infof("No shipping rate in zone '%d' for weight '%s' and value '%s'", zone.ID, weight, value)
+		return nil, types.NewBadRequestError()
+	}
+
+	return &model.ShippingQuoteResponse{
+		ZoneID: zone.ID,
+		RateID: rate.ID,
+		Weight: weight,
+		Price:  rate.Price,
+	}, nil
+}
+
+func (s *ShippingService) Quote(ctx context.Context, orderId uint64, userId uint64) (*model.ShippingQuoteResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.Quote")
+	defer span.End()
+
+	order, err := s.getAccessibleOrder(ctx, orderId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	details, err := s.orderRepo.GetDeliveryDetails(ctx, order.DeliveryDetailsID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.quote(ctx, order, details)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	slots, err := s.shippingRepo.GetSlots(ctx, quote.ZoneID, now, now.AddDate(0, 0, constant.DELIVERY_SLOT_BOOKING_DAYS))
+	if err != nil {
+		return nil, err
+	}
+
+	quote.Slots = make([]model.DeliverySlotResponse, 0, len(slots))
+	for _, slot := range slots {
+		if slot.Reserved < slot.Capacity || (details.DeliverySlotID != nil && *details.DeliverySlotID == slot.ID) {
+			quote.Slots = append(quote.Slots, slot)
+		}
+	}
+
+	return quote, nil
+}
+
+// ReserveSlot books a delivery slot for the order at checkout and charges the quoted
+// shipping rate.
+func (s *ShippingService) ReserveSlot(ctx context.Context, orderId uint64, body string, updatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.ReserveSlot")
+	defer span.End()
+	log := logger.FromContext(ctx, s.logger)
+
+	var reserveRequest model.ReserveSlotRequest
+	err := s.validator.MarshalAndValidateREQ(body, &reserveRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.getAccessibleOrder(ctx, orderId, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.StatusID != constant.ORDER_STATUS_AWAITING_PAYMENT {
+		log.Infof("Order '%d' with status '%d' is past checkout", orderId, order.StatusID)
+		return nil, types.NewBadRequestError()
+	}
+
+	err = s.uow.Run(ctx, "ReserveDeliverySlot", func(tx *repository.Tx) error {
+		order, err = s.ChargeShipping(ctx, tx, order, &reserveRequest.DeliverySlotID, reserveRequest.DesiredTime, updatingUserId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// ChargeShipping quotes shipping to the order's address and charges the rate in the
+// caller's unit of work, failing with a bad request when no zone or rate covers the
+// order. Given a delivery slot it first checks the slot and desired time and books
+// it, giving up the place held in the order's previous slot, so a failure cannot hold
+// a place for an order that was never moved to it.
+func (s *ShippingService) ChargeShipping(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, deliverySlotId *uint64, desiredTime *time.Time, updatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "ShippingService.ChargeShipping")
+	defer span.End()
+	log := logger.FromContext(ctx, s.logger)
+	shippingRepo := s.shippingRepo.WithTx(tx)
+	orderRepo := s.orderRepo.WithTx(tx)
+
+	details, err := orderRepo.GetDeliveryDetails(ctx, order.DeliveryDetailsID)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := s.quote(ctx, order, details)
+	if err != nil {
+		return nil, err
+	}
+
+	if deliverySlotId != nil {
+		slot, err := shippingRepo.GetSlotByID(ctx, *deliverySlotId)
+		if err != nil {
+			return nil, err
+		}
+		if slot.ZoneID != quote.ZoneID || !slot.StartsAt.After(time.Now()) {
+			log.Infof("Delivery slot '%d' is not bookable for order '%d'", slot.ID, order.ID)
+			return nil, types.NewInvalidInputError()
+		}
+
+		slotTime := slot.StartsAt
+		if desiredTime != nil {
+			slotTime = *desiredTime
+		}
+		err = CheckDesiredTime(*slot, slotTime)
+		if err != nil {
+			log.Infof("Desired time '%s' is outside delivery slot '%d'", slotTime, slot.ID)
+			return nil, err
+		}
+
+		alreadyReserved := details.DeliverySlotID != nil && *details.DeliverySlotID == slot.ID
+		if !alreadyReserved {
+			err = shippingRepo.ReserveSlot(ctx, slot.ID, updatingUserId)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = orderRepo.UpdateDeliverySlot(ctx, details.ID, slot.ID, slotTime, updatingUserId)
+		if err != nil {
+			return nil, err
+		}
+
+		if details.DeliverySlotID != nil && !alreadyReserved {
+			err = shippingRepo.ReleaseSlot(ctx, *details.DeliverySlotID, updatingUserId)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return orderRepo.UpdateShipping(ctx, order.ID, quote.RateID, quote.Price, updatingUserId)
+}
+
+func (s *ShippingService) Shutdown() {
+	s.shippingRepo.Shutdown()
+}
@@ -0,0 +1,80 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/service"
+)
+
+func TestFindShippingZone_withNestedZones_shouldPreferMostSpecific(t *testing.T) {
+	city, area := "Cape Town", "Sea Point"
+	zones := []model.ShippingZoneResponse{
+		{ID: 1, Country: "South Africa"},
+		{ID: 2, Country: "South Africa", City: &city},
+		{ID: 3, Country: "South Africa", City: &city, AreaName: &area},
+	}
+
+	zone, ok := service.FindShippingZone(zones, model.DeliveryAddress{Country: "South Africa", City: "cape town", AreaName: "Sea Point"})
+	if !ok || zone.ID != 3 {
+		t.Errorf("Expected the area zone but got %+v", zone)
+	}
+	zone, ok = service.FindShippingZone(zones, model.DeliveryAddress{Country: "South Africa", City: "Cape Town", AreaName: "Gardens"})
+	if !ok || zone.ID != 2 {
+		t.Errorf("Expected the city zone but got %+v", zone)
+	}
+	zone, ok = service.FindShippingZone(zones, model.DeliveryAddress{Country: "South Africa", City: "Durban"})
+	if !ok || zone.ID != 1 {
+		t.Errorf("Expected the country zone but got %+v", zone)
+	}
+	if _, ok = service.FindShippingZone(zones, model.DeliveryAddress{Country: "Lesotho"}); ok {
+		t.Error("Expected no zone for an uncovered country")
+	}
+}
+
+func TestSelectShippingRate_withWeightAndPriceBands_shouldPickCheapestMatch(t *testing.T) {
+	five := money.NewDecimal(5)
+	fiveHundred := money.NewDecimal(500)
+	rates := []model.ShippingRateResponse{
+		{ID: 1, RateBasisID: constant.SHIPPING_RATE_BASIS_WEIGHT, MinValue: money.NewDecimal(0), MaxValue: &five, Price: zar(9900)},
+		{ID: 2, RateBasisID: constant.SHIPPING_RATE_BASIS_WEIGHT, MinValue: five, Price: zar(19900)},
+		{ID: 3, RateBasisID: constant.SHIPPING_RATE_BASIS_PRICE, MinValue: fiveHundred, Price: zar(0)},
+	}
+
+	selected, ok := service.SelectShippingRate(rates, rate(t, "4.9999"), zar(10000))
+	if !ok || selected.ID != 1 {
+		t.Errorf("Expected the light weight band but got %+v", selected)
+	}
+	selected, ok = service.SelectShippingRate(rates, five, zar(10000))
+	if !ok || selected.ID != 2 {
+		t.Errorf("Expected the heavy weight band at its lower bound but got %+v", selected)
+	}
+	selected, ok = service.SelectShippingRate(rates, five, zar(50000))
+	if !ok || selected.ID != 3 {
+		t.Errorf("Expected free shipping over 500 but got %+v", selected)
+	}
+	if _, ok = service.SelectShippingRate(rates[2:], five, zar(100)); ok {
+		t.Error("Expected no rate below the price band")
+	}
+}
+
+func TestCheckDesiredTime_withSlotBounds_shouldIncludeStartAndExcludeEnd(t *testing.T) {
+	startsAt := time.Date(2026, time.November, 2, 8, 0, 0, 0, time.UTC)
+	slot := model.DeliverySlotResponse{StartsAt: startsAt, EndsAt: startsAt.Add(2 * time.Hour)}
+
+	if err := service.CheckDesiredTime(slot, startsAt); err != nil {
+		t.Errorf("Expected the slot start to be allowed but got %v", err)
+	}
+	if err := service.CheckDesiredTime(slot, startsAt.Add(time.Hour)); err != nil {
+		t.Errorf("Expected a time inside the slot to be allowed but got %v", err)
+	}
+	if err := service.CheckDesiredTime(slot, startsAt.Add(-time.Minute)); err == nil {
+		t.Error("Expected error before the slot starts")
+	}
+	if err := service.CheckDesiredTime(slot, slot.EndsAt); err == nil {
+		t.Error("Expected error at the slot end")
+	}
+}
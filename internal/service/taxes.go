@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Taxes interface {
+	AllTaxRates(ctx context.Context) ([]model.TaxRateResponse, error)
+	GetTaxRate(ctx context.Context, taxRateId uint64) (*model.TaxRateResponse, error)
+	CreateTaxRate(ctx context.Context, body string, creatingUserId uint64) (*model.TaxRateResponse, error)
+	UpdateTaxRate(ctx context.Context, taxRateId uint64, body string, updatingUserId uint64) (*model.TaxRateResponse, error)
+	DeleteTaxRate(ctx context.Context, taxRateId uint64, deletingUserId uint64) error
+	ApplyToOrder(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, discountTotal money.Money, updatingUserId uint64) (*model.OrderResponse, error)
+	Shutdown()
+}
+
+type TaxesService struct {
+	validator   Validator
+	taxRepo     repository.TaxRepository
+	orderRepo   repository.OrderRepository
+	productRepo repository.ProductRepository
+	logger      logger.Logger
+}
+
+func NewTaxesService(validator Validator, taxRepo repository.TaxRepository, orderRepo repository.OrderRepository, productRepo repository.ProductRepository, logger logger.Logger) Taxes {
+	return &TaxesService{
+		validator:   validator,
+		taxRepo:     taxRepo,
+		orderRepo:   orderRepo,
+		productRepo: productRepo,
+		logger:      logger,
+	}
+}
+
+// FindTaxRate picks the most specific rate for the tax class at the address: a rate
+// for the delivery area beats one for the city, which beats the country wide rate.
+func FindTaxRate(rates []model.TaxRateResponse, taxClassId uint64, address model.DeliveryAddress) (*model.TaxRateResponse, bool) {
+	var countryRate, cityRate, areaRate *model.TaxRateResponse
+	for i := range rates {
+		rate := &rates[i]
+		if rate.TaxClassID != taxClassId || !strings.EqualFold(rate.Country, address.Country) {
+			continue
+		}
+		switch {
+		case rate.Region == nil:
+			countryRate = rate
+		case address.AreaName != "" && strings.EqualFold(*rate.Region, address.AreaName):
+			areaRate = rate
+		case address.City != "" && strings.EqualFold(*rate.Region, address.City):
+			cityRate = rate
+		}
+	}
+
+	for _, rate := range []*model.TaxRateResponse{areaRate, cityRate, countryRate} {
+		if rate != nil {
+			return rate, true
+		}
+	}
+	return nil, false
+}
+
+// CalculateOrderTax spreads the order discount over the items in proportion to their
+// line totals and taxes what is left of each line. Items without a matching rate are
+// not taxed.
+func CalculateOrderTax(items []model.TaxableItem, discountTotal money.Money, rates []model.TaxRateResponse, address model.DeliveryAddress) (*model.OrderTax, error) {
+	lineTotals := make([]money.Money, len(items))
+	weights := make([]int64, len(items))
+	for i, item := range items {
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		lineTotals[i] = lineTotal
+		weights[i] = lineTotal.Minor()
+	}
+
+	discounts, err := discountTotal.Allocate(weights...)
+	if err != nil {
+		return nil, types.NewInvalidInputError()
+	}
+
+	orderTax := model.OrderTax{
+		Items:             make([]model.ItemTax, 0, len(items)),
+		TaxTotal:          money.Zero(discountTotal.Currency()),
+		ExclusiveTaxTotal: money.Zero(discountTotal.Currency()),
+	}
+	for i, item := range items {
+		itemTax := model.ItemTax{
+			OrderItemID:    item.OrderItemID,
+			DiscountAmount: discounts[i],
+			TaxClassID:     item.TaxClassID,
+			TaxAmount:      money.Zero(lineTotals[i].Currency()),
+		}
+
+		rate, ok := FindTaxRate(rates, item.TaxClassID, address)
+		if ok {
+			taxable, err := lineTotals[i].Sub(discounts[i])
+			if err != nil {
+				return nil, types.NewInvalidInputError()
+			}
+
+			itemTax.TaxRate = rate.Rate
+			itemTax.PricesIncludeTax = rate.PricesIncludeTax
+			if rate.PricesIncludeTax {
+				itemTax.TaxAmount, err = taxable.IncludedPercent(rate.Rate, money.HalfUp)
+			} else {
+				itemTax.TaxAmount, err = taxable.Percent(rate.Rate, money.HalfUp)
+			}
+			if err != nil {
+				return nil, types.NewInvalidInputError()
+			}
+		}
+
+		orderTax.TaxTotal, err = orderTax.TaxTotal.Add(itemTax.TaxAmount)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		if !itemTax.PricesIncludeTax {
+			orderTax.ExclusiveTaxTotal, err = orderTax.ExclusiveTaxTotal.Add(itemTax.TaxAmount)
+			if err != nil {
+				return nil, types.NewInvalidInputError()
+			}
+		}
+		orderTax.Items = append(orderTax.Items, itemTax)
+	}
+
+	return &orderTax, nil
+}
+
+func (t *TaxesService) validateTaxRateRequest(ctx context.Context, body string) (*model.TaxRateRequest, error) {
+	log := logger.FromContext(ctx, t.logger)
+	var taxRateRequest model.TaxRateRequest
+	err := t.validator.MarshalAndValidateREQ(body, &taxRateRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if taxRateRequest.Rate.Cmp(money.NewDecimal(0)) < 0 || taxRateRequest.Rate.Cmp(money.NewDecimal(100)) > 0 {
+		log.Infof("Tax rate of '%s' is out of range", taxRateRequest.Rate)
+		return nil, types.NewInvalidInputError()
+	}
+
+	return &taxRateRequest, nil
+}
+
+func (t *TaxesService) AllTaxRates(ctx context.Context) ([]model.TaxRateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaxesService.AllTaxRates")
+	defer span.End()
+
+	return t.taxRepo.GetAll(ctx)
+}
+
+func (t *TaxesService) GetTaxRate(ctx context.Context, taxRateId uint64) (*model.TaxRateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaxesService.GetTaxRate")
+	defer span.End()
+
+	return t.taxRepo.GetByID(ctx, taxRateId)
+}
+
+func (t *TaxesService) CreateTaxRate(ctx context.Context, body string, creatingUserId uint64) (*model.TaxRateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaxesService.CreateTaxRate")
+	defer span.End()
+
+	taxRateRequest, err := t.validateTaxRateRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.taxRepo.Create(ctx, *taxRateRequest, creatingUserId)
+}
+
+func (t *TaxesService) UpdateTaxRate(ctx context.Context, taxRateId uint64, body string, updatingUserId uint64) (*model.TaxRateResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaxesService.UpdateTaxRate")
+	defer span.End()
+
+	taxRateRequest, err := t.validateTaxRateRequest(ctx, body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = t.taxRepo.GetByID(ctx, taxRateId)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.taxRepo.Update(ctx, taxRateId, *taxRateRequest, updatingUserId)
+}
+
+func (t *TaxesService) DeleteTaxRate(ctx context.Context, taxRateId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "TaxesService.DeleteTaxRate")
+	defer span.End()
+
+	_, err := t.taxRepo.GetByID(ctx, taxRateId)
+	if err != nil {
+		return err
+	}
+
+	return t.taxRepo.Delete(ctx, taxRateId, deletingUserId)
+}
+
+// ApplyToOrder taxes the order at checkout once its discounts are known, storing the
+// tax on every order item and the totals on the order in the caller's unit of work.
+func (t *TaxesService) ApplyToOrder(ctx context.Context, tx *repository.Tx, order *model.OrderResponse, discountTotal money.Money, updatingUserId uint64) (*model.OrderResponse, error) {
+	ctx, span := tracing.Start(ctx, "TaxesService.ApplyToOrder")
+	defer span.End()
+	log := logger.FromContext(ctx, t.logger)
+	orderRepo := t.orderRepo.WithTx(tx)
+
+	details, err := orderRepo.GetDeliveryDetails(ctx, order.DeliveryDetailsID)
+	if err != nil {
+		return nil, err
+	}
+	address := details.Address()
+
+	rates, err := t.taxRepo.GetByCountry(ctx, address.Country)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]model.TaxableItem, 0, len(order.Items))
+	for _, item := range order.Items {
+		// the class snapshotted at checkout wins so a later product edit cannot
+		// retax the order; only items placed before the snapshot read the product
+		taxClassId := uint64(constant.TAX_CLASS_STANDARD)
+		if item.TaxClassID != nil {
+			taxClassId = *item.TaxClassID
+		} else if item.ProductID != nil {
+			product, err := t.productRepo.GetByID(ctx, *item.ProductID)
+			if err != nil {
+				return nil, err
+			}
+			taxClassId = product.TaxClassID
+		}
+		items = append(items, model.TaxableItem{
+			OrderItemID: item.ID,
+			TaxClassID:  taxClassId,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	orderTax, err := CalculateOrderTax(items, discountTotal, rates, address)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal := money.Zero(discountTotal.Currency())
+	for _, item := range items {
+		lineTotal, err := item.Price.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+		subtotal, err = subtotal.Add(lineTotal)
+		if err != nil {
+			return nil, types.NewInvalidInputError()
+		}
+	}
+
+	total, err := subtotal.Sub(discountTotal)
+	if err == nil {
+		total, err = total.Add(orderTax.ExclusiveTaxTotal)
+	}
+	if err == nil {
+		total, err = total.Add(order.ShippingTotal)
+	}
+	if err != nil {
+		log.Errorf("Unabled to total order '%d': %s", order.ID, err.Error())
+		return nil, types.NewInternalServerError()
+	}
+
+	for _, itemTax := range orderTax.Items {
+		err = orderRepo.UpdateItemTax(ctx, itemTax, updatingUserId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return orderRepo.UpdateTotals(ctx, order.ID, subtotal, discountTotal, orderTax.TaxTotal, total, updatingUserId)
+}
+
+func (t *TaxesService) Shutdown() {
+	t.taxRepo.Shutdown()
+}
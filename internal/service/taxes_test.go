@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/service"
+)
+
+func rate(t *testing.T, value string) money.Decimal {
+	decimal, err := money.ParseDecimal(value)
+	if err != nil {
+		t.Fatalf("Expected no error parsing rate but got %v", err)
+	}
+	return decimal
+}
+
+func testTaxableItems() []model.TaxableItem {
+	return []model.TaxableItem{
+		{OrderItemID: 1, TaxClassID: constant.TAX_CLASS_STANDARD, Price: zar(11500), Quantity: 1},
+		{OrderItemID: 2, TaxClassID: constant.TAX_CLASS_ZERO_RATED, Price: zar(5000), Quantity: 2},
+	}
+}
+
+func TestFindTaxRate_withRegionalRates_shouldPreferMostSpecific(t *testing.T) {
+	city, area := "Cape Town", "Sea Point"
+	rates := []model.TaxRateResponse{
+		{ID: 1, Country: "South Africa", TaxClassID: constant.TAX_CLASS_STANDARD, Rate: rate(t, "15")},
+		{ID: 2, Country: "South Africa", Region: &city, TaxClassID: constant.TAX_CLASS_STANDARD, Rate: rate(t, "16")},
+		{ID: 3, Country: "South Africa", Region: &area, TaxClassID: constant.TAX_CLASS_STANDARD, Rate: rate(t, "17")},
+	}
+
+	found, ok := service.FindTaxRate(rates, constant.TAX_CLASS_STANDARD, model.DeliveryAddress{Country: "south africa", City: "Cape Town", AreaName: "Sea Point"})
+	if !ok || found.ID != 3 {
+		t.Errorf("Expected the area rate but got %+v", found)
+	}
+	found, ok = service.FindTaxRate(rates, constant.TAX_CLASS_STANDARD, model.DeliveryAddress{Country: "South Africa", City: "Cape Town"})
+	if !ok || found.ID != 2 {
+		t.Errorf("Expected the city rate but got %+v", found)
+	}
+	found, ok = service.FindTaxRate(rates, constant.TAX_CLASS_STANDARD, model.DeliveryAddress{Country: "South Africa", City: "Durban"})
+	if !ok || found.ID != 1 {
+		t.Errorf("Expected the country rate but got %+v", found)
+	}
+	if _, ok = service.FindTaxRate(rates, constant.TAX_CLASS_STANDARD, model.DeliveryAddress{Country: "Namibia"}); ok {
+		t.Error("Expected no rate for a country without rates")
+	}
+}
+
+func TestCalculateOrderTax_withInclusivePricing_shouldExtractTaxFromPrice(t *testing.T) {
+	rates := []model.TaxRateResponse{
+		{Country: "South Africa", TaxClassID: constant.TAX_CLASS_STANDARD, Rate: rate(t, "15"), PricesIncludeTax: true},
+		{Country: "South Africa", TaxClassID: constant.TAX_CLASS_ZERO_RATED, Rate: rate(t, "0"), PricesIncludeTax: true},
+	}
+
+	orderTax, err := service.CalculateOrderTax(testTaxableItems(), zar(0), rates, model.DeliveryAddress{Country: "South Africa"})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if orderTax.Items[0].TaxAmount.Minor() != 1500 || !orderTax.Items[1].TaxAmount.IsZero() {
+		t.Errorf("Expected 15.00 and 0 tax but got %+v", orderTax.Items)
+	}
+	if orderTax.TaxTotal.Minor() != 1500 || !orderTax.ExclusiveTaxTotal.IsZero() {
+		t.Errorf("Expected inclusive tax total of 15.00 but got %+v", orderTax)
+	}
+}
+
+func TestCalculateOrderTax_withExclusivePricingAndDiscount_shouldTaxDiscountedLines(t *testing.T) {
+	rates := []model.TaxRateResponse{
+		{Country: "United Kingdom", TaxClassID: constant.TAX_CLASS_STANDARD, Rate: rate(t, "20")},
+		{Country: "United Kingdom", TaxClassID: constant.TAX_CLASS_ZERO_RATED, Rate: rate(t, "0")},
+	}
+
+	orderTax, err := service.CalculateOrderTax(testTaxableItems(), zar(4300), rates, model.DeliveryAddress{Country: "United Kingdom"})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if orderTax.Items[0].DiscountAmount.Minor() != 2300 || orderTax.Items[1].DiscountAmount.Minor() != 2000 {
+		t.Errorf("Expected discount split of 23.00 and 20.00 but got %+v", orderTax.Items)
+	}
+	if orderTax.Items[0].TaxAmount.Minor() != 1840 {
+		t.Errorf("Expected 18.40 tax on the discounted line but got %s", orderTax.Items[0].TaxAmount)
+	}
+	if orderTax.ExclusiveTaxTotal.Minor() != 1840 {
+		t.Errorf("Expected exclusive tax total of 18.40 but got %s", orderTax.ExclusiveTaxTotal)
+	}
+}
+
+func TestCalculateOrderTax_withoutMatchingRate_shouldNotTax(t *testing.T) {
+	orderTax, err := service.CalculateOrderTax(testTaxableItems(), zar(0), nil, model.DeliveryAddress{Country: "Botswana"})
+	if err != nil {
+		t.Fatalf("Expected no error but got %v", err)
+	}
+	if !orderTax.TaxTotal.IsZero() {
+		t.Errorf("Expected no tax but got %s", orderTax.TaxTotal)
+	}
+}
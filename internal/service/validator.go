@@ -2,6 +2,7 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/go-playground/validator/v10"
 	"tannar.moss/backend/internal/logger"
@@ -11,6 +12,8 @@ import (
 
 type Validator interface {
 	MarshalAndValidateREQ(body string, request any) error
+	ValidateREQ(request any) error
+	InvalidFields(request any) []string
 }
 
 type SimpleValidator struct {
@@ -40,3 +43,37 @@ func (validator *SimpleValidator) MarshalAndValidateREQ(body string, request any
 
 	return nil
 }
+
+// ValidateREQ validates a request that was built from something other than a JSON
+// body, such as query parameters.
+func (validator *SimpleValidator) ValidateREQ(request any) error {
+	err := validator.Validate.Struct(request)
+	if err != nil {
+		validator.Logger.Infof("Error validating: %s", err.Error())
+		return types.NewInvalidInputError()
+	}
+
+	return nil
+}
+
+// InvalidFields lists each field of request that fails validation with the rule it
+// broke, e.g. "Title (required)", for reports that need more than a pass or fail.
+func (validator *SimpleValidator) InvalidFields(request any) []string {
+	return describeValidationError(validator.Validate.Struct(request))
+}
+
+func describeValidationError(err error) []string {
+	if err == nil {
+		return nil
+	}
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []string{err.Error()}
+	}
+
+	fields := make([]string, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		fields = append(fields, fmt.Sprintf("%s (%s)", fieldError.Namespace(), fieldError.Tag()))
+	}
+	return fields
+}
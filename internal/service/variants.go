@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/money"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Variants interface {
+	AllAttributes(ctx context.Context) ([]model.AttributeResponse, error)
+	CreateAttribute(ctx context.Context, body string, creatingUserId uint64) (*model.AttributeResponse, error)
+	UpdateAttribute(ctx context.Context, attributeId uint64, body string, updatingUserId uint64) (*model.AttributeResponse, error)
+	DeleteAttribute(ctx context.Context, attributeId uint64, deletingUserId uint64) error
+	GetVariants(ctx context.Context, productId uint64) ([]model.VariantResponse, error)
+	CreateVariant(ctx context.Context, productId uint64, body string, creatingUserId uint64) (*model.VariantResponse, error)
+	UpdateVariant(ctx context.Context, variantId uint64, body string, updatingUserId uint64) (*model.VariantResponse, error)
+	DeleteVariant(ctx context.Context, variantId uint64, deletingUserId uint64) error
+	Shutdown()
+}
+
+type VariantsService struct {
+	validator     Validator
+	attributeRepo repository.AttributeRepository
+	productRepo   repository.ProductRepository
+	logger        logger.Logger
+}
+
+func NewVariantsService(validator Validator, attributeRepo repository.AttributeRepository, productRepo repository.ProductRepository, logger logger.Logger) Variants {
+	return &VariantsService{
+		validator:     validator,
+		attributeRepo: attributeRepo,
+		productRepo:   productRepo,
+		logger:        logger,
+	}
+}
+
+// ResolveVariantAttributes looks up the attribute values a variant is made of. It
+// reports false when a value does not exist or two values share an attribute.
+func ResolveVariantAttributes(attributes []model.AttributeResponse, valueIds []uint64) ([]model.VariantAttribute, bool) {
+	resolved := make([]model.VariantAttribute, 0, len(valueIds))
+	used := make(map[uint64]bool, len(valueIds))
+	for _, valueId := range valueIds {
+		found := false
+		for _, attribute := range attributes {
+			for _, value := range attribute.Values {
+				if value.ID != valueId {
+					continue
+				}
+				if used[attribute.ID] {
+					return nil, false
+				}
+				used[attribute.ID] = true
+				found = true
+				resolved = append(resolved, model.VariantAttribute{
+					AttributeValueID: value.ID,
+					Name:             attribute.Name,
+					Value:            value.Value,
+				})
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	return resolved, true
+}
+
+// combinationKey identifies a set of attribute values regardless of their order.
+func combinationKey(valueIds []uint64) string {
+	sorted := append([]uint64(nil), valueIds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return fmt.Sprint(sorted)
+}
+
+// SelectVariant picks the variant being bought and the price it sells at. A product
+// with variants cannot be bought without choosing one, and a variant without its own
+// price sells at the product price.
+func SelectVariant(product model.ProductResponse, variants []model.VariantResponse, variantId *uint64) (*model.VariantResponse, money.Money, error) {
+	if variantId == nil {
+		if len(variants) > 0 {
+			return nil, money.Money{}, types.NewInvalidInputError()
+		}
+		return nil, product.Price, nil
+	}
+
+	for i := range variants {
+		if variants[i].ID != *variantId {
+			continue
+		}
+		if variants[i].ProductID != product.ID {
+			break
+		}
+		if variants[i].Price != nil {
+			return &variants[i], *variants[i].Price, nil
+		}
+		return &variants[i], product.Price, nil
+	}
+
+	return nil, money.Money{}, types.NewInvalidInputError()
+}
+
+func (v *VariantsService) AllAttributes(ctx context.Context) ([]model.AttributeResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.AllAttributes")
+	defer span.End()
+
+	return v.attributeRepo.GetAll(ctx)
+}
+
+func (v *VariantsService) CreateAttribute(ctx context.Context, body string, creatingUserId uint64) (*model.AttributeResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.CreateAttribute")
+	defer span.End()
+
+	var attributeRequest model.AttributeRequest
+	err := v.validator.MarshalAndValidateREQ(body, &attributeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.attributeRepo.Create(ctx, attributeRequest, creatingUserId)
+}
+
+func (v *VariantsService) UpdateAttribute(ctx context.Context, attributeId uint64, body string, updatingUserId uint64) (*model.AttributeResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.UpdateAttribute")
+	defer span.End()
+
+	var attributeRequest model.AttributeRequest
+	err := v.validator.MarshalAndValidateREQ(body, &attributeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.attributeRepo.Update(ctx, attributeId, attributeRequest, updatingUserId)
+}
+
+func (v *VariantsService) DeleteAttribute(ctx context.Context, attributeId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "VariantsService.DeleteAttribute")
+	defer span.End()
+	log := logger.FromContext(ctx, v.logger)
+
+	_, err := v.attributeRepo.GetByID(ctx, attributeId)
+	if err != nil {
+		return err
+	}
+
+	inUse, err := v.attributeRepo.InUse(ctx, attributeId)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		log.Infof("Attribute '%d' is still used by a variant", attributeId)
+		return types.NewBadRequestError()
+	}
+
+	return v.attributeRepo.Delete(ctx, attributeId, deletingUserId)
+}
+
+func (v *VariantsService) GetVariants(ctx context.Context, productId uint64) ([]model.VariantResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.GetVariants")
+	defer span.End()
+
+	_, err := v.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.productRepo.GetVariants(ctx, productId)
+}
+
+func (v *VariantsService) validateVariantRequest(ctx context.Context, body string, productId uint64, variantId uint64) (*model.VariantRequest, error) {
+	log := logger.FromContext(ctx, v.logger)
+	var variantRequest model.VariantRequest
+	err := v.validator.MarshalAndValidateREQ(body, &variantRequest)
+	if err != nil {
+		return nil, err
+	}
+	if variantRequest.Price != nil && variantRequest.Price.IsNegative() {
+		log.Infof("Variant price '%s' is negative", variantRequest.Price)
+		return nil, types.NewInvalidInputError()
+	}
+
+	attributes, err := v.attributeRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := ResolveVariantAttributes(attributes, variantRequest.AttributeValueIDs); !ok {
+		log.Infof("Attribute values '%v' do not make up a variant", variantRequest.AttributeValueIDs)
+		return nil, types.NewInvalidInputError()
+	}
+
+	existing, err := v.productRepo.GetVariantBySKU(ctx, variantRequest.SKU)
+	if err != nil {
+		if socketErr, ok := err.(*types.SocketError); !ok || socketErr.StatusCode() != constant.NotFoundCode {
+			return nil, err
+		}
+	} else if existing.ID != variantId {
+		log.Infof("SKU '%s' is already used by variant '%d'", variantRequest.SKU, existing.ID)
+		return nil, types.NewBadRequestError()
+	}
+
+	variants, err := v.productRepo.GetVariants(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	key := combinationKey(variantRequest.AttributeValueIDs)
+	for _, variant := range variants {
+		if variant.ID == variantId {
+			continue
+		}
+		valueIds := make([]uint64, 0, len(variant.Attributes))
+		for _, attribute := range variant.Attributes {
+			valueIds = append(valueIds, attribute.AttributeValueID)
+		}
+		if combinationKey(valueIds) == key {
+			log.Infof("Product '%d' already has variant '%d' with attribute values '%v'", productId, variant.ID, variantRequest.AttributeValueIDs)
+			return nil, types.NewBadRequestError()
+		}
+	}
+
+	return &variantRequest, nil
+}
+
+func (v *VariantsService) CreateVariant(ctx context.Context, productId uint64, body string, creatingUserId uint64) (*model.VariantResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.CreateVariant")
+	defer span.End()
+
+	_, err := v.productRepo.GetByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	variantRequest, err := v.validateVariantRequest(ctx, body, productId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.productRepo.CreateVariant(ctx, productId, *variantRequest, creatingUserId)
+}
+
+func (v *VariantsService) UpdateVariant(ctx context.Context, variantId uint64, body string, updatingUserId uint64) (*model.VariantResponse, error) {
+	ctx, span := tracing.Start(ctx, "VariantsService.UpdateVariant")
+	defer span.End()
+
+	variant, err := v.productRepo.GetVariantByID(ctx, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	variantRequest, err := v.validateVariantRequest(ctx, body, variant.ProductID, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.productRepo.UpdateVariant(ctx, variantId, *variantRequest, updatingUserId)
+}
+
+func (v *VariantsService) DeleteVariant(ctx context.Context, variantId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "VariantsService.DeleteVariant")
+	defer span.End()
+
+	_, err := v.productRepo.GetVariantByID(ctx, variantId)
+	if err != nil {
+		return err
+	}
+
+	return v.productRepo.DeleteVariant(ctx, variantId, deletingUserId)
+}
+
+func (v *VariantsService) Shutdown() {
+	v.attributeRepo.Shutdown()
+}
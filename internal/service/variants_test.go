@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func testAttributes() []model.AttributeResponse {
+	return []model.AttributeResponse{
+		{ID: 1, Name: "Size", Values: []model.AttributeValueResponse{
+			{ID: 1, AttributeID: 1, Value: "Small"},
+			{ID: 2, AttributeID: 1, Value: "Medium"},
+		}},
+		{ID: 2, Name: "Colour", Values: []model.AttributeValueResponse{
+			{ID: 4, AttributeID: 2, Value: "Black"},
+		}},
+	}
+}
+
+func TestResolveVariantAttributes_withOneValuePerAttribute_shouldNameEachValue(t *testing.T) {
+	resolved, ok := service.ResolveVariantAttributes(testAttributes(), []uint64{4, 2})
+	if !ok {
+		t.Fatal("Expected attribute values to resolve")
+	}
+	if len(resolved) != 2 || resolved[0].Name != "Colour" || resolved[0].Value != "Black" || resolved[1].Name != "Size" || resolved[1].Value != "Medium" {
+		t.Errorf("Expected Colour: Black and Size: Medium but got '%+v'", resolved)
+	}
+}
+
+func TestResolveVariantAttributes_withInvalidValues_shouldRefuse(t *testing.T) {
+	cases := map[string][]uint64{
+		"unknown value":  {1, 99},
+		"same attribute": {1, 2},
+	}
+	for name, valueIds := range cases {
+		if _, ok := service.ResolveVariantAttributes(testAttributes(), valueIds); ok {
+			t.Errorf("Expected %s '%v' to be refused", name, valueIds)
+		}
+	}
+}
+
+func TestSelectVariant_withPriceOverride_shouldUseVariantPrice(t *testing.T) {
+	product := model.ProductResponse{ID: 7, Price: zar(10000)}
+	override := zar(12500)
+	variants := []model.VariantResponse{
+		{ID: 1, ProductID: 7},
+		{ID: 2, ProductID: 7, Price: &override},
+	}
+
+	variant, price, err := service.SelectVariant(product, variants, parent(2))
+	if err != nil {
+		t.Fatalf("Expected no error but got '%s'", err)
+	}
+	if variant.ID != 2 || price.Minor() != 12500 {
+		t.Errorf("Expected variant 2 at 12500 but got variant '%d' at '%d'", variant.ID, price.Minor())
+	}
+
+	variant, price, err = service.SelectVariant(product, variants, parent(1))
+	if err != nil {
+		t.Fatalf("Expected no error but got '%s'", err)
+	}
+	if variant.ID != 1 || price.Minor() != 10000 {
+		t.Errorf("Expected variant 1 at the product price but got variant '%d' at '%d'", variant.ID, price.Minor())
+	}
+}
+
+func TestSelectVariant_withMissingOrForeignVariant_shouldRefuse(t *testing.T) {
+	product := model.ProductResponse{ID: 7, Price: zar(10000)}
+	variants := []model.VariantResponse{{ID: 1, ProductID: 7}, {ID: 3, ProductID: 8}}
+
+	if _, _, err := service.SelectVariant(product, variants, nil); err == nil {
+		t.Error("Expected a product with variants to require a selection")
+	}
+	if _, _, err := service.SelectVariant(product, variants, parent(3)); err == nil {
+		t.Error("Expected a variant of another product to be refused")
+	}
+
+	variant, price, err := service.SelectVariant(product, nil, nil)
+	if err != nil || variant != nil || price.Minor() != 10000 {
+		t.Errorf("Expected a product without variants to sell at its own price but got '%v', '%d', '%v'", variant, price.Minor(), err)
+	}
+}
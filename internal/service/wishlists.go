@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/repository"
+	"tannar.moss/backend/internal/tracing"
+	"tannar.moss/backend/internal/types"
+)
+
+type Wishlists interface {
+	GetWishlist(ctx context.Context, userId uint64) ([]model.WishlistItemResponse, error)
+	AddWishlistItem(ctx context.Context, body string, creatingUserId uint64) (*model.WishlistItemResponse, error)
+	RemoveWishlistItem(ctx context.Context, itemId uint64, deletingUserId uint64) error
+	MoveWishlistItemToCart(ctx context.Context, itemId uint64, body string, updatingUserId uint64) (*model.QuoteItemRequest, error)
+	Shutdown()
+}
+
+type WishlistsService struct {
+	validator    Validator
+	wishlistRepo repository.WishlistRepository
+	productRepo  repository.ProductRepository
+	logger       logger.Logger
+}
+
+func NewWishlistsService(validator Validator, wishlistRepo repository.WishlistRepository, productRepo repository.ProductRepository, logger logger.Logger) Wishlists {
+	return &WishlistsService{
+		validator:    validator,
+		wishlistRepo: wishlistRepo,
+		productRepo:  productRepo,
+		logger:       logger,
+	}
+}
+
+// FlagWishlistItem compares the current price and stock of a wishlist item with
+// what they were when it was saved.
+func FlagWishlistItem(item model.WishlistItemResponse) model.WishlistItemResponse {
+	cmp, err := item.Price.Compare(item.SavedPrice)
+	item.PriceDropped = err == nil && cmp < 0
+	item.BackInStock = item.SavedStock == 0 && item.Stock > 0
+
+	return item
+}
+
+func (w *WishlistsService) getOwnItem(ctx context.Context, itemId uint64, userId uint64) (*model.WishlistItemResponse, error) {
+	log := logger.FromContext(ctx, w.logger)
+	item, err := w.wishlistRepo.GetByID(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.CreatedUser != userId {
+		log.Infof("User '%d' denied access to wishlist item '%d'", userId, itemId)
+		return nil, types.NewForbiddenError()
+	}
+
+	return item, nil
+}
+
+func (w *WishlistsService) GetWishlist(ctx context.Context, userId uint64) ([]model.WishlistItemResponse, error) {
+	ctx, span := tracing.Start(ctx, "WishlistsService.GetWishlist")
+	defer span.End()
+
+	items, err := w.wishlistRepo.GetByUserID(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i] = FlagWishlistItem(items[i])
+	}
+
+	return items, nil
+}
+
+func (w *WishlistsService) AddWishlistItem(ctx context.Context, body string, creatingUserId uint64) (*model.WishlistItemResponse, error) {
+	ctx, span := tracing.Start(ctx, "WishlistsService.AddWishlistItem")
+	defer span.End()
+	log := logger.FromContext(ctx, w.logger)
+
+	var itemRequest model.WishlistItemRequest
+	err := w.validator.MarshalAndValidateREQ(body, &itemRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := w.productRepo.GetByID(ctx, itemRequest.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := w.productRepo.GetVariants(ctx, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	variant, price, err := SelectVariant(*product, variants, itemRequest.VariantID)
+	if err != nil {
+		log.Infof("Variant '%v' cannot be saved for product '%d'", itemRequest.VariantID, product.ID)
+		return nil, err
+	}
+
+	stock := product.Stock
+	if variant != nil {
+		stock = variant.Stock
+	}
+
+	item, err := w.wishlistRepo.Add(ctx, itemRequest, price, stock, creatingUserId)
+	if err != nil {
+		return nil, err
+	}
+	flagged := FlagWishlistItem(*item)
+
+	return &flagged, nil
+}
+
+func (w *WishlistsService) RemoveWishlistItem(ctx context.Context, itemId uint64, deletingUserId uint64) error {
+	ctx, span := tracing.Start(ctx, "WishlistsService.RemoveWishlistItem")
+	defer span.End()
+
+	_, err := w.getOwnItem(ctx, itemId, deletingUserId)
+	if err != nil {
+		return err
+	}
+
+	return w.wishlistRepo.Remove(ctx, itemId, deletingUserId)
+}
+
+// MoveWishlistItemToCart takes the item off the wishlist and hands it back as a cart
+// line, ready to be sent with the rest of the cart to the quote endpoint.
+func (w *WishlistsService) MoveWishlistItemToCart(ctx context.Context, itemId uint64, body string, updatingUserId uint64) (*model.QuoteItemRequest, error) {
+	ctx, span := tracing.Start(ctx, "WishlistsService.MoveWishlistItemToCart")
+	defer span.End()
+	log := logger.FromContext(ctx, w.logger)
+
+	var moveRequest model.MoveWishlistItemRequest
+	err := w.validator.MarshalAndValidateREQ(body, &moveRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := w.getOwnItem(ctx, itemId, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+	if item.Stock < moveRequest.Quantity {
+		log.Infof("Wishlist item '%d' has '%d' in stock, '%d' requested", itemId, item.Stock, moveRequest.Quantity)
+		return nil, types.NewBadRequestError()
+	}
+
+	err = w.wishlistRepo.Remove(ctx, itemId, updatingUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.QuoteItemRequest{
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  moveRequest.Quantity,
+	}, nil
+}
+
+func (w *WishlistsService) Shutdown() {
+	w.wishlistRepo.Shutdown()
+}
@@ -0,0 +1,35 @@
+package service_test
+
+import (
+	"testing"
+
+	"tannar.moss/backend/internal/model"
+	"tannar.moss/backend/internal/service"
+)
+
+func TestFlagWishlistItem_withCheaperRestockedItem_shouldFlagBoth(t *testing.T) {
+	item := service.FlagWishlistItem(model.WishlistItemResponse{
+		SavedPrice: zar(19999),
+		SavedStock: 0,
+		Price:      zar(14999),
+		Stock:      3,
+	})
+	if !item.PriceDropped || !item.BackInStock {
+		t.Errorf("Expected price drop and back in stock but got '%+v'", item)
+	}
+}
+
+func TestFlagWishlistItem_withUnchangedOrWorseItem_shouldNotFlag(t *testing.T) {
+	cases := map[string]model.WishlistItemResponse{
+		"same price":     {SavedPrice: zar(5000), SavedStock: 2, Price: zar(5000), Stock: 2},
+		"price rise":     {SavedPrice: zar(5000), SavedStock: 2, Price: zar(5500), Stock: 1},
+		"still sold out": {SavedPrice: zar(5000), SavedStock: 0, Price: zar(5000), Stock: 0},
+		"always stocked": {SavedPrice: zar(5000), SavedStock: 4, Price: zar(5000), Stock: 9},
+	}
+	for name, c := range cases {
+		item := service.FlagWishlistItem(c)
+		if item.PriceDropped || item.BackInStock {
+			t.Errorf("Expected %s item not to be flagged but got '%+v'", name, item)
+		}
+	}
+}
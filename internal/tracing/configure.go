@@ -0,0 +1,47 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/utils"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+
+	exportInterval = 5 * time.Second
+)
+
+// Configure points the Default tracer at the exporter named by OTEL_TRACES_EXPORTER:
+// otlp, sending to OTEL_EXPORTER_OTLP_ENDPOINT (http://localhost:4318 by default),
+// stdout, or none. Call it before serving, and Shutdown on the way out.
+func Configure(serviceName string, logger logger.Logger) {
+	var exporter Exporter
+	switch name := utils.Getenv("OTEL_TRACES_EXPORTER", ExporterNone); name {
+	case ExporterOTLP:
+		exporter = NewOTLPExporter(utils.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"), serviceName)
+	case ExporterStdout:
+		exporter = NewStdoutExporter(os.Stdout)
+	case ExporterNone:
+	default:
+		logger.Errorf("Unknown OTEL_TRACES_EXPORTER '%s', spans will not be exported", name)
+	}
+
+	Default.processor = NewBatchProcessor(exporter, exportInterval, logger)
+}
+
+// Flush exports the spans the Default tracer has queued, for a lambda to call before
+// its invocation is frozen.
+func Flush(ctx context.Context) error {
+	return Default.processor.ForceFlush(ctx)
+}
+
+// Shutdown flushes and stops the Default tracer's exporter.
+func Shutdown(ctx context.Context) error {
+	return Default.processor.Shutdown(ctx)
+}
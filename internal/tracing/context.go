@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// TraceParentHeader is the W3C Trace Context header carrying the caller's span.
+const TraceParentHeader = "traceparent"
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+	// Remote is set for a span context parsed from an inbound traceparent.
+	Remote bool
+}
+
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// TraceParent formats sc as a version 00 traceparent header value.
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceParent reads a traceparent header value. Versions above 00 are read by
+// their first four fields, as the W3C Trace Context spec asks.
+func ParseTraceParent(value string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return SpanContext{}, fmt.Errorf("traceparent '%s' has %d fields, expected 4", value, len(parts))
+	}
+	version, traceId, spanId, flags := parts[0], parts[1], parts[2], parts[3]
+	if len(version) != 2 || version == "ff" || (version == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("traceparent '%s' has an unsupported version", value)
+	}
+	if !isLowerHex(version) || !isLowerHex(traceId) || !isLowerHex(spanId) || !isLowerHex(flags) || len(flags) != 2 {
+		return SpanContext{}, fmt.Errorf("traceparent '%s' is not lowercase hex", value)
+	}
+
+	var sc SpanContext
+	if len(traceId) != 32 || len(spanId) != 16 {
+		return SpanContext{}, fmt.Errorf("traceparent '%s' has ids of the wrong length", value)
+	}
+	hex.Decode(sc.TraceID[:], []byte(traceId))
+	hex.Decode(sc.SpanID[:], []byte(spanId))
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("traceparent '%s' has an all zero id", value)
+	}
+
+	var flagBits [1]byte
+	hex.Decode(flagBits[:], []byte(flags))
+	sc.Sampled = flagBits[0]&1 == 1
+	sc.Remote = true
+	return sc, nil
+}
+
+func isLowerHex(value string) bool {
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		rand.Read(id[:])
+	}
+	return id
+}
@@ -0,0 +1,231 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StdoutExporter writes each span as a line of JSON, for local runs.
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+type stdoutSpan struct {
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Name          string         `json:"name"`
+	Kind          SpanKind       `json:"kind"`
+	Start         time.Time      `json:"start"`
+	DurationMs    float64        `json:"duration_ms"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Status        StatusCode     `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+}
+
+func (e *StdoutExporter) Export(ctx context.Context, spans []SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	encoder := json.NewEncoder(e.w)
+	for _, span := range spans {
+		line := stdoutSpan{
+			TraceID:       span.SpanContext.TraceID.String(),
+			SpanID:        span.SpanContext.SpanID.String(),
+			Name:          span.Name,
+			Kind:          span.Kind,
+			Start:         span.Start,
+			DurationMs:    float64(span.End.Sub(span.Start).Microseconds()) / 1000,
+			Attributes:    span.Attributes,
+			Status:        span.Status,
+			StatusMessage: span.StatusMessage,
+		}
+		if span.ParentSpanID.IsValid() {
+			line.ParentSpanID = span.ParentSpanID.String()
+		}
+		err := encoder.Encode(line)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *StdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// OTLPExporter posts spans to an OpenTelemetry collector with the OTLP/HTTP JSON
+// protocol, so no OpenTelemetry SDK is needed.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+}
+
+// NewOTLPExporter sends to endpoint, the collector's base URL such as
+// http://localhost:4318, on the /v1/traces path.
+func NewOTLPExporter(endpoint string, serviceName string) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(OTLPRequest(e.serviceName, spans))
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("collector at '%s' answered %s", e.endpoint, response.Status)
+	}
+	return nil
+}
+
+func (e *OTLPExporter) Shutdown(ctx context.Context) error {
+	e.client.CloseIdleConnections()
+	return nil
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    StatusCode `json:"code"`
+	Message string     `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              SpanKind       `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Status            otlpStatus     `json:"status"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+// OTLPTraceRequest is the body of an OTLP/HTTP JSON export request.
+type OTLPTraceRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+// OTLPRequest builds the export request for spans of serviceName.
+func OTLPRequest(serviceName string, spans []SpanData) OTLPTraceRequest {
+	otlpSpans := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		otlpSpans[i] = otlpSpan{
+			TraceID:           span.SpanContext.TraceID.String(),
+			SpanID:            span.SpanContext.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+			Status:            otlpStatus{Code: span.Status, Message: span.StatusMessage},
+		}
+		if span.ParentSpanID.IsValid() {
+			otlpSpans[i].ParentSpanID = span.ParentSpanID.String()
+		}
+	}
+
+	return OTLPTraceRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource:   otlpResource{Attributes: otlpAttributes(map[string]any{"service.name": serviceName})},
+			ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "tannar.moss/backend"}, Spans: otlpSpans}},
+		}},
+	}
+}
+
+func otlpAttributes(attributes map[string]any) []otlpKeyValue {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	values := make([]otlpKeyValue, 0, len(keys))
+	for _, key := range keys {
+		var value otlpAnyValue
+		switch v := attributes[key].(type) {
+		case bool:
+			value.BoolValue = &v
+		case int:
+			value.IntValue = intString(int64(v))
+		case int64:
+			value.IntValue = intString(v)
+		case uint64:
+			value.IntValue = intString(int64(v))
+		case float64:
+			value.DoubleValue = &v
+		default:
+			text := fmt.Sprint(v)
+			value.StringValue = &text
+		}
+		values = append(values, otlpKeyValue{Key: key, Value: value})
+	}
+	return values
+}
+
+func intString(v int64) *string {
+	text := strconv.FormatInt(v, 10)
+	return &text
+}
@@ -0,0 +1,148 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"tannar.moss/backend/internal/logger"
+)
+
+const (
+	maxExportBatch = 512
+	maxQueuedSpans = 2048
+)
+
+// Exporter sends finished spans to a tracing backend.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+	Shutdown(ctx context.Context) error
+}
+
+// BatchProcessor queues finished spans and exports them in batches, every interval or
+// as soon as a full batch is queued, off the request path. Spans beyond the queue
+// limit are dropped rather than slowing requests down.
+type BatchProcessor struct {
+	exporter Exporter
+	logger   logger.Logger
+
+	mu      sync.Mutex
+	queue   []SpanData
+	dropped int
+
+	exportMu sync.Mutex
+	full     chan struct{}
+	done     chan struct{}
+	wg       sync.WaitGroup
+	stopOnce sync.Once
+}
+
+// NewBatchProcessor exports to exporter every interval. A nil exporter discards spans.
+func NewBatchProcessor(exporter Exporter, interval time.Duration, logger logger.Logger) *BatchProcessor {
+	p := &BatchProcessor{
+		exporter: exporter,
+		logger:   logger,
+		full:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if exporter != nil && interval > 0 {
+		p.wg.Add(1)
+		go p.run(interval)
+	}
+	return p
+}
+
+func (p *BatchProcessor) run(interval time.Duration) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		case <-p.full:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		p.ForceFlush(ctx)
+		cancel()
+	}
+}
+
+// OnEnd queues a finished span.
+func (p *BatchProcessor) OnEnd(span SpanData) {
+	if p.exporter == nil {
+		return
+	}
+
+	p.mu.Lock()
+	if len(p.queue) >= maxQueuedSpans {
+		p.dropped++
+		p.mu.Unlock()
+		return
+	}
+	p.queue = append(p.queue, span)
+	queued := len(p.queue)
+	p.mu.Unlock()
+
+	if queued >= maxExportBatch {
+		select {
+		case p.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// ForceFlush exports every queued span, in batches, before returning.
+func (p *BatchProcessor) ForceFlush(ctx context.Context) error {
+	if p.exporter == nil {
+		return nil
+	}
+	p.exportMu.Lock()
+	defer p.exportMu.Unlock()
+
+	for {
+		p.mu.Lock()
+		batch := p.queue
+		if len(batch) > maxExportBatch {
+			batch = batch[:maxExportBatch]
+		}
+		p.queue = p.queue[len(batch):]
+		dropped := p.dropped
+		p.dropped = 0
+		p.mu.Unlock()
+
+		if dropped > 0 && p.logger != nil {
+			p.logger.Warnf("Dropped %d spans, the export queue was full", dropped)
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := p.exporter.Export(ctx, batch)
+		if err != nil {
+			if p.logger != nil {
+				p.logger.Errorf("Unabled to export %d spans: %s", len(batch), err.Error())
+			}
+			return err
+		}
+	}
+}
+
+// Shutdown stops the background export, flushes what is queued and shuts the
+// exporter down.
+func (p *BatchProcessor) Shutdown(ctx context.Context) error {
+	var err error
+	p.stopOnce.Do(func() {
+		close(p.done)
+		p.wg.Wait()
+		err = p.ForceFlush(ctx)
+		if p.exporter != nil {
+			if shutdownErr := p.exporter.Shutdown(ctx); err == nil {
+				err = shutdownErr
+			}
+		}
+	})
+	return err
+}
@@ -0,0 +1,152 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// SpanKind follows the OpenTelemetry span kinds, numbered as in OTLP.
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+// StatusCode follows the OpenTelemetry span status, numbered as in OTLP.
+type StatusCode int
+
+const (
+	StatusUnset StatusCode = 0
+	StatusOK    StatusCode = 1
+	StatusError StatusCode = 2
+)
+
+// Span is one timed operation in a trace. Its methods are safe to call on a nil
+// span, which is what SpanFromContext returns outside any trace.
+type Span struct {
+	tracer *Tracer
+
+	mu            sync.Mutex
+	name          string
+	kind          SpanKind
+	spanContext   SpanContext
+	parentSpanID  SpanID
+	start         time.Time
+	end           time.Time
+	attributes    map[string]any
+	status        StatusCode
+	statusMessage string
+	ended         bool
+}
+
+// SpanData is a finished span as handed to an exporter.
+type SpanData struct {
+	Name          string
+	Kind          SpanKind
+	SpanContext   SpanContext
+	ParentSpanID  SpanID
+	Start         time.Time
+	End           time.Time
+	Attributes    map[string]any
+	Status        StatusCode
+	StatusMessage string
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a context carrying span as the active span.
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the active span, or nil.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// TraceIDFromContext returns the hex trace id of the active span, or "" outside a
+// trace.
+func TraceIDFromContext(ctx context.Context) string {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.SpanContext().TraceID.String()
+	}
+	return ""
+}
+
+func (s *Span) SpanContext() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.spanContext
+}
+
+// SetAttribute records a string, bool, integer or float value on the span.
+func (s *Span) SetAttribute(key string, value any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.attributes[key] = value
+	}
+}
+
+// SetStatus marks the span ok or failed.
+func (s *Span) SetStatus(code StatusCode, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.ended {
+		s.status = code
+		s.statusMessage = message
+	}
+}
+
+// RecordError marks the span failed with err, when err is not nil.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.SetStatus(StatusError, err.Error())
+	}
+}
+
+// End finishes the span and hands it to the tracer's processor. Later calls do nothing.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	data := SpanData{
+		Name:          s.name,
+		Kind:          s.kind,
+		SpanContext:   s.spanContext,
+		ParentSpanID:  s.parentSpanID,
+		Start:         s.start,
+		End:           s.end,
+		Attributes:    s.attributes,
+		Status:        s.status,
+		StatusMessage: s.statusMessage,
+	}
+	s.mu.Unlock()
+
+	if s.spanContext.Sampled {
+		s.tracer.processor.OnEnd(data)
+	}
+}
+
+func (s *Span) String() string {
+	return fmt.Sprintf("%s %s/%s", s.name, s.spanContext.TraceID, s.spanContext.SpanID)
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"time"
+)
+
+// Tracer starts spans and passes the finished ones to its processor.
+type Tracer struct {
+	processor *BatchProcessor
+}
+
+func NewTracer(processor *BatchProcessor) *Tracer {
+	return &Tracer{processor: processor}
+}
+
+// Default is the tracer Start uses. Until Configure installs an exporter its spans
+// still carry ids, for logs and traceparent, but are exported nowhere.
+var Default = NewTracer(NewBatchProcessor(nil, 0, nil))
+
+// StartOption adjusts a span as it starts.
+type StartOption func(*Span)
+
+// WithKind sets the span kind; spans are internal by default.
+func WithKind(kind SpanKind) StartOption {
+	return func(s *Span) { s.kind = kind }
+}
+
+// WithRemoteParent makes the span a child of a span no longer in the context: one in
+// another process, parsed from an inbound traceparent, or one that has already ended.
+// An invalid parent is ignored.
+func WithRemoteParent(parent SpanContext) StartOption {
+	return func(s *Span) {
+		if parent.IsValid() {
+			s.spanContext.TraceID = parent.TraceID
+			s.spanContext.Sampled = parent.Sampled
+			s.parentSpanID = parent.SpanID
+		}
+	}
+}
+
+// WithAttributes records attributes on the span as it starts.
+func WithAttributes(attributes map[string]any) StartOption {
+	return func(s *Span) {
+		for key, value := range attributes {
+			s.attributes[key] = value
+		}
+	}
+}
+
+// Start starts a span on the Default tracer, see Tracer.Start.
+func Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	return Default.Start(ctx, name, options...)
+}
+
+// Start starts a span named name as a child of the active span in ctx, or as the root
+// of a new trace, and returns a context with it active. End it when done.
+func (t *Tracer) Start(ctx context.Context, name string, options ...StartOption) (context.Context, *Span) {
+	span := &Span{
+		tracer:     t,
+		name:       name,
+		kind:       SpanKindInternal,
+		start:      time.Now(),
+		attributes: map[string]any{},
+		spanContext: SpanContext{
+			TraceID: newTraceID(),
+			SpanID:  newSpanID(),
+			Sampled: true,
+		},
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		span.spanContext.TraceID = parent.spanContext.TraceID
+		span.spanContext.Sampled = parent.spanContext.Sampled
+		span.parentSpanID = parent.spanContext.SpanID
+	}
+	for _, option := range options {
+		option(span)
+	}
+
+	return ContextWithSpan(ctx, span), span
+}
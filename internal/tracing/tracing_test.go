@@ -0,0 +1,160 @@
+package tracing_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"tannar.moss/backend/internal/tracing"
+)
+
+type memoryExporter struct {
+	spans []tracing.SpanData
+}
+
+func (e *memoryExporter) Export(ctx context.Context, spans []tracing.SpanData) error {
+	e.spans = append(e.spans, spans...)
+	return nil
+}
+
+func (e *memoryExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func TestParseTraceParent_withValidHeader_shouldReadIdsAndSampledFlag(t *testing.T) {
+	sc, err := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("ParseTraceParent failed: %v", err)
+	}
+
+	if sc.TraceID.String() != "4bf92f3577b34da6a3ce929d0e0e4736" || sc.SpanID.String() != "00f067aa0ba902b7" {
+		t.Errorf("unexpected ids %s %s", sc.TraceID, sc.SpanID)
+	}
+	if !sc.Sampled || !sc.Remote {
+		t.Errorf("expected a sampled remote span context, got %+v", sc)
+	}
+	if sc.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("unexpected round trip %s", sc.TraceParent())
+	}
+}
+
+func TestParseTraceParent_withInvalidHeaders_shouldFail(t *testing.T) {
+	headers := []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, header := range headers {
+		_, err := tracing.ParseTraceParent(header)
+		if err == nil {
+			t.Errorf("expected '%s' to be rejected", header)
+		}
+	}
+}
+
+func TestParseTraceParent_withFutureVersion_shouldReadFirstFourFields(t *testing.T) {
+	sc, err := tracing.ParseTraceParent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00-future")
+	if err != nil {
+		t.Fatalf("ParseTraceParent failed: %v", err)
+	}
+	if sc.Sampled {
+		t.Errorf("expected an unsampled span context")
+	}
+}
+
+func TestStart_withParentAndRemoteParent_shouldShareTraceAndLinkSpans(t *testing.T) {
+	exporter := &memoryExporter{}
+	processor := tracing.NewBatchProcessor(exporter, 0, nil)
+	tracer := tracing.NewTracer(processor)
+	remote, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	ctx, server := tracer.Start(context.Background(), "GET /api/order/:id", tracing.WithKind(tracing.SpanKindServer), tracing.WithRemoteParent(remote))
+	_, query := tracer.Start(ctx, "SQL GetOrder")
+	query.RecordError(errors.New("deadline exceeded"))
+	query.End()
+	server.End()
+	server.End()
+
+	err := processor.ForceFlush(context.Background())
+	if err != nil {
+		t.Fatalf("ForceFlush failed: %v", err)
+	}
+	if len(exporter.spans) != 2 {
+		t.Fatalf("expected 2 exported spans, got %d", len(exporter.spans))
+	}
+
+	child, parent := exporter.spans[0], exporter.spans[1]
+	if parent.SpanContext.TraceID != remote.TraceID || child.SpanContext.TraceID != remote.TraceID {
+		t.Errorf("expected both spans in trace %s", remote.TraceID)
+	}
+	if parent.ParentSpanID != remote.SpanID || child.ParentSpanID != parent.SpanContext.SpanID {
+		t.Errorf("expected the chain remote -> server -> query, got %s and %s", parent.ParentSpanID, child.ParentSpanID)
+	}
+	if child.Status != tracing.StatusError || child.StatusMessage != "deadline exceeded" {
+		t.Errorf("expected the query span to be failed, got %d '%s'", child.Status, child.StatusMessage)
+	}
+	if tracing.TraceIDFromContext(ctx) != remote.TraceID.String() {
+		t.Errorf("expected the context to carry trace %s", remote.TraceID)
+	}
+}
+
+func TestStart_withUnsampledParent_shouldNotExport(t *testing.T) {
+	exporter := &memoryExporter{}
+	processor := tracing.NewBatchProcessor(exporter, 0, nil)
+	tracer := tracing.NewTracer(processor)
+	remote, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+
+	_, span := tracer.Start(context.Background(), "GET /healthz", tracing.WithRemoteParent(remote))
+	span.End()
+	processor.ForceFlush(context.Background())
+
+	if len(exporter.spans) != 0 {
+		t.Errorf("expected no exported spans, got %d", len(exporter.spans))
+	}
+}
+
+func TestOTLPRequest_shouldEncodeOTLPJSON(t *testing.T) {
+	sc, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	start := time.Unix(1700000000, 5)
+	span := tracing.SpanData{
+		Name:        "SQL GetOrder",
+		Kind:        tracing.SpanKindClient,
+		SpanContext: sc,
+		Start:       start,
+		End:         start.Add(time.Millisecond),
+		Attributes:  map[string]any{"db.system": "mysql", "http.status_code": 200},
+		Status:      tracing.StatusOK,
+	}
+
+	body, err := json.Marshal(tracing.OTLPRequest("backend", []tracing.SpanData{span}))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	expected := `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"backend"}}]},` +
+		`"scopeSpans":[{"scope":{"name":"tannar.moss/backend"},"spans":[{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"spanId":"00f067aa0ba902b7","name":"SQL GetOrder","kind":3,"startTimeUnixNano":"1700000000000000005",` +
+		`"endTimeUnixNano":"1700000000001000005","attributes":[{"key":"db.system","value":{"stringValue":"mysql"}},` +
+		`{"key":"http.status_code","value":{"intValue":"200"}}],"status":{"code":1}}]}]}]}`
+	if string(body) != expected {
+		t.Errorf("unexpected OTLP body:\n%s", body)
+	}
+}
+
+func TestSpan_withNilSpan_shouldDoNothing(t *testing.T) {
+	span := tracing.SpanFromContext(context.Background())
+	span.SetAttribute("key", "value")
+	span.RecordError(errors.New("ignored"))
+	span.End()
+
+	if tracing.TraceIDFromContext(context.Background()) != "" {
+		t.Errorf("expected no trace id outside a trace")
+	}
+}
@@ -47,3 +47,13 @@ func TestNewSocketError_withTestNewNoTFoundOrNoRecordError_expectConstantsToMatc
 	err := types.NewNoTFoundOrNoRecordError()
 	commonTestSocketErrorFlow(t, err, constant.NotFoundCode, constant.NotFoundErrorName)
 }
+
+func TestNewSocketError_withTestNewForbiddenError_expectConstantsToMatch(t *testing.T) {
+	err := types.NewForbiddenError()
+	commonTestSocketErrorFlow(t, err, constant.ForbiddenCode, constant.ForbiddenErrorName)
+}
+
+func TestNewSocketError_withTestNewGatewayTimeoutError_expectConstantsToMatch(t *testing.T) {
+	err := types.NewGatewayTimeoutError()
+	commonTestSocketErrorFlow(t, err, constant.GatewayTimeoutCode, constant.GatewayTimeoutErrorName)
+}
@@ -45,3 +45,11 @@ func NewNoTFoundOrNoRecordError() error {
 func NewUnauthorizedError() error {
 	return NewSocketError(constant.UnauthorizedCode, constant.UnauthorizedRequestName)
 }
+
+func NewForbiddenError() error {
+	return NewSocketError(constant.ForbiddenCode, constant.ForbiddenErrorName)
+}
+
+func NewGatewayTimeoutError() error {
+	return NewSocketError(constant.GatewayTimeoutCode, constant.GatewayTimeoutErrorName)
+}
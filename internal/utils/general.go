@@ -108,6 +108,23 @@ func UintToString(variable uint64) string {
 	return fmt.Sprintf("%d", variable)
 }
 
+// ParseIDList reads a comma separated list of ids, such as a GROUP_CONCAT column.
+// An empty string is an empty list.
+func ParseIDList(value string) ([]uint64, error) {
+	ids := make([]uint64, 0)
+	if value == "" {
+		return ids, nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
 func FormatJSONString(input string) string {
 	var data map[string]interface{}
 	err := json.Unmarshal([]byte(input), &data)
@@ -303,3 +303,18 @@ func TestReplaceQuotes_withCaseWithQuotesInAndCaseWithoutQuotes_shouldAlwaysRemo
 		t.Errorf("Expected %s, but got %s", expected, result)
 	}
 }
+
+func TestParseIDList_withCommaSeparatedIds_shouldParseEach(t *testing.T) {
+	result, err := utils.ParseIDList("3,12, 7")
+	if err != nil || len(result) != 3 || result[0] != 3 || result[1] != 12 || result[2] != 7 {
+		t.Errorf("ParseIDList test failed, expected[3 12 7], got[%v] with error[%v]", result, err)
+	}
+	result, err = utils.ParseIDList("")
+	if err != nil || len(result) != 0 {
+		t.Errorf("ParseIDList test failed, expected an empty list, got[%v]", result)
+	}
+	_, err = utils.ParseIDList("3,x")
+	if err == nil {
+		t.Error("ParseIDList test failed, expected an error for a non numeric id")
+	}
+}
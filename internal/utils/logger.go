@@ -1,9 +1,14 @@
 package utils
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
+	"github.com/go-sql-driver/mysql"
 	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/types"
 )
 
 func LogPreparingError(queryName string, logger logger.Logger, err error) {
@@ -21,3 +26,62 @@ func LogBeginingTnxError(queryName string, logger logger.Logger, err error) {
 func LogCommitError(queryName string, logger logger.Logger, err error) {
 	logger.Error(fmt.Sprintf("Error commiting transaction '%s' query: %s", queryName, err.Error()))
 }
+
+// QueryError logs a failed query and returns the error to hand back for it: a
+// timeout when the query ran past its deadline or its request was cancelled, a bad
+// request when it broke a unique key, otherwise an internal server error.
+func QueryError(queryName string, logger logger.Logger, err error) error {
+	if IsTimeout(err) {
+		return timeoutError(queryName, logger, err)
+	}
+	if IsDuplicateEntry(err) {
+		logger.Info(fmt.Sprintf("Duplicate entry executing '%s' query: %s", queryName, err.Error()))
+		return types.NewBadRequestError()
+	}
+
+	metrics.DBQueryErrors.Inc(queryName, metrics.QueryErrorFailed)
+	LogExecutingError(queryName, logger, err)
+	return types.NewInternalServerError()
+}
+
+// PrepareError is QueryError for a statement that could not be prepared.
+func PrepareError(queryName string, logger logger.Logger, err error) error {
+	if IsTimeout(err) {
+		return timeoutError(queryName, logger, err)
+	}
+
+	metrics.DBQueryErrors.Inc(queryName, metrics.QueryErrorFailed)
+	LogPreparingError(queryName, logger, err)
+	return types.NewInternalServerError()
+}
+
+// BeginError is QueryError for a transaction that could not be started.
+func BeginError(queryName string, logger logger.Logger, err error) error {
+	if IsTimeout(err) {
+		return timeoutError(queryName, logger, err)
+	}
+
+	metrics.DBQueryErrors.Inc(queryName, metrics.QueryErrorFailed)
+	LogBeginingTnxError(queryName, logger, err)
+	return types.NewInternalServerError()
+}
+
+// IsTimeout reports whether err comes from a deadline passing or a cancellation.
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled)
+}
+
+// IsDuplicateEntry reports whether err is MySQL refusing a row that breaks a unique
+// key, which only a concurrent request can cause once the caller has checked first.
+func IsDuplicateEntry(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
+
+const mysqlDuplicateEntry = 1062
+
+func timeoutError(queryName string, logger logger.Logger, err error) error {
+	metrics.DBQueryErrors.Inc(queryName, metrics.QueryErrorTimeout)
+	logger.Error(fmt.Sprintf("Timed out executing '%s' query: %s", queryName, err.Error()))
+	return types.NewGatewayTimeoutError()
+}
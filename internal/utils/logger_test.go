@@ -0,0 +1,42 @@
+package utils_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
+)
+
+func TestQueryError_withDeadlineOrCancel_shouldReturnGatewayTimeout(t *testing.T) {
+	log := logger.NewSimpleLogger("ERROR", false)
+	for _, cause := range []error{context.DeadlineExceeded, context.Canceled, fmt.Errorf("wrapped: %w", context.DeadlineExceeded)} {
+		err := utils.QueryError("GetOrder", log, cause)
+		socketErr, ok := err.(*types.SocketError)
+		if !ok || socketErr.StatusCode() != constant.GatewayTimeoutCode {
+			t.Errorf("QueryError test failed for '%v', expected[%d], got[%v]", cause, constant.GatewayTimeoutCode, err)
+		}
+	}
+}
+
+func TestQueryError_withDriverError_shouldReturnInternalServerError(t *testing.T) {
+	err := utils.QueryError("GetOrder", logger.NewSimpleLogger("ERROR", false), errors.New("connection refused"))
+	socketErr, ok := err.(*types.SocketError)
+	if !ok || socketErr.StatusCode() != constant.InternalServerErrorCode {
+		t.Errorf("QueryError test failed, expected[%d], got[%v]", constant.InternalServerErrorCode, err)
+	}
+}
+
+func TestQueryError_withDuplicateEntry_shouldReturnBadRequest(t *testing.T) {
+	cause := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '7-3-1' for key 'idx_reviews_user_product'"}
+	err := utils.QueryError("CreateReview", logger.NewSimpleLogger("ERROR", false), cause)
+	socketErr, ok := err.(*types.SocketError)
+	if !ok || socketErr.StatusCode() != constant.BadRequestCode {
+		t.Errorf("QueryError test failed, expected[%d], got[%v]", constant.BadRequestCode, err)
+	}
+}
@@ -1,14 +1,38 @@
 package lambda
 
 import (
+	"context"
+	"strings"
+
 	"github.com/aws/aws-lambda-go/events"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/lambda/public/model"
 )
 
 type Controller interface {
-	PreProcess(event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error)
-	Process(requestType string, path string, body string) (*model.Response, error)
+	PreProcess(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error)
+	Process(ctx context.Context, requestType string, path string, body string) (*model.Response, error)
 	PostProcess(response model.Response) (string, error)
 	PublishLogs()
 	Shutdown()
 }
+
+// StartRequestSpan runs an invocation in a server span, continuing the caller's trace
+// when the event carries a valid traceparent header.
+func StartRequestSpan(ctx context.Context, event events.APIGatewayWebsocketProxyRequest) (context.Context, *tracing.Span) {
+	options := []tracing.StartOption{tracing.WithKind(tracing.SpanKindServer)}
+	// API Gateway passes header names through in whatever case the client sent
+	for name, value := range event.Headers {
+		if strings.EqualFold(name, tracing.TraceParentHeader) {
+			parent, err := tracing.ParseTraceParent(value)
+			if err == nil {
+				options = append(options, tracing.WithRemoteParent(parent))
+			}
+		}
+	}
+
+	ctx, span := tracing.Start(ctx, event.HTTPMethod+" "+event.Path, options...)
+	span.SetAttribute("http.method", event.HTTPMethod)
+	span.SetAttribute("http.route", event.Path)
+	return ctx, span
+}
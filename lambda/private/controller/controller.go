@@ -1,33 +1,36 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
+	"tannar.moss/backend/internal/audit"
 	"tannar.moss/backend/internal/constant"
 	"tannar.moss/backend/internal/logger"
 	"tannar.moss/backend/internal/repository"
 	"tannar.moss/backend/internal/repository/mysql"
 	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/types"
 	"tannar.moss/backend/internal/utils"
 	"tannar.moss/backend/lambda/private/model"
 )
 
 type Controller interface {
-	PreProcess(event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (uint64, string, string, string, error)
-	Process(signInUserId uint64, requestType string, path string, body string) (*model.Response, error)
+	PreProcess(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (uint64, string, string, string, error)
+	Process(ctx context.Context, signInUserId uint64, requestType string, path string, body string) (*model.Response, error)
 	PostProcess(response model.Response) (string, error)
 	PublishLogs()
 	Shutdown()
 }
 
 type PrivateController struct {
-	service service.Private
-	logger  logger.Logger
+	service  service.Private
+	logger   logger.Logger
+	sourceIp string
 }
 
 func (c *PrivateController) retrieveUserIdFromJWT(jwt string) (uint64, error) {
@@ -57,12 +60,12 @@ func NewPrivateController(logLevel string, publishLogs bool) (Controller, error)
 		Password:          "root",
 	}
 
-	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig)
+	logger := logger.NewSimpleLogger(logLevel, publishLogs)
+	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig, logger)
 	if err != nil {
 		return nil, types.NewInternalServerError()
 	}
 
-	logger := logger.NewSimpleLogger(logLevel, publishLogs)
 	userRepo := repository.NewMySqlUserRepository(logger, *dbConn)
 	validatorService := service.NewValidator(logger, *validator.New())
 	PrivateService := service.NewPrivateService(validatorService, userRepo, logger)
@@ -84,44 +87,51 @@ func (c *PrivateController) PostProcess(response model.Response) (string, error)
 	return string(responseString), nil
 }
 
-func (c *PrivateController) PreProcess(event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (uint64, string, string, string, error) {
-	c.logger.SetTraceId(uuid.NewString())
+func (c *PrivateController) PreProcess(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (uint64, string, string, string, error) {
+	c.logger.SetTraceId(tracing.TraceIDFromContext(ctx))
+	c.sourceIp = event.RequestContext.Identity.SourceIP
 	jwtToken := event.Headers["Authorization"]
 	userId, err := c.retrieveUserIdFromJWT(jwtToken)
 	return userId, event.HTTPMethod, event.Path, event.Body, err
 }
 
-func (c *PrivateController) Process(userId uint64, requestType string, path string, body string) (*model.Response, error) {
+func (c *PrivateController) Process(ctx context.Context, userId uint64, requestType string, path string, body string) (*model.Response, error) {
+	ctx = mysql.WithSession(ctx, userId)
+	ctx = logger.NewContext(ctx, c.logger.Derive(tracing.TraceIDFromContext(ctx),
+		logger.String("route", requestType+" "+path),
+		logger.String("ip", c.sourceIp),
+		logger.Uint64("user_id", userId),
+	))
 	switch requestType {
 	case constant.GET:
-		return c.handleGetRequest(userId, path, body)
+		return c.handleGetRequest(ctx, userId, path, body)
 	case constant.POST:
-		return c.handlePostRequest(userId, path, body)
+		return c.handlePostRequest(ctx, userId, path, body)
 	case constant.PUT:
-		return c.handlePutRequest(userId, path, body)
+		return c.handlePutRequest(ctx, userId, path, body)
 	default:
 		return nil, types.NewNotImplementedError()
 	}
 }
 
-func (c *PrivateController) handleGetRequest(userId uint64, path string, body string) (*model.Response, error) {
+func (c *PrivateController) handleGetRequest(ctx context.Context, userId uint64, path string, body string) (*model.Response, error) {
 	switch path {
 	default:
 		return nil, types.NewNotImplementedError()
 	}
 }
 
-func (c *PrivateController) handlePostRequest(userId uint64, path string, body string) (*model.Response, error) {
+func (c *PrivateController) handlePostRequest(ctx context.Context, userId uint64, path string, body string) (*model.Response, error) {
 	switch path {
 	default:
 		return nil, types.NewNotImplementedError()
 	}
 }
 
-func (c *PrivateController) handlePutRequest(userId uint64, path string, body string) (*model.Response, error) {
+func (c *PrivateController) handlePutRequest(ctx context.Context, userId uint64, path string, body string) (*model.Response, error) {
 	switch path {
 	case "/api/users/info":
-		userResponse, err := c.service.UpdateUserInfo(userId, body, userId)
+		userResponse, err := c.service.UpdateUserInfo(ctx, userId, body, audit.Source{ActorID: userId, SourceIP: c.sourceIp})
 		if err != nil {
 			return nil, err
 		}
@@ -130,7 +140,7 @@ func (c *PrivateController) handlePutRequest(userId uint64, path string, body st
 			User: *userResponse,
 		}, nil
 	case "/api/users/password":
-		userResponse, err := c.service.UpdateUserPassword(userId, body, userId)
+		userResponse, err := c.service.UpdateUserPassword(ctx, userId, body, audit.Source{ActorID: userId, SourceIP: c.sourceIp})
 		if err != nil {
 			return nil, err
 		}
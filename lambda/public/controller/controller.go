@@ -1,34 +1,41 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/go-playground/validator/v10"
-	"github.com/google/uuid"
 	"tannar.moss/backend/internal/constant"
 	"tannar.moss/backend/internal/logger"
+	internalModel "tannar.moss/backend/internal/model"
 	"tannar.moss/backend/internal/repository"
 	"tannar.moss/backend/internal/repository/mysql"
+	"tannar.moss/backend/internal/search"
 	"tannar.moss/backend/internal/service"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/types"
+	"tannar.moss/backend/internal/utils"
 	"tannar.moss/backend/lambda/public/model"
 )
 
 type Controller interface {
-	PreProcess(event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error)
-	Process(requestType string, path string, body string) (*model.Response, error)
+	PreProcess(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error)
+	Process(ctx context.Context, requestType string, path string, body string) (*model.Response, error)
 	PostProcess(response model.Response) (string, error)
 	PublishLogs()
 	Shutdown()
 }
 
 type PublicController struct {
-	Service service.Public
-	Logger  logger.Logger
+	Service         service.Public
+	ProductsService service.Products
+	HealthService   service.Health
+	Logger          logger.Logger
+	sourceIp        string
 }
 
-func NewPublicController(logLevel string, publishLogs bool) (Controller, error) {
+func NewPublicController(ctx context.Context, logLevel string, publishLogs bool) (Controller, error) {
 	// todo: remove hardcode databse config and use aws secret
 	genericUserConfig := mysql.DatabaseConfig{
 		Host:              "localhost",
@@ -41,19 +48,31 @@ func NewPublicController(logLevel string, publishLogs bool) (Controller, error)
 		Password:          "root",
 	}
 
-	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig)
+	logger := logger.NewSimpleLogger(logLevel, publishLogs)
+	dbConn, err := mysql.NewDbConnection(genericUserConfig, genericUserConfig, logger)
 	if err != nil {
 		return nil, types.NewInternalServerError()
 	}
 
-	logger := logger.NewSimpleLogger(logLevel, publishLogs)
 	userRepo := repository.NewMySqlUserRepository(logger, *dbConn)
 	validatorService := service.NewValidator(logger, *validator.New())
 	publicService := service.NewPublicService(validatorService, userRepo, logger)
 
+	// the search index is built in memory on cold start so searches skip the
+	// full-text queries; it is rebuilt whenever the controller is recycled
+	productRepo := repository.NewMySqlProductRepository(logger, *dbConn)
+	products, err := productRepo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	categoryRepo := repository.NewMySqlCategoryRepository(logger, *dbConn)
+	productsService := service.NewProductsService(validatorService, productRepo, categoryRepo, search.NewMemoryIndex(logger, products), logger)
+
 	return &PublicController{
-		Service: publicService,
-		Logger:  logger,
+		Service:         publicService,
+		ProductsService: productsService,
+		HealthService:   service.NewHealthService(repository.NewMySqlHealthRepository(logger, *dbConn), logger),
+		Logger:          logger,
 	}, nil
 }
 
@@ -68,41 +87,79 @@ func (c *PublicController) PostProcess(response model.Response) (string, error)
 	return string(responseString), nil
 }
 
-func (c *PublicController) PreProcess(event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error) {
-	c.Logger.SetTraceId(uuid.NewString())
+func (c *PublicController) PreProcess(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, loglevel string, pushLogs bool) (string, string, string, error) {
+	c.Logger.SetTraceId(tracing.TraceIDFromContext(ctx))
+	c.sourceIp = event.RequestContext.Identity.SourceIP
 	return event.HTTPMethod, event.Path, event.Body, nil
 }
 
-func (c *PublicController) Process(requestType string, path string, body string) (*model.Response, error) {
+func (c *PublicController) Process(ctx context.Context, requestType string, path string, body string) (*model.Response, error) {
+	ctx = logger.NewContext(ctx, c.Logger.Derive(tracing.TraceIDFromContext(ctx),
+		logger.String("route", requestType+" "+path),
+		logger.String("ip", c.sourceIp),
+	))
 	switch requestType {
+	case constant.GET:
+		return c.handleGetRequest(ctx, path)
 	case constant.POST:
-		return c.handlePostRequest(path, body)
+		return c.handlePostRequest(ctx, path, body)
 	case constant.PUT:
-		return c.handlePutRequest(path, body)
+		return c.handlePutRequest(ctx, path, body)
+	default:
+		return nil, types.NewNotImplementedError()
+	}
+}
+
+func (c *PublicController) handleGetRequest(ctx context.Context, path string) (*model.Response, error) {
+	switch path {
+	case "/healthz":
+		liveness := c.HealthService.Liveness()
+		return &model.Response{
+			HealthResponse: &liveness,
+		}, nil
+	case "/readyz":
+		readiness := c.HealthService.Readiness(ctx)
+		return &model.Response{
+			HealthResponse: &readiness,
+		}, nil
 	default:
 		return nil, types.NewNotImplementedError()
 	}
 }
 
-func (c *PublicController) handlePostRequest(path string, body string) (*model.Response, error) {
+func (c *PublicController) handlePostRequest(ctx context.Context, path string, body string) (*model.Response, error) {
 	switch path {
 	case "/api/register":
-		loginResponse, err := c.Service.Register(body)
+		loginResponse, err := c.Service.Register(ctx, body, c.sourceIp)
 		if err != nil {
 			return nil, err
 		}
 		return &model.Response{
 			LoginResponse: *loginResponse,
 		}, nil
+	case "/api/products/search":
+		var searchRequest internalModel.ProductSearchRequest
+		err := json.Unmarshal([]byte(utils.FormatJSONString(body)), &searchRequest)
+		if err != nil {
+			c.Logger.Infof("Error marshaling search request: %s", err.Error())
+			return nil, types.NewInvalidInputError()
+		}
+		searchResponse, err := c.ProductsService.Search(ctx, searchRequest)
+		if err != nil {
+			return nil, err
+		}
+		return &model.Response{
+			ProductSearchResponse: searchResponse,
+		}, nil
 	default:
 		return nil, types.NewNotImplementedError()
 	}
 }
 
-func (c *PublicController) handlePutRequest(path string, body string) (*model.Response, error) {
+func (c *PublicController) handlePutRequest(ctx context.Context, path string, body string) (*model.Response, error) {
 	switch path {
 	case "/api/login":
-		loginResponse, err := c.Service.Login(body)
+		loginResponse, err := c.Service.Login(ctx, body)
 		if err != nil {
 			return nil, err
 		}
@@ -121,5 +178,7 @@ func (c *PublicController) PublishLogs() {
 
 func (c *PublicController) Shutdown() {
 	c.Service.Shutdown()
+	c.ProductsService.Shutdown()
+	c.HealthService.Shutdown()
 	c = nil
 }
@@ -4,9 +4,15 @@ import (
 	"context"
 	"net/http"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
+	"tannar.moss/backend/internal/constant"
+	"tannar.moss/backend/internal/logger"
+	"tannar.moss/backend/internal/metrics"
+	"tannar.moss/backend/internal/tracing"
 	"tannar.moss/backend/internal/utils"
 	internalLambda "tannar.moss/backend/lambda"
 	"tannar.moss/backend/lambda/public/controller"
@@ -15,7 +21,10 @@ import (
 var invokeCount = 0
 var lambdaController internalLambda.Controller
 
-func handlerEvent(_ context.Context, event events.APIGatewayWebsocketProxyRequest) (*events.APIGatewayProxyResponse, error) {
+// emf prints the counters each invocation moved, for CloudWatch to turn into metrics
+var emf = metrics.NewEMFWriter(metrics.Default, "Backend", map[string]string{"function": "public"})
+
+func handlerEvent(ctx context.Context, event events.APIGatewayWebsocketProxyRequest) (*events.APIGatewayProxyResponse, error) {
 	logLevel := utils.Getenv("LOG_LEVEL", "INFO")
 	pushLogs := utils.SafeBool(os.Getenv("PUSH_LOGS"), false)
 
@@ -26,7 +35,7 @@ func handlerEvent(_ context.Context, event events.APIGatewayWebsocketProxyReques
 
 	var err error
 	if invokeCount == 0 {
-		lambdaController, err = controller.NewPublicController(logLevel, pushLogs)
+		lambdaController, err = controller.NewPublicController(ctx, logLevel, pushLogs)
 
 		if err != nil {
 			return utils.FormatErrorAPIGatewayResponse(err), nil
@@ -34,19 +43,31 @@ func handlerEvent(_ context.Context, event events.APIGatewayWebsocketProxyReques
 	}
 
 	invokeCount++
-	response := processEvent(event, logLevel, pushLogs)
+	started := time.Now()
+	ctx, span := internalLambda.StartRequestSpan(ctx, event)
+	response := processEvent(ctx, event, logLevel, pushLogs)
+	span.SetAttribute("http.status_code", response.StatusCode)
+	if response.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(tracing.StatusError, response.Body)
+	}
+	span.End()
+	metrics.HTTPRequests.Inc(event.HTTPMethod, event.Path, strconv.Itoa(response.StatusCode))
+	metrics.HTTPRequestDuration.ObserveSince(started, event.HTTPMethod, event.Path)
 	lambdaController.PublishLogs()
+	emf.Flush(os.Stdout, time.Now())
+	// the sandbox is frozen between invocations, so spans are sent before returning
+	tracing.Flush(ctx)
 
 	return response, nil
 }
 
-func processEvent(event events.APIGatewayWebsocketProxyRequest, logLevel string, publishLogs bool) *events.APIGatewayProxyResponse {
-	httpType, path, body, err := lambdaController.PreProcess(event, logLevel, publishLogs)
+func processEvent(ctx context.Context, event events.APIGatewayWebsocketProxyRequest, logLevel string, publishLogs bool) *events.APIGatewayProxyResponse {
+	httpType, path, body, err := lambdaController.PreProcess(ctx, event, logLevel, publishLogs)
 	if err != nil {
 		return utils.FormatErrorAPIGatewayResponse(err)
 	}
 
-	response, err := lambdaController.Process(httpType, path, body)
+	response, err := lambdaController.Process(ctx, httpType, path, body)
 	if err != nil {
 		return utils.FormatErrorAPIGatewayResponse(err)
 	}
@@ -56,9 +77,13 @@ func processEvent(event events.APIGatewayWebsocketProxyRequest, logLevel string,
 		return utils.FormatErrorAPIGatewayResponse(err)
 	}
 
+	if response.HealthResponse != nil && response.HealthResponse.Status == constant.HEALTH_STATUS_FAIL {
+		return utils.FormatGatewayResponse(http.StatusServiceUnavailable, processedResponse)
+	}
 	return utils.FormatGatewayResponse(http.StatusOK, processedResponse)
 }
 
 func main() {
+	tracing.Configure("backend-public", logger.NewSimpleLogger(utils.Getenv("LOG_LEVEL", "INFO"), false))
 	lambda.Start(handlerEvent)
 }
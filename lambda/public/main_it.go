@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -24,7 +25,7 @@ func TestLambdaHandler_withPostRequest_shouldSuccessfullProcess(t *testing.T) {
 		}`,
 	}
 
-	response, err := handlerEvent(nil, event)
+	response, err := handlerEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("LambdaHandler() error = %v", err)
 	} else {
@@ -46,7 +47,7 @@ func TestLambdaHandler_withPutRequest_shouldSuccessfullProcess(t *testing.T) {
 		}`,
 	}
 
-	response, err := handlerEvent(nil, event)
+	response, err := handlerEvent(context.Background(), event)
 	if err != nil {
 		t.Errorf("LambdaHandler() error = %v", err)
 	} else {
@@ -3,5 +3,7 @@ package model
 import internalModel "tannar.moss/backend/internal/model"
 
 type Response struct {
-	LoginResponse internalModel.LoginResponse
+	LoginResponse         internalModel.LoginResponse
+	ProductSearchResponse *internalModel.ProductSearchResponse `json:",omitempty"`
+	HealthResponse        *internalModel.HealthResponse        `json:",omitempty"`
 }